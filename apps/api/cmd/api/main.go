@@ -2,21 +2,35 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/google/uuid"
+	"github.com/itisrohit/quiethire/apps/api/internal/activities"
+	"github.com/itisrohit/quiethire/apps/api/internal/auth"
 	"github.com/itisrohit/quiethire/apps/api/internal/config"
+	"github.com/itisrohit/quiethire/apps/api/internal/dispatch"
+	"github.com/itisrohit/quiethire/apps/api/internal/indexer"
+	"github.com/itisrohit/quiethire/apps/api/internal/jobstream"
+	"github.com/itisrohit/quiethire/apps/api/internal/searchindex"
 	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
 	"github.com/typesense/typesense-go/typesense"
 	tsapi "github.com/typesense/typesense-go/typesense/api"
+	"github.com/valyala/fasthttp"
+	"go.temporal.io/sdk/client"
 )
 
 func main() {
@@ -52,6 +66,25 @@ func main() {
 		}
 	}
 
+	// Initialize PostgreSQL connection, used by the schedule CRUD endpoints
+	// below (crawl_schedules is where SchedulerWorkflow reads its due rows
+	// from).
+	pgConnStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password,
+		cfg.Database.Database, cfg.Database.SSLMode)
+	pgConn, err := sql.Open("postgres", pgConnStr)
+	if err != nil {
+		log.Printf("Warning: Failed to connect to PostgreSQL: %v", err)
+		pgConn = nil
+	} else if err := pgConn.Ping(); err != nil {
+		log.Printf("Warning: PostgreSQL ping failed: %v", err)
+	} else {
+		log.Println("✅ Connected to PostgreSQL")
+		if err := activities.EnsureCrawlSchedulesTable(context.Background(), pgConn); err != nil {
+			log.Printf("Warning: failed to ensure crawl_schedules table: %v", err)
+		}
+	}
+
 	// Initialize Typesense client
 	tsClient := typesense.NewClient(
 		typesense.WithServer(fmt.Sprintf("http://%s:%d", cfg.Typesense.Host, cfg.Typesense.Port)),
@@ -59,6 +92,36 @@ func main() {
 	)
 	log.Println("✅ Typesense client initialized")
 
+	// Hub fans newly-changed jobs out to /api/v1/jobs/stream subscribers. It
+	// sources them by polling ClickHouse itself, under its own watermark —
+	// see internal/jobstream's doc comment for why it can't just receive
+	// them from the cmd/index-jobs --daemon process directly.
+	hub := jobstream.NewHub()
+	if chConn != nil {
+		go hub.Watch(context.Background(), chConn, 10*time.Second)
+
+		if err := auth.EnsureKeysTable(context.Background(), chConn); err != nil {
+			log.Printf("Warning: failed to ensure api_keys table: %v", err)
+		}
+	}
+	limiter := auth.NewLimiter()
+
+	// Temporal client for the manual dispatch endpoints below. A connection
+	// failure here is non-fatal, same as the ClickHouse/Typesense handling
+	// above — those routes just 503 until TEMPORAL_HOST is reachable.
+	temporalHost := os.Getenv("TEMPORAL_HOST")
+	if temporalHost == "" {
+		temporalHost = "localhost:7233"
+	}
+	temporalClient, err := client.Dial(client.Options{HostPort: temporalHost})
+	if err != nil {
+		log.Printf("Warning: Failed to connect to Temporal: %v", err)
+		temporalClient = nil
+	} else {
+		log.Println("✅ Connected to Temporal")
+		defer temporalClient.Close()
+	}
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      cfg.App.Name,
@@ -71,7 +134,7 @@ func main() {
 		Format: "[${time}] ${status} - ${method} ${path} - ${latency}\n",
 	}))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
+		AllowOrigins: cfg.App.AllowOrigins,
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 	}))
@@ -88,8 +151,11 @@ func main() {
 	// API v1 routes
 	api := app.Group("/api/v1")
 
+	readJobs := auth.Middleware(chConn, limiter, "read:jobs")
+	adminIndex := auth.Middleware(chConn, limiter, "admin:index")
+
 	// Search endpoint with Typesense
-	api.Get("/search", func(c *fiber.Ctx) error {
+	api.Get("/search", readJobs, func(c *fiber.Ctx) error {
 		query := c.Query("q")
 		if query == "" {
 			return c.Status(400).JSON(fiber.Map{
@@ -101,6 +167,29 @@ func main() {
 		page, _ := strconv.Atoi(c.Query("page", "1"))
 		perPage, _ := strconv.Atoi(c.Query("per_page", "20"))
 
+		filterBy, err := searchindex.BuildFilterBy(searchindex.FilterParams{
+			Remote:          c.Query("remote"),
+			ExperienceLevel: c.Query("experience_level"),
+			JobType:         c.Query("job_type"),
+			SourcePlatform:  c.Query("source_platform"),
+			Tags:            c.Query("tags"),
+			SalaryMin:       c.Query("salary_min"),
+			PostedAfter:     c.Query("posted_after"),
+		})
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		facetBy, err := searchindex.BuildFacetBy(c.Query("facet_by"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		sortBy, err := searchindex.BuildSortBy(c.Query("sort_by"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
 		// Search in Typesense
 		searchParams := &tsapi.SearchCollectionParams{
 			Q:       query,
@@ -108,8 +197,19 @@ func main() {
 			Page:    &page,
 			PerPage: &perPage,
 		}
+		if filterBy != "" {
+			searchParams.FilterBy = &filterBy
+		}
+		if facetBy != "" {
+			searchParams.FacetBy = &facetBy
+		}
+		if sortBy != "" {
+			searchParams.SortBy = &sortBy
+		}
 
-		results, err := tsClient.Collection("jobs").Documents().Search(context.Background(), searchParams)
+		// Collection() also resolves alias names, so this transparently
+		// follows wherever cmd/index-jobs last pointed searchindex.JobsAlias.
+		results, err := tsClient.Collection(searchindex.JobsAlias).Documents().Search(context.Background(), searchParams)
 		if err != nil {
 			log.Printf("Search error: %v", err)
 			// Return empty results if Typesense is not set up yet
@@ -129,8 +229,100 @@ func main() {
 		})
 	})
 
+	// Live feed of newly-changed jobs over SSE, filtered with the same
+	// query parameters /search accepts. A client can resume after a drop
+	// either via the standard EventSource Last-Event-ID header or a
+	// ?since= query param, both carrying the job's updated_at unix
+	// timestamp; matching jobs missed since then are replayed from
+	// ClickHouse before the stream switches over to live Hub events.
+	api.Get("/jobs/stream", readJobs, func(c *fiber.Ctx) error {
+		filterParams := searchindex.FilterParams{
+			Remote:          c.Query("remote"),
+			ExperienceLevel: c.Query("experience_level"),
+			JobType:         c.Query("job_type"),
+			SourcePlatform:  c.Query("source_platform"),
+			Tags:            c.Query("tags"),
+			SalaryMin:       c.Query("salary_min"),
+			PostedAfter:     c.Query("posted_after"),
+		}
+		if _, err := searchindex.BuildFilterBy(filterParams); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		var since int64
+		if lastEventID := c.Get("Last-Event-ID"); lastEventID != "" {
+			since, _ = strconv.ParseInt(lastEventID, 10, 64)
+		} else if rawSince := c.Query("since"); rawSince != "" {
+			since, _ = strconv.ParseInt(rawSince, 10, 64)
+		}
+
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+
+		ch, unsubscribe := hub.Subscribe()
+
+		c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+			defer unsubscribe()
+
+			// Started before the replay query below so a slow or
+			// wide-range replay (a client reconnecting after a long gap)
+			// still gets a heartbeat opportunity between rows, rather than
+			// the idle-disconnect detection this ticker exists for only
+			// kicking in once replay is done. It can't help during the
+			// FetchDelta call itself — that's one blocking ClickHouse
+			// round trip with no point to interject a write.
+			ticker := time.NewTicker(sseHeartbeatInterval)
+			defer ticker.Stop()
+
+			if since > 0 && chConn != nil {
+				// No sinceIDs here: the client only hands back a unix
+				// timestamp (Last-Event-ID/?since=), not the ids it already
+				// saw at that exact second, so a same-second replay overlap
+				// is possible — the same Last-Event-ID semantics as any
+				// other at-least-once SSE resume.
+				replayed, err := indexer.FetchDelta(context.Background(), chConn, time.Unix(since, 0), nil,
+					indexer.DefaultScoreThreshold, indexer.DefaultMaxAge)
+				if err != nil {
+					log.Printf("jobs/stream: replay query failed: %v", err)
+				} else {
+					for _, job := range replayed.Upserts {
+						select {
+						case <-ticker.C:
+							if !writeHeartbeat(w) {
+								return
+							}
+						default:
+						}
+						if jobstream.Matches(filterParams, job) && !writeJobEvent(w, job) {
+							return
+						}
+					}
+				}
+			}
+
+			for {
+				select {
+				case job, ok := <-ch:
+					if !ok {
+						return
+					}
+					if jobstream.Matches(filterParams, job) && !writeJobEvent(w, job) {
+						return
+					}
+				case <-ticker.C:
+					if !writeHeartbeat(w) {
+						return
+					}
+				}
+			}
+		}))
+
+		return nil
+	})
+
 	// Get job by ID from ClickHouse
-	api.Get("/jobs/:id", func(c *fiber.Ctx) error {
+	api.Get("/jobs/:id", readJobs, func(c *fiber.Ctx) error {
 		jobID := c.Params("id")
 
 		if chConn == nil {
@@ -186,7 +378,7 @@ func main() {
 	})
 
 	// List jobs with pagination
-	api.Get("/jobs", func(c *fiber.Ctx) error {
+	api.Get("/jobs", readJobs, func(c *fiber.Ctx) error {
 		if chConn == nil {
 			return c.Status(503).JSON(fiber.Map{
 				"error": "Database connection not available",
@@ -314,6 +506,208 @@ func main() {
 		return c.JSON(stats)
 	})
 
+	// Reindexing itself is still triggered out-of-band via cmd/index-jobs;
+	// this endpoint exists so admin:index-scoped automation can kick one off
+	// over HTTP once that wiring lands, without opening the scope up early.
+	api.Post("/admin/reindex", adminIndex, func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error": "reindexing is not yet triggerable over HTTP; run cmd/index-jobs",
+		})
+	})
+
+	// Manual workflow dispatch: start one of dispatch's registered
+	// workflows (normally only ever started from a cmd/* CLI or another
+	// workflow) directly over HTTP, for ad hoc reruns and debugging.
+	api.Post("/workflows/:name/dispatch", adminIndex, func(c *fiber.Ctx) error {
+		if temporalClient == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "Temporal connection not available"})
+		}
+
+		name := c.Params("name")
+		if !dispatch.Known(name) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fmt.Sprintf("unknown workflow %q", name)})
+		}
+
+		input, err := dispatch.DecodeInput(name, c.Body())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		workflowID := c.Query("workflow_id")
+		if workflowID == "" {
+			workflowID = "dispatch-" + name + "-" + uuid.New().String()
+		}
+
+		run, err := dispatch.Start(context.Background(), temporalClient, workflowID, name, input)
+		if err != nil {
+			log.Printf("dispatch: starting %s failed: %v", name, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start workflow"})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"workflow_id": run.GetID(),
+			"run_id":      run.GetRunID(),
+			"status_url":  fmt.Sprintf("/api/v1/workflows/status/%s/%s", run.GetID(), run.GetRunID()),
+		})
+	})
+
+	// Status lookup is keyed by (workflow_id, run_id) rather than the
+	// run_id alone: Temporal's client always addresses an execution by
+	// that pair (an empty run_id just means "latest run of this workflow
+	// ID"), so a run_id-only lookup isn't something DescribeWorkflowExecution
+	// can do.
+	api.Get("/workflows/status/:workflowID/:runID", adminIndex, func(c *fiber.Ctx) error {
+		if temporalClient == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "Temporal connection not available"})
+		}
+
+		workflowID, runID := c.Params("workflowID"), c.Params("runID")
+		desc, err := temporalClient.DescribeWorkflowExecution(context.Background(), workflowID, runID)
+		if err != nil {
+			log.Printf("dispatch: describing %s/%s failed: %v", workflowID, runID, err)
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "workflow execution not found"})
+		}
+
+		info := desc.GetWorkflowExecutionInfo()
+
+		resp := fiber.Map{
+			"workflow_id": workflowID,
+			"run_id":      runID,
+			"status":      info.GetStatus().String(),
+		}
+
+		// Closed workflows have a result worth surfacing; the registered
+		// workflows here return differently-shaped structs (*JobCrawlResult,
+		// *CareerPageCrawlResult, *DiscoveryResult), so a generic map is the
+		// only decode target that fits all of them.
+		if info.GetCloseTime() != nil {
+			var result map[string]interface{}
+			if err := temporalClient.GetWorkflow(context.Background(), workflowID, runID).Get(context.Background(), &result); err != nil {
+				resp["error"] = err.Error()
+			} else {
+				resp["result"] = result
+			}
+		}
+
+		return c.JSON(resp)
+	})
+
+	// Cancel requests a graceful stop; the workflow still runs its own
+	// cancellation handling (e.g. any defer-based cleanup) rather than
+	// being torn down immediately the way Terminate would.
+	api.Post("/workflows/status/:workflowID/:runID/cancel", adminIndex, func(c *fiber.Ctx) error {
+		if temporalClient == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "Temporal connection not available"})
+		}
+
+		workflowID, runID := c.Params("workflowID"), c.Params("runID")
+		if err := temporalClient.CancelWorkflow(context.Background(), workflowID, runID); err != nil {
+			log.Printf("dispatch: cancelling %s/%s failed: %v", workflowID, runID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to cancel workflow"})
+		}
+
+		return c.JSON(fiber.Map{"workflow_id": workflowID, "run_id": runID, "status": "cancel_requested"})
+	})
+
+	// scheduleRequest is the CRUD request/response body shape for
+	// crawl_schedules; it mirrors activities.CrawlSchedule minus the
+	// server-assigned ID/NextRunAt/LastRunAt fields a create/update body
+	// doesn't supply.
+	type scheduleRequest struct {
+		Platform      string            `json:"platform"`
+		SeedURLs      []string          `json:"seed_urls"`
+		CronExpr      string            `json:"cron_expr"`
+		MaxJobs       int               `json:"max_jobs"`
+		JitterSeconds int               `json:"jitter_seconds"`
+		Tags          map[string]string `json:"tags"`
+	}
+
+	// Schedule CRUD: operators add, edit, pause, and resume the platform
+	// crawls SchedulerWorkflow runs, without redeploying it.
+	api.Post("/schedules", adminIndex, func(c *fiber.Ctx) error {
+		if pgConn == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "database connection not available"})
+		}
+		var req scheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		id, err := activities.CreateSchedule(context.Background(), pgConn, req.Platform, req.SeedURLs, req.CronExpr, req.MaxJobs, req.JitterSeconds, req.Tags)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id})
+	})
+
+	api.Get("/schedules", adminIndex, func(c *fiber.Ctx) error {
+		if pgConn == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "database connection not available"})
+		}
+		schedules, err := activities.ListSchedules(context.Background(), pgConn)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{"schedules": schedules})
+	})
+
+	api.Get("/schedules/:id", adminIndex, func(c *fiber.Ctx) error {
+		if pgConn == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "database connection not available"})
+		}
+		id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid schedule id"})
+		}
+		schedule, err := activities.GetSchedule(context.Background(), pgConn, id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if schedule == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "schedule not found"})
+		}
+		return c.JSON(schedule)
+	})
+
+	api.Put("/schedules/:id", adminIndex, func(c *fiber.Ctx) error {
+		if pgConn == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "database connection not available"})
+		}
+		id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid schedule id"})
+		}
+		var req scheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err := activities.UpdateSchedule(context.Background(), pgConn, id, req.Platform, req.SeedURLs, req.CronExpr, req.MaxJobs, req.JitterSeconds, req.Tags); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{"id": id, "status": "updated"})
+	})
+
+	setScheduleEnabled := func(enabled bool) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			if pgConn == nil {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "database connection not available"})
+			}
+			id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid schedule id"})
+			}
+			if err := activities.SetScheduleEnabled(context.Background(), pgConn, id, enabled); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			}
+			status := "paused"
+			if enabled {
+				status = "resumed"
+			}
+			return c.JSON(fiber.Map{"id": id, "status": status})
+		}
+	}
+	api.Post("/schedules/:id/pause", adminIndex, setScheduleEnabled(false))
+	api.Post("/schedules/:id/resume", adminIndex, setScheduleEnabled(true))
+
 	// Start server
 	port := os.Getenv("API_PORT")
 	if port == "" {
@@ -326,6 +720,42 @@ func main() {
 	}
 }
 
+// sseHeartbeatInterval paces /jobs/stream's keepalive ping, sent even during
+// a quiet filter with no matching jobs. fasthttp's RequestCtx.Done() only
+// closes on server shutdown, not per-client disconnect, so a periodic write
+// is the only way to notice a client that dropped mid-quiet-period — the
+// same write-error detection writeJobEvent already relies on when a job
+// does arrive.
+const sseHeartbeatInterval = 30 * time.Second
+
+// writeJobEvent writes job as one SSE frame, using its updated_at as the
+// event ID so a reconnecting EventSource's Last-Event-ID carries it forward.
+// It returns false if the write failed (the client disconnected), signaling
+// the caller to stop streaming.
+func writeJobEvent(w *bufio.Writer, job indexer.Job) bool {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("jobs/stream: marshal error: %v", err)
+		return true
+	}
+	if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", job.UpdatedAt, payload); err != nil {
+		return false
+	}
+	return w.Flush() == nil
+}
+
+// writeHeartbeat writes an SSE comment line as sseHeartbeatInterval's
+// keepalive ping. A leading ':' is ignored by EventSource clients, so this
+// never surfaces as a spurious event — it exists purely so a write failure
+// can be detected. It returns false if the write failed (the client
+// disconnected), signaling the caller to stop streaming.
+func writeHeartbeat(w *bufio.Writer) bool {
+	if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+		return false
+	}
+	return w.Flush() == nil
+}
+
 func customErrorHandler(c *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 
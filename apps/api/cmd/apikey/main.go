@@ -0,0 +1,184 @@
+// Package main provides a CLI to create, list, and revoke the API keys
+// cmd/api's auth middleware validates requests against. Only a SHA-256
+// hash of each key is ever stored — "apikey create" prints the raw key
+// exactly once, and there is no way to recover it afterward.
+//
+// Usage:
+//
+//	apikey create --owner <name> --scopes read:jobs,admin:index [--rate-limit 60] [--expires 720h]
+//	apikey list
+//	apikey revoke --id <key-id>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/itisrohit/quiethire/apps/api/internal/auth"
+	"github.com/itisrohit/quiethire/apps/api/internal/config"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "create":
+		err = runCreate(os.Args[2:])
+	case "list":
+		err = runList(os.Args[2:])
+	case "revoke":
+		err = runRevoke(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: apikey <create|list|revoke> [flags]")
+}
+
+func runCreate(args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	owner := fs.String("owner", "", "who this key belongs to (required)")
+	scopes := fs.String("scopes", "read:jobs", "comma-separated scopes, e.g. read:jobs,admin:index")
+	rateLimit := fs.Int("rate-limit", 0, "requests/min override for this key (0 = use the default)")
+	expires := fs.Duration("expires", 0, "how long until this key expires (0 = never)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *owner == "" {
+		return fmt.Errorf("--owner is required")
+	}
+
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if err := auth.EnsureKeysTable(ctx, conn); err != nil {
+		return err
+	}
+
+	var rateLimitPtr *int32
+	if *rateLimit > 0 {
+		v := int32(*rateLimit)
+		rateLimitPtr = &v
+	}
+
+	var expiresAt *time.Time
+	if *expires > 0 {
+		t := time.Now().Add(*expires)
+		expiresAt = &t
+	}
+
+	id, raw, err := auth.CreateKey(ctx, conn, *owner, strings.Split(*scopes, ","), rateLimitPtr, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Created key %s for %s", id, *owner)
+	log.Printf("   Raw key (shown once, store it now): %s", raw)
+	return nil
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if err := auth.EnsureKeysTable(ctx, conn); err != nil {
+		return err
+	}
+
+	keys, err := auth.List(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		status := "active"
+		if k.RevokedAt != nil {
+			status = "revoked"
+		} else if k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt) {
+			status = "expired"
+		}
+		log.Printf("%s  owner=%-20s scopes=%-30s status=%s", k.ID, k.Owner, strings.Join(k.Scopes, ","), status)
+	}
+	return nil
+}
+
+func runRevoke(args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	id := fs.String("id", "", "id of the key to revoke (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := auth.Revoke(context.Background(), conn, *id); err != nil {
+		return err
+	}
+	log.Printf("✅ Revoked key %s", *id)
+	return nil
+}
+
+func connect() (clickhouse.Conn, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	conn, err := clickhouse.Open(&clickhouse.Options{
+		Addr: []string{fmt.Sprintf("%s:%d", cfg.ClickHouse.Host, cfg.ClickHouse.Port)},
+		Auth: clickhouse.Auth{
+			Database: cfg.ClickHouse.Database,
+			Username: cfg.ClickHouse.User,
+			Password: cfg.ClickHouse.Password,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
+	}
+	if err := conn.Ping(context.Background()); err != nil {
+		return nil, fmt.Errorf("clickHouse ping failed: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,127 @@
+// Package main runs a crawl-queue Acquirer: it long-polls discovered_urls
+// (via crawlqueue.Acquirer) for rows enqueued by QueueURLsForCrawling and
+// starts a CareerPageCrawlWorkflow for each one it claims. Running this as
+// its own process, separate from cmd/worker, is what lets crawl throughput
+// scale independently of however fast discovery can enqueue URLs.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/crawlqueue"
+	"github.com/itisrohit/quiethire/apps/api/internal/workflows"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"go.temporal.io/sdk/client"
+)
+
+// getEnv returns the environment variable at key, or defaultValue if unset.
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// acquirerTagsFromEnv parses CRAWL_QUEUE_TAGS ("platform=greenhouse,region=us")
+// into the tag filter Acquire matches discovered_urls.tags against. An unset
+// or empty value matches any queued row.
+func acquirerTagsFromEnv() map[string]string {
+	raw := os.Getenv("CRAWL_QUEUE_TAGS")
+	if raw == "" {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// companyName looks up a company's name by ID, falling back to an empty
+// string if it's unset or the lookup fails — CareerPageCrawlWorkflow treats
+// a missing company name as non-fatal.
+func companyName(ctx context.Context, db *sql.DB, companyID *int) string {
+	if companyID == nil {
+		return ""
+	}
+
+	var name string
+	if err := db.QueryRowContext(ctx, `SELECT name FROM companies WHERE id = $1`, *companyID).Scan(&name); err != nil {
+		log.Printf("crawl-acquirer: looking up company %d: %v", *companyID, err)
+		return ""
+	}
+	return name
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	temporalHost := getEnv("TEMPORAL_HOST", "localhost:7233")
+	c, err := client.Dial(client.Options{HostPort: temporalHost})
+	if err != nil {
+		log.Fatalln("Unable to create Temporal client", err)
+	}
+	defer c.Close()
+
+	dbHost := getEnv("DB_HOST", "localhost")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbUser := getEnv("DB_USER", "quiethire")
+	dbPassword := getEnv("DB_PASSWORD", "")
+	dbName := getEnv("DB_NAME", "quiethire")
+	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
+
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
+
+	pgConn, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatalln("Unable to connect to PostgreSQL", err)
+	}
+	defer pgConn.Close()
+	if err := pgConn.Ping(); err != nil {
+		log.Fatalln("PostgreSQL ping failed", err)
+	}
+	log.Println("✅ Connected to PostgreSQL")
+
+	acquirer := crawlqueue.NewAcquirer(pgConn, connStr)
+	defer acquirer.Close()
+
+	wantTags := acquirerTagsFromEnv()
+	log.Println("✅ Crawl acquirer started")
+	log.Printf("Tag filter: %v", wantTags)
+
+	ctx := context.Background()
+	for {
+		job, err := acquirer.Acquire(ctx, wantTags)
+		if err != nil {
+			log.Fatalln("Acquirer stopped", err)
+		}
+
+		workflowID := fmt.Sprintf("career-crawl-%d", job.ID)
+		_, err = c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+			ID:        workflowID,
+			TaskQueue: "job-crawl-queue",
+		}, workflows.CareerPageCrawlWorkflow, workflows.CareerPageCrawlInput{
+			URL:         job.URL,
+			CompanyName: companyName(ctx, pgConn, job.CompanyID),
+		})
+		if err != nil {
+			log.Printf("❌ Failed to start crawl workflow for %s: %v", job.URL, err)
+			continue
+		}
+		log.Printf("✅ Started crawl workflow %s for %s", workflowID, job.URL)
+	}
+}
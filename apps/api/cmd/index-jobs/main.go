@@ -1,68 +1,59 @@
-// Package main provides a CLI tool to index jobs from ClickHouse to Typesense.
+// Package main provides a CLI tool to index jobs from ClickHouse to
+// Typesense. Three modes share the same indexer package underneath:
+//
+//   - (default) a full reindex into a fresh timestamped collection, verified
+//     against ClickHouse's row count and then swapped in via the JobsAlias;
+//   - --since, a one-shot delta pull of everything changed since the last
+//     watermark, upserted/deleted directly against the live alias; and
+//   - --daemon, the same delta pull repeated every --interval.
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/itisrohit/quiethire/apps/api/internal/config"
+	"github.com/itisrohit/quiethire/apps/api/internal/indexer"
+	"github.com/itisrohit/quiethire/apps/api/internal/searchindex"
 	"github.com/joho/godotenv"
 	"github.com/typesense/typesense-go/typesense"
 	"github.com/typesense/typesense-go/typesense/api"
-	"github.com/typesense/typesense-go/typesense/api/pointer"
 )
 
-// Job represents a job posting with all metadata.
-//
-//nolint:govet // Field order optimized for readability over memory alignment
-type Job struct {
-	ID                 string   `json:"id"`
-	Title              string   `json:"title"`
-	Company            string   `json:"company"`
-	Description        string   `json:"description"`
-	Location           string   `json:"location"`
-	JobType            string   `json:"job_type"`
-	SourceURL          string   `json:"source_url"`
-	SourcePlatform     string   `json:"source_platform"`
-	Tags               []string `json:"tags,omitempty"`
-	PostedAt           int64    `json:"posted_at"`
-	UpdatedAt          int64    `json:"updated_at"`
-	RealScore          int32    `json:"real_score"`
-	SalaryMin          *int32   `json:"salary_min,omitempty"`
-	SalaryMax          *int32   `json:"salary_max,omitempty"`
-	Currency           *string  `json:"currency,omitempty"`
-	ExperienceLevel    *string  `json:"experience_level,omitempty"`
-	HiringManagerName  *string  `json:"hiring_manager_name,omitempty"`
-	HiringManagerEmail *string  `json:"hiring_manager_email,omitempty"`
-	Remote             bool     `json:"remote"`
-}
+// defaultKeepCollections is how many of the most recent jobs_<ts> collections
+// pruneOldCollections leaves behind after a successful reindex, so a bad
+// reindex can still be rolled back to by re-pointing the alias by hand.
+const defaultKeepCollections = 3
 
 func main() {
-	if err := run(); err != nil {
+	since := flag.Bool("since", false, "index only jobs changed since the last watermark, instead of a full reindex")
+	daemon := flag.Bool("daemon", false, "run delta indexing forever, polling every --interval (implies --since)")
+	interval := flag.Duration("interval", 30*time.Second, "poll interval for --daemon mode")
+	flag.Parse()
+
+	if err := run(*since || *daemon, *daemon, *interval); err != nil {
 		log.Fatalf("Error: %v", err)
 	}
 }
 
-//nolint:gocyclo // run function handles complete indexing workflow, complexity is acceptable
-func run() error {
-	// Load environment variables
+func run(since, daemon bool, interval time.Duration) error {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 
-	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize ClickHouse connection
 	chConn, err := clickhouse.Open(&clickhouse.Options{
 		Addr: []string{fmt.Sprintf("%s:%d", cfg.ClickHouse.Host, cfg.ClickHouse.Port)},
 		Auth: clickhouse.Auth{
@@ -85,17 +76,91 @@ func run() error {
 	}
 	log.Println("✅ Connected to ClickHouse")
 
-	// Initialize Typesense client
 	tsClient := typesense.NewClient(
 		typesense.WithServer(fmt.Sprintf("http://%s:%d", cfg.Typesense.Host, cfg.Typesense.Port)),
 		typesense.WithAPIKey(cfg.Typesense.APIKey),
 	)
 	log.Println("✅ Typesense client initialized")
 
+	if !since {
+		return runFullReindex(chConn, tsClient)
+	}
+
+	if err := indexer.EnsureStateTable(context.Background(), chConn); err != nil {
+		return err
+	}
+
+	if !daemon {
+		return runDeltaOnce(chConn, tsClient)
+	}
+
+	log.Printf("🔁 Running delta indexing every %s (Ctrl-C to stop)", interval)
+	for {
+		if err := runDeltaOnce(chConn, tsClient); err != nil {
+			log.Printf("⚠️  Delta indexing failed: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// runDeltaOnce pulls everything changed since the last watermark and
+// upserts/deletes it directly against the live JobsAlias collection, then
+// advances the watermark — there's no alias swap here, unlike a full
+// reindex, since a delta only ever touches the collection already serving
+// traffic.
+func runDeltaOnce(chConn clickhouse.Conn, tsClient *typesense.Client) error {
+	ctx := context.Background()
+
+	watermark, watermarkIDs, err := indexer.GetWatermark(ctx, chConn, searchindex.JobsAlias)
+	if err != nil {
+		return err
+	}
+
+	delta, err := indexer.FetchDelta(ctx, chConn, watermark, watermarkIDs, indexer.DefaultScoreThreshold, indexer.DefaultMaxAge)
+	if err != nil {
+		return err
+	}
+
+	if len(delta.Upserts) == 0 && len(delta.DeleteIDs) == 0 {
+		log.Println("✅ No changes since last watermark")
+		return nil
+	}
+
+	im := indexer.NewImporter(tsClient, searchindex.JobsAlias)
+
+	indexed, errored, retries, err := im.Upsert(ctx, delta.Upserts)
+	if err != nil {
+		return fmt.Errorf("upserting delta: %w", err)
+	}
+
+	deleted := 0
+	for _, id := range delta.DeleteIDs {
+		if delErr := im.Delete(ctx, id); delErr != nil {
+			log.Printf("⚠️  %v", delErr)
+			errored++
+			continue
+		}
+		deleted++
+	}
+
+	log.Printf("📈 Delta: %d upserted, %d deleted, %d errors, %d retries (watermark now %s)",
+		indexed, deleted, errored, retries, delta.Watermark.Format(time.RFC3339))
+
+	if !delta.Watermark.IsZero() {
+		if err := indexer.SetWatermark(ctx, chConn, searchindex.JobsAlias, delta.Watermark, delta.WatermarkIDs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocyclo // run function handles complete indexing workflow, complexity is acceptable
+func runFullReindex(chConn clickhouse.Conn, tsClient *typesense.Client) error {
 	// Fetch all jobs from ClickHouse
 	log.Println("📦 Fetching jobs from ClickHouse...")
 	query := `
-		SELECT 
+		SELECT
 			id, title, company, description, location, remote,
 			salary_min, salary_max, currency, job_type, experience_level,
 			real_score, hiring_manager_name, hiring_manager_email,
@@ -116,9 +181,9 @@ func run() error {
 		}
 	}()
 
-	var jobs []Job
+	var jobs []indexer.Job
 	for rows.Next() {
-		var job Job
+		var job indexer.Job
 		var remote uint8
 		var postedAt uint32
 		var updatedAt uint32
@@ -162,97 +227,21 @@ func run() error {
 		return nil
 	}
 
-	// Index jobs to Typesense in batches using JSONL format
-	batchSize := 40
-	totalIndexed := 0
-	totalErrors := 0
+	// Reindex into a fresh timestamped collection rather than upserting into
+	// the live "jobs" alias's target, so a search mid-reindex never sees a
+	// partially-loaded result set — the alias only flips over once this
+	// collection is fully built and verified.
+	collectionName := searchindex.NewCollectionName(time.Now())
+	log.Printf("🏗️  Creating collection %s...", collectionName)
+	if _, err := tsClient.Collections().Create(context.Background(), searchindex.JobsSchema(collectionName)); err != nil {
+		return fmt.Errorf("creating collection %s: %w", collectionName, err)
+	}
 
 	log.Println("🚀 Indexing jobs to Typesense...")
-	for i := 0; i < len(jobs); i += batchSize {
-		end := i + batchSize
-		if end > len(jobs) {
-			end = len(jobs)
-		}
-
-		batch := jobs[i:end]
-		log.Printf("   Batch %d-%d of %d...", i+1, end, len(jobs))
-
-		// Convert jobs to JSONL (newline-delimited JSON)
-		var buf bytes.Buffer
-		for _, job := range batch {
-			jobJSON, marshalErr := json.Marshal(job)
-			if marshalErr != nil {
-				log.Printf("⚠️  JSON marshal error: %v", marshalErr)
-				totalErrors++
-				continue
-			}
-			buf.Write(jobJSON)
-			buf.WriteString("\n")
-		}
-
-		// Import batch to Typesense
-		action := "upsert"
-		params := &api.ImportDocumentsParams{
-			Action:    &action,
-			BatchSize: pointer.Int(40),
-		}
-
-		resp, importErr := tsClient.Collection("jobs").Documents().ImportJsonl(
-			context.Background(),
-			bytes.NewReader(buf.Bytes()),
-			params,
-		)
-		if importErr != nil {
-			log.Printf("⚠️  Import error: %v", importErr)
-			totalErrors += len(batch)
-			continue
-		}
-
-		// Read response body
-		var respBuf bytes.Buffer
-		if _, readErr := respBuf.ReadFrom(resp); readErr != nil {
-			log.Printf("⚠️  Failed to read response: %v", readErr)
-			_ = resp.Close()
-			totalErrors += len(batch)
-			continue
-		}
-
-		if closeErr := resp.Close(); closeErr != nil {
-			log.Printf("Warning: Failed to close response: %v", closeErr)
-		}
-
-		// Parse results - response is JSONL with one result per line
-		successCount := 0
-		errorCount := 0
-
-		lines := strings.Split(strings.TrimSpace(respBuf.String()), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			var res map[string]interface{}
-			if unmarshalErr := json.Unmarshal([]byte(line), &res); unmarshalErr != nil {
-				log.Printf("⚠️  Parse error: %v", unmarshalErr)
-				errorCount++
-				continue
-			}
-			if success, ok := res["success"].(bool); ok && success {
-				successCount++
-			} else {
-				errorCount++
-				if errMsg, ok := res["error"].(string); ok {
-					log.Printf("   ⚠️  Document error: %s", errMsg)
-				}
-			}
-		}
-
-		totalIndexed += successCount
-		totalErrors += errorCount
-
-		log.Printf("   ✓ Indexed %d documents (%d errors)", successCount, errorCount)
-
-		// Small delay to avoid overwhelming Typesense
-		time.Sleep(100 * time.Millisecond)
+	im := indexer.NewImporter(tsClient, collectionName)
+	totalIndexed, totalErrors, totalRetries, err := im.Upsert(context.Background(), jobs)
+	if err != nil {
+		return fmt.Errorf("indexing into %s: %w", collectionName, err)
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -260,16 +249,95 @@ func run() error {
 	log.Printf("   Total jobs: %d", len(jobs))
 	log.Printf("   Indexed: %d", totalIndexed)
 	log.Printf("   Errors: %d", totalErrors)
+	log.Printf("   Retries: %d", totalRetries)
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-	// Verify index count
-	collection, err := tsClient.Collection("jobs").Retrieve(context.Background())
+	// Verify the new collection's document count matches what ClickHouse
+	// gave us before swapping the alias onto it — a mismatch here means a
+	// partial import, and the live alias should keep serving the previous
+	// collection rather than an incomplete one.
+	collection, err := tsClient.Collection(collectionName).Retrieve(context.Background())
 	if err != nil {
-		return fmt.Errorf("could not verify collection: %w", err)
+		return fmt.Errorf("could not verify collection %s: %w", collectionName, err)
+	}
+	if int(*collection.NumDocuments) != len(jobs) {
+		return fmt.Errorf("refusing to swap alias: collection %s has %d documents, expected %d",
+			collectionName, *collection.NumDocuments, len(jobs))
+	}
+	log.Printf("📊 Collection %s has %d documents, matching ClickHouse\n", collectionName, *collection.NumDocuments)
+
+	// Atomically point the live alias at the new collection. Typesense
+	// resolves alias reads/writes to whatever collection it currently maps
+	// to, so this is the instant search traffic moves over.
+	if _, err := tsClient.Aliases().Upsert(context.Background(), searchindex.JobsAlias, &api.CollectionAliasSchema{
+		CollectionName: collectionName,
+	}); err != nil {
+		return fmt.Errorf("pointing %s alias at %s: %w", searchindex.JobsAlias, collectionName, err)
+	}
+	log.Printf("✅ Alias %q now points at %q", searchindex.JobsAlias, collectionName)
+
+	if err := pruneOldCollections(context.Background(), tsClient, collectionName, keepCollectionsFromEnv()); err != nil {
+		log.Printf("⚠️  Failed to prune old collections: %v", err)
 	}
 
-	log.Printf("📊 Typesense 'jobs' collection now has %d documents\n", *collection.NumDocuments)
 	log.Println("✅ All done! You can now search jobs via /api/v1/search endpoint")
 
 	return nil
 }
+
+// keepCollectionsFromEnv returns how many jobs_<ts> collections
+// pruneOldCollections should keep, from INDEX_KEEP_COLLECTIONS, or
+// defaultKeepCollections if it's unset or invalid.
+func keepCollectionsFromEnv() int {
+	raw := os.Getenv("INDEX_KEEP_COLLECTIONS")
+	if raw == "" {
+		return defaultKeepCollections
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return defaultKeepCollections
+	}
+	return n
+}
+
+// pruneOldCollections deletes every jobs_<ts> collection except current
+// (the one the alias now points at) and the keep-1 most recent others,
+// so a reindex doesn't let old collections accumulate forever while still
+// leaving a few generations available to manually roll back to.
+func pruneOldCollections(ctx context.Context, tsClient *typesense.Client, current string, keep int) error {
+	collections, err := tsClient.Collections().Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("listing collections: %w", err)
+	}
+
+	var jobsCollections []string
+	for _, c := range collections {
+		if c.Name == current {
+			continue
+		}
+		if strings.HasPrefix(c.Name, searchindex.JobsAlias+"_") {
+			jobsCollections = append(jobsCollections, c.Name)
+		}
+	}
+
+	// Collection names are "jobs_<sortable-timestamp>", so a descending
+	// string sort is a descending time sort: newest survivors first.
+	sort.Sort(sort.Reverse(sort.StringSlice(jobsCollections)))
+
+	keepOthers := keep - 1
+	if keepOthers < 0 {
+		keepOthers = 0
+	}
+	if len(jobsCollections) <= keepOthers {
+		return nil
+	}
+
+	for _, name := range jobsCollections[keepOthers:] {
+		if _, err := tsClient.Collection(name).Delete(ctx); err != nil {
+			log.Printf("⚠️  Failed to delete old collection %s: %v", name, err)
+			continue
+		}
+		log.Printf("🗑️  Deleted old collection %s", name)
+	}
+	return nil
+}
@@ -6,11 +6,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"github.com/itisrohit/quiethire/apps/api/internal/searchindex"
 	"github.com/joho/godotenv"
 	"github.com/typesense/typesense-go/typesense"
 	"github.com/typesense/typesense-go/typesense/api"
-	"github.com/typesense/typesense-go/typesense/api/pointer"
 )
 
 func main() {
@@ -35,120 +36,28 @@ func main() {
 		typesense.WithAPIKey(apiKey),
 	)
 
-	// Define the jobs collection schema
-	schema := &api.CollectionSchema{
-		Name: "jobs",
-		Fields: []api.Field{
-			{
-				Name: "id",
-				Type: "string",
-			},
-			{
-				Name: "title",
-				Type: "string",
-			},
-			{
-				Name:  "company",
-				Type:  "string",
-				Facet: pointer.True(),
-			},
-			{
-				Name: "description",
-				Type: "string",
-			},
-			{
-				Name:  "location",
-				Type:  "string",
-				Facet: pointer.True(),
-			},
-			{
-				Name:  "remote",
-				Type:  "bool",
-				Facet: pointer.True(),
-			},
-			{
-				Name:     "salary_min",
-				Type:     "int32",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "salary_max",
-				Type:     "int32",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "currency",
-				Type:     "string",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-			{
-				Name:  "job_type",
-				Type:  "string",
-				Facet: pointer.True(),
-			},
-			{
-				Name:     "experience_level",
-				Type:     "string",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-			{
-				Name: "real_score",
-				Type: "int32",
-			},
-			{
-				Name:     "hiring_manager_name",
-				Type:     "string",
-				Optional: pointer.True(),
-			},
-			{
-				Name:     "hiring_manager_email",
-				Type:     "string",
-				Optional: pointer.True(),
-			},
-			{
-				Name: "posted_at",
-				Type: "int64",
-			},
-			{
-				Name: "updated_at",
-				Type: "int64",
-			},
-			{
-				Name: "source_url",
-				Type: "string",
-			},
-			{
-				Name:  "source_platform",
-				Type:  "string",
-				Facet: pointer.True(),
-			},
-			{
-				Name:     "tags",
-				Type:     "string[]",
-				Facet:    pointer.True(),
-				Optional: pointer.True(),
-			},
-		},
-		DefaultSortingField: pointer.String("posted_at"),
-	}
+	// Create the first timestamped collection under the real jobs schema,
+	// and point the JobsAlias at it — the same alias-swap convention
+	// cmd/index-jobs uses for every subsequent reindex, so there's never a
+	// point where a bare "jobs" collection exists without an alias over it.
+	collectionName := searchindex.NewCollectionName(time.Now())
+	schema := searchindex.JobsSchema(collectionName)
 
-	// Try to delete existing collection (if it exists)
 	ctx := context.Background()
-	_, err := client.Collection("jobs").Delete(ctx)
-	if err != nil {
-		log.Printf("Note: Could not delete existing collection (may not exist): %v", err)
-	}
-
-	// Create the collection
 	collection, err := client.Collections().Create(ctx, schema)
 	if err != nil {
 		log.Fatalf("Failed to create collection: %v", err)
 	}
-
 	log.Printf("Successfully created collection: %s", collection.Name)
 	log.Printf("Collection has %d fields", len(collection.Fields))
+
+	if _, err := client.Aliases().Upsert(ctx, searchindex.JobsAlias, &api.CollectionAliasSchema{
+		CollectionName: collectionName,
+	}); err != nil {
+		log.Fatalf("Failed to point %s alias at %s: %v", searchindex.JobsAlias, collectionName, err)
+	}
+	log.Printf("Pointed alias %q at collection %q", searchindex.JobsAlias, collectionName)
+
 	log.Println("\nTypesense schema initialized successfully!")
 	log.Println("Jobs can now be indexed with the following fields:")
 	for _, field := range collection.Fields {
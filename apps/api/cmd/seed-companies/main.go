@@ -1,93 +1,51 @@
-// Package main provides a CLI tool to seed initial companies into the database.
+// Package main provides a CLI tool to seed companies into the database from
+// a YAML seed file, with dry-run, diff, and export modes.
 package main
 
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	_ "github.com/lib/pq"
+	"gopkg.in/yaml.v3"
 )
 
-// Company represents a company to add to the database
+// Company is a single seed entry, loaded from companies.yaml (or merged in
+// from companies.d/*.yaml) and matched against the companies table by domain.
 type Company struct {
-	Name        string
-	Domain      string
-	Description string
-	Website     string
+	Name        string   `yaml:"name"`
+	Domain      string   `yaml:"domain"`
+	Description string   `yaml:"description"`
+	Website     string   `yaml:"website"`
+	Aliases     []string `yaml:"aliases,omitempty"`
+	ATSHints    []string `yaml:"ats_hints,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
 }
 
-// List of tech companies to seed
-var companies = []Company{
-	{
-		Name:        "Stripe",
-		Domain:      "stripe.com",
-		Description: "Financial infrastructure for the internet",
-		Website:     "https://stripe.com/jobs",
-	},
-	{
-		Name:        "Shopify",
-		Domain:      "shopify.com",
-		Description: "E-commerce platform for online stores",
-		Website:     "https://www.shopify.com/careers",
-	},
-	{
-		Name:        "GitHub",
-		Domain:      "github.com",
-		Description: "Code hosting platform for version control and collaboration",
-		Website:     "https://github.com/about/careers",
-	},
-	{
-		Name:        "GitLab",
-		Domain:      "gitlab.com",
-		Description: "DevOps platform for software development lifecycle",
-		Website:     "https://about.gitlab.com/jobs/",
-	},
-	{
-		Name:        "Atlassian",
-		Domain:      "atlassian.com",
-		Description: "Team collaboration and productivity software",
-		Website:     "https://www.atlassian.com/company/careers",
-	},
-	{
-		Name:        "Notion",
-		Domain:      "notion.so",
-		Description: "All-in-one workspace for notes, docs, and collaboration",
-		Website:     "https://www.notion.so/careers",
-	},
-	{
-		Name:        "Figma",
-		Domain:      "figma.com",
-		Description: "Collaborative design and prototyping platform",
-		Website:     "https://www.figma.com/careers/",
-	},
-	{
-		Name:        "Vercel",
-		Domain:      "vercel.com",
-		Description: "Platform for frontend developers",
-		Website:     "https://vercel.com/careers",
-	},
-	{
-		Name:        "Linear",
-		Domain:      "linear.app",
-		Description: "Modern issue tracking for software teams",
-		Website:     "https://linear.app/careers",
-	},
-	{
-		Name:        "Canva",
-		Domain:      "canva.com",
-		Description: "Online graphic design platform",
-		Website:     "https://www.canva.com/careers/",
-	},
+// seedFile is the on-disk shape of a companies.yaml file.
+type seedFile struct {
+	Companies []Company `yaml:"companies"`
 }
 
 func main() {
+	file := flag.String("file", "cmd/seed-companies/companies.yaml", "path to the companies YAML seed file")
+	dryRun := flag.Bool("dry-run", false, "print planned inserts without touching the database")
+	diff := flag.Bool("diff", false, "compare the seed file against the database and report adds/removes/changes")
+	export := flag.Bool("export", false, "write the current database state out to --file and exit")
+	flag.Parse()
+
 	log.Println("🏢 Seeding QuietHire with Tech Companies")
 	log.Println("========================================")
 
-	// Get database connection string from env
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", "quiethire")
@@ -116,6 +74,115 @@ func main() {
 
 	log.Printf("✅ Connected to PostgreSQL at %s:%s\n\n", dbHost, dbPort)
 
+	if *export {
+		if err := exportCompanies(db, *file); err != nil {
+			log.Fatalf("❌ Export failed: %v", err)
+		}
+		return
+	}
+
+	companies, err := loadCompanies(*file)
+	if err != nil {
+		log.Fatalf("❌ Failed to load seed file(s): %v", err)
+	}
+	log.Printf("📖 Loaded %d companies from %s\n\n", len(companies), *file)
+
+	if err := validateCompanies(companies); err != nil {
+		log.Fatalf("❌ Validation failed: %v", err)
+	}
+
+	if *diff {
+		if err := diffCompanies(db, companies); err != nil {
+			log.Fatalf("❌ Diff failed: %v", err)
+		}
+		return
+	}
+
+	seedCompanies(db, companies, *dryRun)
+}
+
+// loadCompanies reads the base seed file and merges in any per-team
+// additions under a sibling companies.d/*.yaml directory, so contributors
+// can add companies via PR without touching Go source or the base file.
+// Later files win on domain collisions.
+func loadCompanies(path string) ([]Company, error) {
+	all, err := readSeedFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := filepath.Join(filepath.Dir(path), "companies.d", "*.yaml")
+	extraFiles, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("globbing %s: %w", pattern, err)
+	}
+	sort.Strings(extraFiles)
+
+	byDomain := make(map[string]int, len(all))
+	for i, c := range all {
+		byDomain[strings.ToLower(c.Domain)] = i
+	}
+
+	for _, extraFile := range extraFiles {
+		extra, err := readSeedFile(extraFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range extra {
+			key := strings.ToLower(c.Domain)
+			if idx, ok := byDomain[key]; ok {
+				all[idx] = c
+				continue
+			}
+			byDomain[key] = len(all)
+			all = append(all, c)
+		}
+	}
+
+	return all, nil
+}
+
+// readSeedFile parses a single YAML seed file.
+func readSeedFile(path string) ([]Company, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var sf seedFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return sf.Companies, nil
+}
+
+// validateCompanies checks that every entry has the fields required to
+// insert a usable company row before any database call is made.
+func validateCompanies(companies []Company) error {
+	for _, c := range companies {
+		if c.Name == "" {
+			return fmt.Errorf("company with domain %q is missing a name", c.Domain)
+		}
+		if c.Domain == "" {
+			return fmt.Errorf("company %q is missing a domain", c.Name)
+		}
+		if strings.ContainsAny(c.Domain, "/:@") {
+			return fmt.Errorf("company %q has an invalid domain %q (expected a bare hostname)", c.Name, c.Domain)
+		}
+		if c.Website != "" {
+			u, err := url.Parse(c.Website)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("company %q has an invalid website URL %q", c.Name, c.Website)
+			}
+		}
+	}
+	return nil
+}
+
+// seedCompanies inserts any company not already present by domain. In
+// dry-run mode it reports what would be inserted without writing anything.
+func seedCompanies(db *sql.DB, companies []Company, dryRun bool) {
 	successCount := 0
 	skipCount := 0
 	failCount := 0
@@ -140,14 +207,26 @@ func main() {
 			continue
 		}
 
+		if dryRun {
+			log.Printf("   🔎 Would insert (dry-run)\n")
+			successCount++
+			continue
+		}
+
+		metadata, err := json.Marshal(companyMetadata(company))
+		if err != nil {
+			log.Printf("   ❌ Failed to encode metadata: %v\n", err)
+			failCount++
+			continue
+		}
+
 		// Insert company
 		var id int
 		err = db.QueryRowContext(context.Background(), `
 			INSERT INTO companies (name, domain, description, source, metadata)
 			VALUES ($1, $2, $3, $4, $5)
 			RETURNING id
-		`, company.Name, company.Domain, company.Description, "manual_seed",
-			fmt.Sprintf(`{"website": "%s"}`, company.Website)).Scan(&id)
+		`, company.Name, company.Domain, company.Description, "manual_seed", metadata).Scan(&id)
 
 		if err != nil {
 			log.Printf("   ❌ Failed to insert: %v\n", err)
@@ -166,6 +245,11 @@ func main() {
 	log.Printf("   Failed:  %d/%d\n", failCount, len(companies))
 	log.Println("")
 
+	if dryRun {
+		log.Println("💡 Dry run only — no changes were made. Re-run without --dry-run to apply.")
+		return
+	}
+
 	if successCount > 0 || skipCount > 0 {
 		log.Println("✨ Companies ready for discovery!")
 		log.Println("")
@@ -175,6 +259,153 @@ func main() {
 	}
 }
 
+// diffCompanies compares the seed file against the companies table and
+// reports adds, removes, and field-level changes without writing anything.
+func diffCompanies(db *sql.DB, companies []Company) error {
+	existing, err := fetchCompanies(db)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(companies))
+	var adds, changes []string
+	for _, c := range companies {
+		key := strings.ToLower(c.Domain)
+		seen[key] = true
+
+		dbC, ok := existing[key]
+		if !ok {
+			adds = append(adds, fmt.Sprintf("+ %s (%s)", c.Name, c.Domain))
+			continue
+		}
+		if dbC.Name != c.Name || dbC.Description != c.Description || dbC.Website != c.Website {
+			changes = append(changes, fmt.Sprintf("~ %s (%s)", c.Name, c.Domain))
+		}
+	}
+
+	var removes []string
+	for domain, dbC := range existing {
+		if !seen[domain] {
+			removes = append(removes, fmt.Sprintf("- %s (%s)", dbC.Name, dbC.Domain))
+		}
+	}
+
+	sort.Strings(adds)
+	sort.Strings(changes)
+	sort.Strings(removes)
+
+	log.Printf("📊 Diff: %d to add, %d changed, %d in DB but not in seed file\n", len(adds), len(changes), len(removes))
+	for _, line := range adds {
+		log.Println(line)
+	}
+	for _, line := range changes {
+		log.Println(line)
+	}
+	for _, line := range removes {
+		log.Println(line)
+	}
+
+	return nil
+}
+
+// exportCompanies writes the current companies table out to path in the
+// same YAML shape loadCompanies reads, for round-tripping DB edits back
+// into the seed file.
+func exportCompanies(db *sql.DB, path string) error {
+	existing, err := fetchCompanies(db)
+	if err != nil {
+		return err
+	}
+
+	out := seedFile{Companies: make([]Company, 0, len(existing))}
+	for _, c := range existing {
+		out.Companies = append(out.Companies, c)
+	}
+	sort.Slice(out.Companies, func(i, j int) bool {
+		return out.Companies[i].Domain < out.Companies[j].Domain
+	})
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	log.Printf("✅ Exported %d companies to %s\n", len(out.Companies), path)
+	return nil
+}
+
+// fetchCompanies loads every row from the companies table, keyed by
+// lowercased domain, decoding the website/aliases/ats_hints/tags back out
+// of the metadata JSON blob.
+func fetchCompanies(db *sql.DB) (map[string]Company, error) {
+	rows, err := db.Query("SELECT name, domain, description, metadata FROM companies")
+	if err != nil {
+		return nil, fmt.Errorf("querying companies: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]Company)
+	for rows.Next() {
+		var name, domain, description string
+		var metadataRaw []byte
+		if err := rows.Scan(&name, &domain, &description, &metadataRaw); err != nil {
+			return nil, fmt.Errorf("scanning company row: %w", err)
+		}
+
+		c := Company{Name: name, Domain: domain, Description: description}
+		var meta map[string]interface{}
+		if len(metadataRaw) > 0 {
+			if err := json.Unmarshal(metadataRaw, &meta); err == nil {
+				c.Website, _ = meta["website"].(string)
+				c.Aliases = stringSlice(meta["aliases"])
+				c.ATSHints = stringSlice(meta["ats_hints"])
+				c.Tags = stringSlice(meta["tags"])
+			}
+		}
+		existing[strings.ToLower(domain)] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating company rows: %w", err)
+	}
+
+	return existing, nil
+}
+
+// companyMetadata builds the JSON blob stored in companies.metadata.
+func companyMetadata(c Company) map[string]interface{} {
+	m := map[string]interface{}{"website": c.Website}
+	if len(c.Aliases) > 0 {
+		m["aliases"] = c.Aliases
+	}
+	if len(c.ATSHints) > 0 {
+		m["ats_hints"] = c.ATSHints
+	}
+	if len(c.Tags) > 0 {
+		m["tags"] = c.Tags
+	}
+	return m
+}
+
+// stringSlice coerces a decoded JSON value into a []string, used when
+// re-reading the metadata blob's aliases/ats_hints/tags arrays.
+func stringSlice(v interface{}) []string {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
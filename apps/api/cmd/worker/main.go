@@ -2,15 +2,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/itisrohit/quiethire/apps/api/internal/activities"
+	"github.com/itisrohit/quiethire/apps/api/internal/archive"
+	"github.com/itisrohit/quiethire/apps/api/internal/chbatch"
+	"github.com/itisrohit/quiethire/apps/api/internal/crawler"
+	"github.com/itisrohit/quiethire/apps/api/internal/eventbus"
 	"github.com/itisrohit/quiethire/apps/api/internal/workflows"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -18,6 +25,120 @@ import (
 	"go.temporal.io/sdk/worker"
 )
 
+// crawlerTagsFromEnv parses CRAWLER_TAGS ("platform=linkedin,requires=browser")
+// into the capability set this worker advertises to CrawlAcquirer.AcquireJob,
+// the same "key=value,..." convention acquirerTagsFromEnv already uses in
+// cmd/crawl-acquirer for discovered_urls. A crawl_jobs row can only be
+// claimed here once every tag it requires also appears here. An unset or
+// empty value advertises no capabilities, so this worker only ever claims
+// untagged jobs.
+func crawlerTagsFromEnv() map[string]string {
+	raw := os.Getenv("CRAWLER_TAGS")
+	if raw == "" {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// eventBusFromEnv builds the eventbus.Publisher PublishCrawlEvent fans
+// crawl lifecycle events out through, selected by EVENTBUS_KIND ("redis" or
+// "nats", default "redis") and EVENTBUS_ADDR. An unset or unreachable
+// backend is non-fatal, like the ClickHouse/PostgreSQL connections below —
+// the worker still runs, just without that event stream.
+func eventBusFromEnv() eventbus.Publisher {
+	kind := os.Getenv("EVENTBUS_KIND")
+	if kind == "" {
+		kind = "redis"
+	}
+	addr := os.Getenv("EVENTBUS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	bus, err := eventbus.New(eventbus.Config{Kind: kind, Addr: addr})
+	if err != nil {
+		log.Printf("Warning: Failed to connect to event bus (%s %s): %v", kind, addr, err)
+		return nil
+	}
+	return bus
+}
+
+// discovererFromEnv builds the crawler.Discoverer DiscoverJobURLs crawls
+// through, tuned by CRAWL_DISCOVERY_QPS/CRAWL_DISCOVERY_BURST/
+// CRAWL_DISCOVERY_PARALLELISM (each falling back to crawler.Config's own
+// defaults when unset or unparseable) and CRAWL_DISCOVERY_CACHE_DIR (unset
+// disables on-disk response caching). A construction failure is non-fatal,
+// like eventBusFromEnv above — DiscoverJobURLs just degrades to passing
+// seed URLs through unchanged.
+func discovererFromEnv() *crawler.Discoverer {
+	cfg := crawler.Config{CacheDir: os.Getenv("CRAWL_DISCOVERY_CACHE_DIR")}
+
+	if raw := os.Getenv("CRAWL_DISCOVERY_QPS"); raw != "" {
+		if qps, err := strconv.ParseFloat(raw, 64); err == nil {
+			cfg.QPS = qps
+		} else {
+			log.Printf("Warning: invalid CRAWL_DISCOVERY_QPS %q, using default", raw)
+		}
+	}
+	if raw := os.Getenv("CRAWL_DISCOVERY_BURST"); raw != "" {
+		if burst, err := strconv.ParseFloat(raw, 64); err == nil {
+			cfg.Burst = burst
+		} else {
+			log.Printf("Warning: invalid CRAWL_DISCOVERY_BURST %q, using default", raw)
+		}
+	}
+	if raw := os.Getenv("CRAWL_DISCOVERY_PARALLELISM"); raw != "" {
+		if parallelism, err := strconv.Atoi(raw); err == nil {
+			cfg.Parallelism = parallelism
+		} else {
+			log.Printf("Warning: invalid CRAWL_DISCOVERY_PARALLELISM %q, using default", raw)
+		}
+	}
+
+	discoverer, err := crawler.NewDiscoverer(cfg)
+	if err != nil {
+		log.Printf("Warning: Failed to configure job discoverer: %v", err)
+		return nil
+	}
+	return discoverer
+}
+
+// batcherConfigFromEnv builds the chbatch.Config a ClickHouseBatcher flushes
+// jobs_raw_html and jobs rows by, tuned by CLICKHOUSE_BATCH_FLUSH_ROWS
+// (falls back to chbatch's own default of 1000) and
+// CLICKHOUSE_BATCH_FLUSH_INTERVAL (falls back to 500ms). An unset or
+// unparseable value just keeps that default, same tolerance
+// discovererFromEnv's env vars get.
+func batcherConfigFromEnv() chbatch.Config {
+	var cfg chbatch.Config
+
+	if raw := os.Getenv("CLICKHOUSE_BATCH_FLUSH_ROWS"); raw != "" {
+		if rows, err := strconv.Atoi(raw); err == nil {
+			cfg.FlushRows = rows
+		} else {
+			log.Printf("Warning: invalid CLICKHOUSE_BATCH_FLUSH_ROWS %q, using default", raw)
+		}
+	}
+	if raw := os.Getenv("CLICKHOUSE_BATCH_FLUSH_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			cfg.FlushInterval = d
+		} else {
+			log.Printf("Warning: invalid CLICKHOUSE_BATCH_FLUSH_INTERVAL %q, using default", raw)
+		}
+	}
+
+	return cfg
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -71,6 +192,12 @@ func main() {
 		log.Printf("Warning: Failed to connect to ClickHouse: %v", err)
 	} else {
 		log.Println("✅ Connected to ClickHouse")
+		if err := activities.EnsureJobsTable(context.Background(), chConn); err != nil {
+			log.Printf("Warning: failed to ensure jobs table: %v", err)
+		}
+		if err := activities.EnsureJobScoresTable(context.Background(), chConn); err != nil {
+			log.Printf("Warning: failed to ensure job_scores table: %v", err)
+		}
 	}
 
 	// Initialize PostgreSQL connection using environment variables directly
@@ -96,8 +223,25 @@ func main() {
 		}
 	}
 
+	// workerDrainTimeout bounds how long Stop() waits for in-flight
+	// activities (CrawlJobBatch, ParseJobActivity, FetchAndExtractLinks,
+	// ...) to finish once InterruptCh fires, before the worker process
+	// exits anyway. worker.Run(worker.InterruptCh()) already installs the
+	// SIGINT/SIGTERM handler; WorkerStopTimeout is what makes that shutdown
+	// graceful instead of immediate.
+	workerDrainTimeout := 60 * time.Second
+	if raw := getEnv("WORKER_DRAIN_TIMEOUT", ""); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			workerDrainTimeout = d
+		} else {
+			log.Printf("Warning: invalid WORKER_DRAIN_TIMEOUT %q, using default %s", raw, workerDrainTimeout)
+		}
+	}
+
 	// Create worker
-	w := worker.New(c, "job-crawl-queue", worker.Options{})
+	w := worker.New(c, "job-crawl-queue", worker.Options{
+		WorkerStopTimeout: workerDrainTimeout,
+	})
 
 	// Register workflows
 	w.RegisterWorkflow(workflows.CrawlCoordinatorWorkflow)
@@ -105,12 +249,30 @@ func main() {
 	w.RegisterWorkflow(workflows.CompanyDiscoveryWorkflow)
 	w.RegisterWorkflow(workflows.ContinuousDiscoveryWorkflow)
 	w.RegisterWorkflow(workflows.GoogleDorkDiscoveryWorkflow)
+	w.RegisterWorkflow(workflows.CareerSiteCrawlWorkflow)
+	w.RegisterWorkflow(workflows.CareerPageCrawlWorkflow)
+	w.RegisterWorkflow(workflows.SchedulerWorkflow)
+	w.RegisterWorkflow(workflows.ReaperWorkflow)
 
 	// Get service URLs from environment or config
 	crawlerURL := getEnv("CRAWLER_SERVICE_URL", "http://localhost:8002")
 	parserURL := getEnv("PARSER_SERVICE_URL", "http://localhost:8001")
 	osintURL := getEnv("OSINT_SERVICE_URL", "http://localhost:8004")
 
+	// batcher buffers jobs_raw_html and jobs rows and flushes them to
+	// ClickHouse in PrepareBatch-sized batches instead of one INSERT per
+	// job — see chbatch.ClickHouseBatcher's doc comment. Only built when
+	// chConn connected; a nil batcher leaves storeJobData/storeParsedJob on
+	// their original one-Exec-per-job path.
+	var batcher *chbatch.ClickHouseBatcher
+	if chConn != nil {
+		batcher = chbatch.NewClickHouseBatcher(chConn, batcherConfigFromEnv(),
+			chbatch.TableConfig{Table: archive.JobsRawHTMLTable, InsertQuery: archive.JobsRawHTMLInsertQuery},
+			chbatch.TableConfig{Table: activities.JobsTable, InsertQuery: activities.JobsInsertQuery},
+		)
+		batcher.Run(context.Background())
+	}
+
 	// Initialize and register crawl activities
 	crawlActivities := &activities.CrawlActivities{
 		HTTPClient: httpClient,
@@ -118,12 +280,39 @@ func main() {
 		ParserURL:  parserURL,
 		OSINTUrl:   osintURL,
 		ClickHouse: chConn,
+		PostgreSQL: pgConn,
+		EventBus:   eventBusFromEnv(),
+		Discoverer: discovererFromEnv(),
+		Batcher:    batcher,
+	}
+	if batcher != nil {
+		crawlActivities.Archive = archive.NewClickHouseBackendWithBatcher(chConn, batcher)
+	}
+
+	// crawlerTags is this worker's advertised capability set: CrawlJobBatch
+	// enqueues into crawl_jobs rather than crawling inline, and this worker
+	// only claims a row via CrawlAcquirer.AcquireJob once it can satisfy
+	// every tag that row requires. Running several cmd/worker instances with
+	// different CRAWLER_TAGS is how heterogeneous crawlers (headless-browser,
+	// platform-specific, Tor-exit, ...) each only pick up what they can run.
+	crawlerTags := crawlerTagsFromEnv()
+	if pgConn != nil {
+		if err := activities.EnsureCrawlJobsTable(context.Background(), pgConn); err != nil {
+			log.Printf("Warning: failed to ensure crawl_jobs table: %v", err)
+		} else {
+			acquirer := activities.NewCrawlAcquirer(pgConn, connStr)
+			crawlActivities.CrawlAcquirer = acquirer
+			go crawlActivities.RunCrawlAcquirerLoop(context.Background(), crawlerTags)
+		}
 	}
+
 	w.RegisterActivity(crawlActivities.DiscoverJobURLs)
 	w.RegisterActivity(crawlActivities.CrawlJobBatch)
 	w.RegisterActivity(crawlActivities.ParseJobActivity)
 	w.RegisterActivity(crawlActivities.ScoreJobActivity)
 	w.RegisterActivity(crawlActivities.ExtractHiringManagerActivity)
+	w.RegisterActivity(crawlActivities.FetchAndExtractLinks)
+	w.RegisterActivity(crawlActivities.PublishCrawlEvent)
 
 	// Initialize and register discovery activities
 	discoveryActivities := &activities.DiscoveryActivities{
@@ -141,15 +330,41 @@ func main() {
 	w.RegisterActivity(discoveryActivities.GenerateDorkQueries)
 	w.RegisterActivity(discoveryActivities.ExecuteDorkQuery)
 	w.RegisterActivity(discoveryActivities.DetectATSAndExtractDomain)
+	w.RegisterActivity(discoveryActivities.DiscoverFromProvider)
+	w.RegisterActivity(discoveryActivities.RankCareerPages)
+
+	// Initialize and register schedule activities
+	scheduleActivities := &activities.ScheduleActivities{PostgreSQL: pgConn}
+	if pgConn != nil {
+		if err := activities.EnsureCrawlSchedulesTable(context.Background(), pgConn); err != nil {
+			log.Printf("Warning: failed to ensure crawl_schedules table: %v", err)
+		}
+	}
+	w.RegisterActivity(scheduleActivities.LoadDueSchedules)
+	w.RegisterActivity(scheduleActivities.MarkScheduleRun)
+
+	// Initialize and register reaper activities. These reuse the same
+	// Temporal client the worker itself connects with, since reclaiming a
+	// stuck execution needs visibility/termination calls no activity
+	// context gives you on its own.
+	reaperActivities := &activities.ReaperActivities{Client: c}
+	w.RegisterActivity(reaperActivities.FindStuckCareerPageCrawls)
+	w.RegisterActivity(reaperActivities.CancelStuckWorkflow)
 
 	log.Println("✅ Temporal worker started")
 	log.Println("Task Queue: job-crawl-queue")
+	log.Printf("Crawl tags: %v", crawlerTags)
 	log.Println("Registered Workflows:")
 	log.Println("  - CrawlCoordinatorWorkflow")
 	log.Println("  - ScheduledCrawlWorkflow")
 	log.Println("  - CompanyDiscoveryWorkflow")
 	log.Println("  - ContinuousDiscoveryWorkflow")
 	log.Println("  - GoogleDorkDiscoveryWorkflow")
+	log.Println("  - CareerSiteCrawlWorkflow")
+	log.Println("  - CareerPageCrawlWorkflow")
+	log.Println("  - SchedulerWorkflow")
+	log.Println("  - ReaperWorkflow")
+	log.Printf("Worker drain timeout: %s", workerDrainTimeout)
 	log.Println("Registered Activities:")
 	log.Println("  Crawl Activities:")
 	log.Println("    - DiscoverJobURLs")
@@ -157,6 +372,8 @@ func main() {
 	log.Println("    - ParseJobActivity")
 	log.Println("    - ScoreJobActivity")
 	log.Println("    - ExtractHiringManagerActivity")
+	log.Println("    - FetchAndExtractLinks")
+	log.Println("    - PublishCrawlEvent")
 	log.Println("  Discovery Activities:")
 	log.Println("    - DiscoverCompaniesFromGitHub")
 	log.Println("    - DiscoverCompaniesFromGoogleDorks")
@@ -168,9 +385,23 @@ func main() {
 	log.Println("    - GenerateDorkQueries")
 	log.Println("    - ExecuteDorkQuery")
 	log.Println("    - DetectATSAndExtractDomain")
+	log.Println("    - DiscoverFromProvider")
+	log.Println("    - RankCareerPages")
+	log.Println("  Schedule Activities:")
+	log.Println("    - LoadDueSchedules")
+	log.Println("    - MarkScheduleRun")
+	log.Println("  Reaper Activities:")
+	log.Println("    - FindStuckCareerPageCrawls")
+	log.Println("    - CancelStuckWorkflow")
 
 	// Start listening to the Task Queue
 	if err := w.Run(worker.InterruptCh()); err != nil {
 		log.Fatalln("Unable to start worker", err) //nolint:gocritic // Acceptable pattern for worker exit
 	}
+
+	if batcher != nil {
+		log.Println("Draining ClickHouse batcher...")
+		batcher.Stop()
+		log.Println("✅ ClickHouse batcher drained")
+	}
 }
@@ -0,0 +1,152 @@
+package activities
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CareerPageScorer scores a CareerPageInfo for ranking and dedup; higher
+// scores rank first. DiscoveryActivities.Comparator is how callers plug in a
+// custom scorer (or any other ordering) without touching RankCareerPages.
+type CareerPageScorer interface {
+	Score(page CareerPageInfo) float64
+}
+
+// atsPlatformWeight scores known ATS platforms above a generic/unknown one.
+var atsPlatformWeight = map[string]float64{
+	"greenhouse": 3,
+	"lever":      3,
+	"ashby":      2.5,
+	"workday":    2,
+}
+
+// careerPathSignals are URL path substrings that indicate a genuine careers
+// page rather than some other page on the domain.
+var careerPathSignals = []string{"/careers", "/jobs", "/join-us"}
+
+// sourceTrustWeight ranks discovery sources by how reliable their results
+// tend to be: a manually-added or GitHub-sourced company is more likely to be
+// a real target than one scraped off a Google dork or guessed via subdomain
+// enumeration.
+var sourceTrustWeight = map[string]float64{
+	"manual":      4,
+	"github":      3,
+	"google_dork": 2,
+	"subdomains":  1,
+	"ats":         1,
+}
+
+// defaultCareerPageScorer is the built-in CareerPageScorer, used whenever
+// DiscoveryActivities.Comparator is unset. It combines ATS-platform weight,
+// career-path URL signals, DetectATS confidence, and source trust.
+type defaultCareerPageScorer struct{}
+
+func (defaultCareerPageScorer) Score(page CareerPageInfo) float64 {
+	score := atsPlatformWeight[strings.ToLower(page.ATSPlatform)]
+
+	lowerURL := strings.ToLower(page.URL)
+	for _, signal := range careerPathSignals {
+		if strings.Contains(lowerURL, signal) {
+			score++
+			break
+		}
+	}
+
+	score += page.Confidence * 2
+	score += sourceTrustWeight[page.Source]
+
+	return score
+}
+
+// defaultComparator orders CareerPageInfo by defaultCareerPageScorer score,
+// highest first.
+func defaultComparator(x, y CareerPageInfo) int {
+	scorer := defaultCareerPageScorer{}
+	sx, sy := scorer.Score(x), scorer.Score(y)
+	switch {
+	case sx > sy:
+		return -1
+	case sx < sy:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparator returns a.Comparator, falling back to defaultComparator when
+// it's unset — the same lazy-default pattern providers() uses for
+// DiscoveryActivities.Providers.
+func (a *DiscoveryActivities) comparator() func(x, y CareerPageInfo) int {
+	if a.Comparator != nil {
+		return a.Comparator
+	}
+	return defaultComparator
+}
+
+// canonicalCareerPageURL normalizes a CareerPageInfo's URL for dedup:
+// lowercase scheme/host, drop query/fragment, and strip a trailing slash, so
+// "https://Acme.com/careers?ref=x" and "https://acme.com/careers" collapse to
+// the same key.
+func canonicalCareerPageURL(page CareerPageInfo) string {
+	u, err := url.Parse(page.URL)
+	if err != nil {
+		return strings.ToLower(strings.TrimSuffix(page.URL, "/"))
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// RankCareerPages sorts pages by a's Comparator (best first), collapses
+// duplicates keyed by canonical URL down to the highest-ranked variant, and
+// assigns each surviving page's Priority from its rank, so
+// QueueURLsForCrawling writes a priority that reflects ATS platform, path
+// signals, confidence, and source trust rather than a flat per-provider
+// constant.
+func (a *DiscoveryActivities) RankCareerPages(_ context.Context, pages []CareerPageInfo) ([]CareerPageInfo, error) {
+	cmp := a.comparator()
+
+	ranked := make([]CareerPageInfo, len(pages))
+	copy(ranked, pages)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return cmp(ranked[i], ranked[j]) < 0
+	})
+
+	seen := make(map[string]bool, len(ranked))
+	deduped := make([]CareerPageInfo, 0, len(ranked))
+	for _, page := range ranked {
+		key := canonicalCareerPageURL(page)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, page)
+	}
+
+	for i := range deduped {
+		deduped[i].Priority = careerPagePriority(i, len(deduped))
+	}
+
+	return deduped, nil
+}
+
+// careerPagePriority maps a page's 0-indexed rank among n ranked pages to a
+// 1-5 priority, with the best-ranked page getting the highest priority.
+func careerPagePriority(rank, n int) int {
+	if n <= 1 {
+		return 5
+	}
+	scaled := 5 - (rank * 4 / (n - 1))
+	if scaled < 1 {
+		scaled = 1
+	}
+	if scaled > 5 {
+		scaled = 5
+	}
+	return scaled
+}
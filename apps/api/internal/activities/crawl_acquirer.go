@@ -0,0 +1,312 @@
+package activities
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CrawlJobsNotifyChannel is the Postgres NOTIFY channel EnqueueJob and
+// CrawlAcquirer share, mirroring crawlqueue's discovered_urls_queue channel
+// one layer down: discovered_urls decides which career pages get crawled at
+// all, while crawl_jobs decides which individual crawl (a career page fetch,
+// a job detail page, an ATS API call, ...) a given worker is equipped to
+// run, based on capability tags instead of blind task-queue membership.
+const CrawlJobsNotifyChannel = "crawl_jobs_queue"
+
+// DefaultCrawlLeaseDuration is how long AcquireJob holds a claimed row
+// before another Acquirer is allowed to reclaim it, absent a HeartbeatJob
+// call extending it. It should comfortably exceed a single crawl's
+// expected duration so a slow-but-alive worker doesn't lose its claim.
+const DefaultCrawlLeaseDuration = 5 * time.Minute
+
+// DefaultPollInterval and DefaultDebounce mirror crawlqueue.Acquirer's
+// constants of the same name: the fallback claim-attempt period when no
+// NOTIFY arrives, and how long to coalesce a burst of NOTIFYs into one
+// wake-up.
+const (
+	DefaultPollInterval = 30 * time.Second
+	DefaultDebounce     = 200 * time.Millisecond
+)
+
+// CrawlJob is one crawl_jobs row claimed by a CrawlAcquirer.
+type CrawlJob struct {
+	ID       int64
+	URL      string
+	Platform string
+	Tags     map[string]string
+	Attempts int
+}
+
+// CrawlAcquirer lets heterogeneous crawl workers (headless-browser,
+// platform-specific, Tor-exit, residential-proxy, ...) pull crawl_jobs rows
+// whose required tags are a subset of the worker's own advertised
+// capabilities, instead of Temporal's task queue implicitly assuming any
+// worker listening on job-crawl-queue can run any crawl. AcquireJob blocks
+// on Postgres NOTIFY (falling back to a poll interval) the same way
+// crawlqueue.Acquirer does for discovered_urls.
+type CrawlAcquirer struct {
+	db       *sql.DB
+	listener *pq.Listener
+
+	// LeaseDuration is how long a claim is held before it's eligible for
+	// reclaim. <= 0 falls back to DefaultCrawlLeaseDuration.
+	LeaseDuration time.Duration
+	// PollInterval is the fallback claim-attempt period if no NOTIFY
+	// arrives. <= 0 falls back to DefaultPollInterval (shared with
+	// crawlqueue's default).
+	PollInterval time.Duration
+	// Debounce coalesces a burst of NOTIFYs into one claim attempt. <= 0
+	// falls back to DefaultDebounce.
+	Debounce time.Duration
+}
+
+// NewCrawlAcquirer opens a dedicated LISTEN connection on
+// CrawlJobsNotifyChannel. connStr must be the same DSN db was opened with.
+func NewCrawlAcquirer(db *sql.DB, connStr string) *CrawlAcquirer {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("crawl_acquirer: listener event %v: %v", ev, err)
+		}
+	})
+	if err := listener.Listen(CrawlJobsNotifyChannel); err != nil {
+		log.Printf("crawl_acquirer: failed to LISTEN on %s: %v", CrawlJobsNotifyChannel, err)
+	}
+	return &CrawlAcquirer{db: db, listener: listener}
+}
+
+// Close releases the CrawlAcquirer's LISTEN connection.
+func (a *CrawlAcquirer) Close() error {
+	return a.listener.Close()
+}
+
+// EnsureCrawlJobsTable creates crawl_jobs and its notify trigger if they
+// don't already exist.
+func EnsureCrawlJobsTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS crawl_jobs (
+			id               BIGSERIAL PRIMARY KEY,
+			url              TEXT NOT NULL,
+			platform         TEXT NOT NULL,
+			tags             JSONB NOT NULL DEFAULT '{}'::jsonb,
+			status           TEXT NOT NULL DEFAULT 'pending',
+			priority         INT NOT NULL DEFAULT 0,
+			attempts         INT NOT NULL DEFAULT 0,
+			lease_expires_at TIMESTAMPTZ,
+			created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating crawl_jobs table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS crawl_jobs_claimable_idx
+		ON crawl_jobs (status, priority DESC, created_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating crawl_jobs index: %w", err)
+	}
+	return nil
+}
+
+// EnqueueJob inserts a pending crawl_jobs row for url and notifies any
+// listening CrawlAcquirer. tags are the capabilities this crawl requires
+// (e.g. {"platform": "linkedin", "requires": "browser", "region": "us"});
+// a worker can only AcquireJob it once every key/value here is also present
+// in the worker's own tag set.
+func EnqueueJob(ctx context.Context, db *sql.DB, url, platform string, tags map[string]string, priority int) error {
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("encoding tags: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO crawl_jobs (url, platform, tags, priority)
+		VALUES ($1, $2, $3::jsonb, $4)
+	`, url, platform, tagsJSON, priority)
+	if err != nil {
+		return fmt.Errorf("enqueuing crawl job for %s: %w", url, err)
+	}
+
+	if _, err := db.ExecContext(ctx, `SELECT pg_notify($1, '')`, CrawlJobsNotifyChannel); err != nil {
+		log.Printf("crawl_acquirer: failed to notify %s: %v", CrawlJobsNotifyChannel, err)
+	}
+	return nil
+}
+
+// AcquireJob blocks until a crawl_jobs row whose tags are satisfied by
+// workerTags is claimed, or ctx is done. A nil or empty workerTags only
+// matches rows with no tag requirements at all.
+func (a *CrawlAcquirer) AcquireJob(ctx context.Context, workerTags map[string]string) (*CrawlJob, error) {
+	pollInterval := a.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	debounce := a.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	for {
+		job, err := a.tryClaim(ctx, workerTags)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			return job, nil
+		}
+
+		if err := a.waitForWork(ctx, pollInterval, debounce); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (a *CrawlAcquirer) waitForWork(ctx context.Context, pollInterval, debounce time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case n := <-a.listener.Notify:
+		if n == nil {
+			return nil
+		}
+		return a.drainBurst(ctx, debounce)
+	case <-time.After(pollInterval):
+		return nil
+	}
+}
+
+func (a *CrawlAcquirer) drainBurst(ctx context.Context, debounce time.Duration) error {
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-a.listener.Notify:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(debounce)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryClaim attempts to claim one row whose tags are a subset of workerTags,
+// using SELECT ... FOR UPDATE SKIP LOCKED so concurrent Acquirers never
+// double-claim. It also reclaims rows whose lease has already expired,
+// covering a worker that claimed a job and then crashed or stopped
+// heartbeating. Returns (nil, nil) if nothing matched.
+func (a *CrawlAcquirer) tryClaim(ctx context.Context, workerTags map[string]string) (*CrawlJob, error) {
+	leaseDuration := a.LeaseDuration
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultCrawlLeaseDuration
+	}
+
+	if workerTags == nil {
+		// A nil map marshals to JSON null, which contains no tags object.
+		workerTags = map[string]string{}
+	}
+	tagsJSON, err := json.Marshal(workerTags)
+	if err != nil {
+		return nil, fmt.Errorf("encoding worker tags: %w", err)
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var job CrawlJob
+	var jobTagsJSON []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE crawl_jobs
+		SET status = 'leased',
+		    attempts = attempts + 1,
+		    lease_expires_at = now() + ($2 * interval '1 second')
+		WHERE id = (
+			SELECT id FROM crawl_jobs
+			WHERE tags <@ $1::jsonb
+			  AND (status = 'pending' OR (status = 'leased' AND lease_expires_at < now()))
+			ORDER BY priority DESC, created_at ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, url, platform, tags, attempts
+	`, tagsJSON, leaseDuration.Seconds()).Scan(&job.ID, &job.URL, &job.Platform, &jobTagsJSON, &job.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claiming crawl job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing claim: %w", err)
+	}
+
+	if len(jobTagsJSON) > 0 {
+		if err := json.Unmarshal(jobTagsJSON, &job.Tags); err != nil {
+			return nil, fmt.Errorf("decoding tags: %w", err)
+		}
+	}
+	return &job, nil
+}
+
+// HeartbeatJob extends a held lease by leaseDuration (or
+// DefaultCrawlLeaseDuration if <= 0), so a crawl that's taking longer than
+// one lease period doesn't get reclaimed out from under the worker still
+// running it. It returns an error if the lease was already lost — the
+// caller should abandon the job rather than keep working on it.
+func (a *CrawlAcquirer) HeartbeatJob(ctx context.Context, jobID int64, leaseDuration time.Duration) error {
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultCrawlLeaseDuration
+	}
+
+	result, err := a.db.ExecContext(ctx, `
+		UPDATE crawl_jobs
+		SET lease_expires_at = now() + ($2 * interval '1 second')
+		WHERE id = $1 AND status = 'leased'
+	`, jobID, leaseDuration.Seconds())
+	if err != nil {
+		return fmt.Errorf("heartbeating crawl job %d: %w", jobID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking heartbeat result for crawl job %d: %w", jobID, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("lease for crawl job %d was lost", jobID)
+	}
+	return nil
+}
+
+// CompleteJob marks a claimed job done.
+func (a *CrawlAcquirer) CompleteJob(ctx context.Context, jobID int64) error {
+	if _, err := a.db.ExecContext(ctx, `UPDATE crawl_jobs SET status = 'done' WHERE id = $1`, jobID); err != nil {
+		return fmt.Errorf("completing crawl job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// FailJob marks a claimed job failed. It doesn't automatically requeue —
+// a human or a higher-level workflow decides whether a failed crawl is
+// worth re-enqueuing.
+func (a *CrawlAcquirer) FailJob(ctx context.Context, jobID int64, reason error) error {
+	if _, err := a.db.ExecContext(ctx, `UPDATE crawl_jobs SET status = 'failed' WHERE id = $1`, jobID); err != nil {
+		return fmt.Errorf("failing crawl job %d: %w", jobID, err)
+	}
+	log.Printf("crawl_acquirer: job %d failed: %v", jobID, reason)
+	return nil
+}
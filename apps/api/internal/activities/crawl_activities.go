@@ -2,20 +2,76 @@
 package activities
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/google/uuid"
+	"github.com/itisrohit/quiethire/apps/api/internal/archive"
+	"github.com/itisrohit/quiethire/apps/api/internal/chbatch"
+	"github.com/itisrohit/quiethire/apps/api/internal/crawler"
+	"github.com/itisrohit/quiethire/apps/api/internal/eventbus"
+	"github.com/itisrohit/quiethire/apps/api/internal/httpx"
+	"github.com/itisrohit/quiethire/apps/api/internal/parsers"
+	"github.com/itisrohit/quiethire/apps/api/internal/scoring"
 )
 
+// JobsTable and JobsInsertQuery let a caller wire a
+// chbatch.ClickHouseBatcher for the jobs table with the exact table name
+// and column list storeParsedJob itself inserts with.
+const (
+	JobsTable       = "jobs"
+	JobsInsertQuery = `INSERT INTO jobs (
+		id, job_hash, title, company, description, location, remote,
+		salary_min, salary_max, currency, job_type, experience_level,
+		real_score, source_url, source_platform, tags,
+		posted_at, updated_at, version, content_hash
+	)`
+)
+
+// ParsedJobOutcome is what storeParsedJob did with a parsed job row,
+// returned to ParseJobActivity's caller (and counted in jobsStoreOutcome)
+// so a churn-heavy platform is visible instead of silently re-inserting an
+// unchanged listing on every re-crawl.
+type ParsedJobOutcome string
+
+const (
+	// JobInserted is the first row ever stored for a job_hash.
+	JobInserted ParsedJobOutcome = "inserted"
+	// JobSkippedDup means the job_hash already has a row whose
+	// content_hash is unchanged; nothing was inserted.
+	JobSkippedDup ParsedJobOutcome = "skipped_dup"
+	// JobUpdated means the job_hash already has a row, but content_hash
+	// changed; a new row was inserted at the prior version + 1.
+	JobUpdated ParsedJobOutcome = "updated"
+	// JobStoreUnavailable means a.ClickHouse is nil, so nothing was
+	// checked or stored — the pre-existing degraded-but-running path.
+	JobStoreUnavailable ParsedJobOutcome = "unavailable"
+)
+
+// jobsStoreOutcome counts what storeParsedJob decided per call, so a
+// platform whose listings churn (or whose scraper is stuck returning the
+// same content) shows up as a change in the skipped_dup/updated ratio
+// rather than just a rising row count.
+var jobsStoreOutcome = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "jobs_store_outcome_total",
+	Help: "storeParsedJob outcomes: inserted, skipped_dup, updated, or unavailable.",
+}, []string{"outcome"})
+
 // CrawlActivities contains all crawling-related activities
 type CrawlActivities struct {
 	// HTTP client for calling microservices
@@ -28,6 +84,66 @@ type CrawlActivities struct {
 
 	// Database connections
 	ClickHouse clickhouse.Conn
+	PostgreSQL *sql.DB
+
+	// Archive stores raw crawled HTML; defaults to ClickHouseBackend when nil
+	Archive archive.Backend
+
+	// Batcher, when set, is where storeParsedJob enqueues jobs-table rows
+	// instead of executing a single-row INSERT directly — see
+	// chbatch.ClickHouseBatcher's doc comment for why batching matters at
+	// scale. Must already own a TableConfig{Table: JobsTable, InsertQuery:
+	// JobsInsertQuery} and be running (Run called). A nil Batcher falls
+	// back to storeParsedJob's original one-Exec-per-job behavior.
+	Batcher *chbatch.ClickHouseBatcher
+
+	// CrawlAcquirer is where CrawlJobBatch enqueues individual URLs instead
+	// of crawling them inline, and what RunCrawlAcquirerLoop claims from —
+	// see its doc comment for why that decouples "which worker runs this
+	// crawl" from Temporal's task queue. Required for both; a nil value is
+	// only valid for activities that don't touch crawl_jobs at all.
+	CrawlAcquirer *CrawlAcquirer
+
+	// EventBus fans crawl lifecycle events out via PublishCrawlEvent so
+	// downstream consumers don't have to poll ClickHouse or wait on
+	// workflow completion. Nil makes PublishCrawlEvent a no-op, the same
+	// tolerance ProgressSink gets in DiscoveryActivities.
+	EventBus eventbus.Publisher
+
+	// ProgressSink receives CrawlProgress events reported alongside each
+	// activity heartbeat; nil (the default) means they only go to
+	// activity.RecordHeartbeat, same tolerance DiscoveryActivities.ProgressSink
+	// gets.
+	ProgressSink CrawlProgressSink
+
+	// Discoverer drives real, selector-based job-URL discovery for
+	// DiscoverJobURLs (see crawler.Discoverer). A nil Discoverer falls
+	// back to returning seedURLs unchanged, the same degraded-but-running
+	// tolerance every other optional dependency on this struct gets.
+	Discoverer *crawler.Discoverer
+
+	osintOnce   sync.Once
+	osintClient *osintClient
+}
+
+// osint returns a's own osintClient, building it on first use so every
+// OSINT-service call ScoreJobActivity makes goes through one bounded worker
+// pool — a separate instance from DiscoveryActivities.osint's, since the two
+// activity structs are constructed independently in cmd/worker, but built
+// with the same osintMaxConcurrency bound.
+func (a *CrawlActivities) osint() *osintClient {
+	a.osintOnce.Do(func() {
+		a.osintClient = newOSINTClient(a.HTTPClient, a.OSINTUrl)
+	})
+	return a.osintClient
+}
+
+// platformRequirements maps a platform to the extra capability tags a crawl
+// worker needs to service it, layered on top of {"platform": platform}.
+// Platforms absent here need no capability beyond a plain HTTP fetch.
+var platformRequirements = map[string]string{
+	"linkedin": "browser",
+	"indeed":   "browser",
 }
 
 // JobData represents a crawled job
@@ -41,84 +157,176 @@ type JobData struct {
 	URL         string
 	Platform    string
 	HTML        string
+	RawHTMLURI  string
 }
 
-// DiscoverJobURLs discovers job listing URLs from a platform
-func (a *CrawlActivities) DiscoverJobURLs(_ context.Context, platform string, seedURLs []string) ([]string, error) {
+// DiscoverJobURLs discovers job-detail URLs for platform by crawling each
+// of seedURLs (a platform's search/listing pages) with a.Discoverer,
+// following pagination and extracting detail links per
+// crawler.PlatformSelectors[platform]. A platform absent from
+// PlatformSelectors, or a nil a.Discoverer (discovery not configured), logs
+// a warning and passes seedURLs through unchanged rather than failing the
+// whole activity.
+func (a *CrawlActivities) DiscoverJobURLs(ctx context.Context, platform string, seedURLs []string) ([]string, error) {
 	log.Printf("Discovering URLs for platform: %s", platform)
 
-	// TODO: Implement actual URL discovery logic
-	// This is a placeholder that returns the seed URLs
-	// In production, this would:
-	// 1. Fetch the search page
-	// 2. Parse pagination
-	// 3. Extract individual job URLs
-	// 4. Return list of job detail page URLs
+	if a.Discoverer == nil {
+		log.Printf("Warning: no Discoverer configured, passing through %d seed URLs for platform %s", len(seedURLs), platform)
+		return seedURLs, nil
+	}
 
-	discoveredURLs := make([]string, 0)
+	sel, ok := crawler.PlatformSelectors[platform]
+	if !ok {
+		log.Printf("Warning: no Selector configured for platform %s, passing through %d seed URLs", platform, len(seedURLs))
+		return seedURLs, nil
+	}
 
-	// Placeholder: simulate discovering 50 job URLs per seed URL
+	// A failed seedURL doesn't abort the rest: losing urlC shouldn't throw
+	// away urlA/urlB's already-discovered URLs or force this (retried-on-
+	// failure) Temporal activity to recrawl every seed from scratch.
+	var discoveredURLs []string
+	var failed int
 	for _, seedURL := range seedURLs {
-		for i := 1; i <= 50; i++ {
-			jobURL := fmt.Sprintf("%s&job=%d", seedURL, i)
-			discoveredURLs = append(discoveredURLs, jobURL)
+		urls, err := a.Discoverer.DiscoverURLs(ctx, seedURL, sel)
+		if err != nil {
+			log.Printf("Warning: failed to discover URLs from %s (platform %s): %v", seedURL, platform, err)
+			failed++
+			continue
 		}
+		discoveredURLs = append(discoveredURLs, urls...)
+	}
+
+	if failed == len(seedURLs) && failed > 0 {
+		return nil, fmt.Errorf("discovering URLs for platform %s: all %d seed URLs failed", platform, failed)
 	}
 
-	log.Printf("Discovered %d URLs for platform %s", len(discoveredURLs), platform)
+	log.Printf("Discovered %d URLs for platform %s (%d/%d seed URLs failed)", len(discoveredURLs), platform, failed, len(seedURLs))
 	return discoveredURLs, nil
 }
 
-// CrawlJobBatch crawls a batch of job URLs
+// CrawlJobBatch enqueues a batch of job URLs into the crawl_jobs table
+// instead of crawling them itself, tagged by platform (plus any capability
+// platformRequirements adds) so only a worker whose own tags satisfy them
+// ever claims one via CrawlAcquirer.AcquireJob. This replaces the old
+// behavior of crawling every URL inline within this one Temporal activity,
+// which implicitly assumed whichever worker Temporal happened to dispatch
+// the activity to could service any platform — the returned counts are now
+// "enqueued" rather than "crawled", since the actual crawl happens
+// out-of-band on whatever worker next claims each row.
 func (a *CrawlActivities) CrawlJobBatch(ctx context.Context, urls []string, platform string) (map[string]interface{}, error) {
-	log.Printf("Crawling batch of %d URLs for platform: %s", len(urls), platform)
+	log.Printf("Enqueuing batch of %d URLs for platform: %s", len(urls), platform)
+
+	if a.CrawlAcquirer == nil {
+		return nil, fmt.Errorf("crawl batch for %s: no CrawlAcquirer configured", platform)
+	}
 
-	successful := 0
+	tags := map[string]string{"platform": platform}
+	if requires, ok := platformRequirements[platform]; ok {
+		tags["requires"] = requires
+	}
+
+	enqueued := 0
 	failed := 0
 
 	for _, url := range urls {
-		// Check if context is canceled
 		select {
 		case <-ctx.Done():
 			return map[string]interface{}{
-				"Successful": successful,
+				"Successful": enqueued,
 				"Failed":     failed,
 			}, ctx.Err()
 		default:
 		}
 
-		// Crawl individual job
-		jobData, err := a.crawlSingleJob(ctx, url, platform)
-		if err != nil {
-			log.Printf("Failed to crawl %s: %v", url, err)
+		if err := EnqueueJob(ctx, a.PostgreSQL, url, platform, tags, 0); err != nil {
+			log.Printf("Failed to enqueue %s: %v", url, err)
 			failed++
 			continue
 		}
+		enqueued++
+	}
+
+	log.Printf("Batch enqueue complete: %d enqueued, %d failed", enqueued, failed)
 
-		// Store raw HTML and job data
-		err = a.storeJobData(ctx, jobData)
+	return map[string]interface{}{
+		"Successful": enqueued,
+		"Failed":     failed,
+	}, nil
+}
+
+// RunCrawlAcquirerLoop runs forever (until ctx is done), claiming crawl_jobs
+// rows whose required tags are satisfied by workerTags and running the same
+// fetch-then-archive steps CrawlJobBatch used to run inline. It's meant to
+// run as a background goroutine in cmd/worker, independent of and alongside
+// the Temporal activity worker loop — CrawlJobBatch only gets work into the
+// queue, this is what actually claims and executes it.
+func (a *CrawlActivities) RunCrawlAcquirerLoop(ctx context.Context, workerTags map[string]string) {
+	log.Printf("crawl_acquirer: loop started with tags %v", workerTags)
+	for {
+		job, err := a.CrawlAcquirer.AcquireJob(ctx, workerTags)
 		if err != nil {
-			log.Printf("Failed to store job data for %s: %v", url, err)
-			failed++
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("crawl_acquirer: acquire failed: %v", err)
 			continue
 		}
 
-		successful++
+		a.runAcquiredJob(ctx, job)
+	}
+}
+
+// runAcquiredJob crawls and archives one claimed job, heartbeating its
+// lease every DefaultCrawlLeaseDuration/2 while the crawl is in flight so a
+// slow fetch doesn't lose the claim to another worker mid-crawl.
+func (a *CrawlActivities) runAcquiredJob(ctx context.Context, job *CrawlJob) {
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+	go func() {
+		ticker := time.NewTicker(DefaultCrawlLeaseDuration / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				if err := a.CrawlAcquirer.HeartbeatJob(heartbeatCtx, job.ID, DefaultCrawlLeaseDuration); err != nil {
+					log.Printf("crawl_acquirer: heartbeat for job %d: %v", job.ID, err)
+				}
+			}
+		}
+	}()
 
-		// Rate limiting - sleep between requests
-		time.Sleep(1 * time.Second)
+	jobData, err := a.crawlSingleJob(ctx, job.URL, job.Platform)
+	if err != nil {
+		log.Printf("crawl_acquirer: failed to crawl %s: %v", job.URL, err)
+		if failErr := a.CrawlAcquirer.FailJob(ctx, job.ID, err); failErr != nil {
+			log.Printf("crawl_acquirer: marking job %d failed: %v", job.ID, failErr)
+		}
+		return
 	}
 
-	log.Printf("Batch complete: %d successful, %d failed", successful, failed)
+	if err := a.storeJobData(ctx, jobData); err != nil {
+		log.Printf("crawl_acquirer: failed to store %s: %v", job.URL, err)
+		if failErr := a.CrawlAcquirer.FailJob(ctx, job.ID, err); failErr != nil {
+			log.Printf("crawl_acquirer: marking job %d failed: %v", job.ID, failErr)
+		}
+		return
+	}
 
-	return map[string]interface{}{
-		"Successful": successful,
-		"Failed":     failed,
-	}, nil
+	if err := a.CrawlAcquirer.CompleteJob(ctx, job.ID); err != nil {
+		log.Printf("crawl_acquirer: marking job %d done: %v", job.ID, err)
+	}
 }
 
-// crawlSingleJob crawls a single job URL using the Python Crawler service
+// crawlSingleJob crawls a single job URL using the Python Crawler service,
+// through httpx.Client so a 429/5xx/network failure is retried with
+// backoff (and the CrawlerURL host's circuit breaker tracked) instead of
+// failing the activity on the first hiccup.
 func (a *CrawlActivities) crawlSingleJob(ctx context.Context, url, platform string) (*JobData, error) {
+	startedAt := time.Now()
+	a.reportCrawlProgress(ctx, startedAt, CrawlProgress{Stage: "fetch", URL: url})
+
 	// Call the Python crawler service - it expects a JSON array of URLs
 	payload := []string{url}
 
@@ -127,25 +335,13 @@ func (a *CrawlActivities) crawlSingleJob(ctx context.Context, url, platform stri
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", a.CrawlerURL+"/crawl-batch", bytes.NewBuffer(body))
+	respBody, statusCode, err := httpx.New(a.HTTPClient).Post(ctx, a.CrawlerURL+"/crawl-batch", body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, httpx.WrapErr("failed to call crawler service", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call crawler service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("crawler service returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("crawler service returned status %d: %s", statusCode, string(respBody))
 	}
 
 	// Response is an array of CrawlResponse objects
@@ -156,7 +352,7 @@ func (a *CrawlActivities) crawlSingleJob(ctx context.Context, url, platform stri
 		Error   string `json:"error"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+	if err := json.Unmarshal(respBody, &results); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -168,6 +364,8 @@ func (a *CrawlActivities) crawlSingleJob(ctx context.Context, url, platform stri
 		return nil, fmt.Errorf("crawl failed: %s", errMsg)
 	}
 
+	a.reportCrawlProgress(ctx, startedAt, CrawlProgress{Stage: "fetch", URL: url, BytesRead: int64(len(results[0].HTML))})
+
 	// Generate a unique ID based on URL using SHA256
 	hash := sha256.Sum256([]byte(url))
 	id := hex.EncodeToString(hash[:])
@@ -183,32 +381,60 @@ func (a *CrawlActivities) crawlSingleJob(ctx context.Context, url, platform stri
 	return jobData, nil
 }
 
-// storeJobData stores crawled job data in ClickHouse
-func (a *CrawlActivities) storeJobData(ctx context.Context, job *JobData) error {
-	if a.ClickHouse == nil {
-		log.Println("Warning: ClickHouse connection not available, skipping storage")
+// storeJobData archives the raw HTML via a.Archive, keeping only the
+// returned URI on the job rather than the full payload.
+func (a *CrawlActivities) storeJobData(_ context.Context, job *JobData) error {
+	backend, err := a.archiveBackend()
+	if err != nil {
+		log.Println("Warning: no archive backend available, skipping storage")
 		return nil
 	}
 
-	// Store raw HTML first
-	err := a.ClickHouse.Exec(ctx, `
-		INSERT INTO jobs_raw_html (id, url, html, status)
-		VALUES (?, ?, ?, ?)
-	`, job.ID, job.URL, job.HTML, "success")
-
+	meta := map[string]string{"url": job.URL, "source": job.Platform}
+	uri, err := backend.Store(job.ID, []byte(job.HTML), meta)
 	if err != nil {
-		log.Printf("Failed to store raw HTML: %v", err)
-		return fmt.Errorf("failed to store raw HTML: %w", err)
+		log.Printf("Failed to archive raw HTML: %v", err)
+		return fmt.Errorf("failed to archive raw HTML: %w", err)
 	}
+	job.RawHTMLURI = uri
 
-	log.Printf("✅ Stored job data: %s - %s", job.ID, job.Title)
+	log.Printf("✅ Stored job data: %s - %s (%s)", job.ID, job.Title, uri)
 	return nil
 }
 
-// ParseJobActivity parses raw HTML into structured job data using Parser service
-func (a *CrawlActivities) ParseJobActivity(ctx context.Context, jobID string, html string) (map[string]interface{}, error) {
+// archiveBackend returns the configured Archive, falling back to a
+// ClickHouseBackend over a.ClickHouse for backward compatibility with
+// callers that haven't set Archive explicitly.
+func (a *CrawlActivities) archiveBackend() (archive.Backend, error) {
+	if a.Archive != nil {
+		return a.Archive, nil
+	}
+	if a.ClickHouse == nil {
+		return nil, fmt.Errorf("no archive backend configured")
+	}
+	return archive.NewClickHouseBackend(a.ClickHouse), nil
+}
+
+// ParseJobActivity parses raw HTML into structured job data. It tries the
+// local parsers.Registry first (see internal/parsers) and only calls out to
+// the remote Parser service when no local parser can extract a listing —
+// e.g. a page with no JSON-LD JobPosting — cutting latency and removing the
+// hard 422 failure mode for sites the registry already covers.
+func (a *CrawlActivities) ParseJobActivity(ctx context.Context, jobID string, url string, html string) (map[string]interface{}, error) {
 	log.Printf("Parsing job: %s", jobID)
 
+	startedAt := time.Now()
+	a.reportCrawlProgress(ctx, startedAt, CrawlProgress{Stage: "parse", URL: url, BytesRead: int64(len(html))})
+
+	if result, ok := a.parseLocally(url, html); ok {
+		outcome, err := a.storeParsedJob(ctx, jobID, result)
+		if err != nil {
+			log.Printf("Warning: Failed to store parsed job: %v", err)
+		}
+		result["store_outcome"] = string(outcome)
+		return result, nil
+	}
+
 	// Call the Parser service
 	payload := map[string]string{
 		"html": html,
@@ -219,58 +445,84 @@ func (a *CrawlActivities) ParseJobActivity(ctx context.Context, jobID string, ht
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", a.ParserURL+"/parse", bytes.NewBuffer(body))
+	respBody, statusCode, err := httpx.New(a.HTTPClient).Post(ctx, a.ParserURL+"/parse", body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, httpx.WrapErr("failed to call parser service", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call parser service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close parser response body: %v", err)
-		}
-	}()
 
-	if resp.StatusCode == 422 {
+	if statusCode == 422 {
 		return nil, fmt.Errorf("no structured data found in HTML (requires JSON-LD JobPosting schema)")
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("parser service returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("parser service returned status %d: %s", statusCode, string(respBody))
 	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(respBody, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// Store parsed job in ClickHouse
-	if err := a.storeParsedJob(ctx, jobID, result); err != nil {
+	outcome, err := a.storeParsedJob(ctx, jobID, result)
+	if err != nil {
 		log.Printf("Warning: Failed to store parsed job: %v", err)
 	}
+	result["store_outcome"] = string(outcome)
 
 	return result, nil
 }
 
-// storeParsedJob stores the parsed job data in the main jobs table
-func (a *CrawlActivities) storeParsedJob(ctx context.Context, jobID string, data map[string]interface{}) error {
+// parseLocally tries the registered parsers.Parser for url before
+// ParseJobActivity falls back to the remote Parser service. ok is false
+// when nothing in the registry claims url, or the resolved parser fails to
+// extract a listing (e.g. a JobPosting with no JSON-LD present) — either
+// way the caller should fall back, not fail.
+func (a *CrawlActivities) parseLocally(url, html string) (result map[string]interface{}, ok bool) {
+	parser, err := parsers.Resolve(url)
+	if err != nil {
+		return nil, false
+	}
+
+	listing, err := parser.Parse(html, url)
+	if err != nil {
+		log.Printf("Warning: local parser failed for %s, falling back to Parser service: %v", url, err)
+		return nil, false
+	}
+
+	return listing.ToMap(url), true
+}
+
+// storeParsedJob stores the parsed job data in the main jobs table, a
+// ReplacingMergeTree(version) ordered by job_hash (see EnsureJobsTable): a
+// re-crawl whose content_hash hasn't changed since the last stored version
+// is skipped outright, and a genuine change is inserted at the prior
+// version + 1 so ReplacingMergeTree collapses to the newest row for that
+// job_hash. Returns which of the three happened (see ParsedJobOutcome).
+func (a *CrawlActivities) storeParsedJob(ctx context.Context, jobID string, data map[string]interface{}) (ParsedJobOutcome, error) {
 	if a.ClickHouse == nil {
 		log.Println("Warning: ClickHouse connection not available, skipping storage")
-		return nil
+		return JobStoreUnavailable, nil
 	}
 
-	// Generate job hash for deduplication
-	hashInput := fmt.Sprintf("%v%v%v",
-		data["source_url"],
-		data["title"],
-		data["company"])
-	hash := sha256.Sum256([]byte(hashInput))
-	jobHash := hex.EncodeToString(hash[:])
+	jobHash := jobHashOf(data)
+	contentHash := contentHashOf(data)
+
+	// nextJobVersion's SELECT-then-insert isn't atomic: two ParseJobActivity
+	// executions for the same job_hash running close together (a Temporal
+	// retry, or two re-crawls within the batcher's flush window) can both
+	// read the same existingVersion and insert same-version rows, which
+	// ReplacingMergeTree can't order — an accepted limitation of this
+	// version-by-read approach, same as EnsureJobsTable's FINAL-on-read cost.
+	version, outcome, err := a.nextJobVersion(ctx, jobHash, contentHash)
+	if err != nil {
+		return "", fmt.Errorf("checking existing job %s: %w", jobHash, err)
+	}
+	if outcome == JobSkippedDup {
+		jobsStoreOutcome.WithLabelValues(string(outcome)).Inc()
+		log.Printf("Job %s unchanged since last crawl (hash %s), skipping", jobID, jobHash[:8])
+		return outcome, nil
+	}
 
 	// Convert timestamps
 	var postedAt, updatedAt time.Time
@@ -293,33 +545,113 @@ func (a *CrawlActivities) storeParsedJob(ctx context.Context, jobID string, data
 	sourceURL := getStringField(data, "source_url")
 	sourcePlatform := getStringField(data, "source_platform")
 	jobType := getStringField(data, "job_type", "full-time")
+	salaryMin := getFloatField(data, "salary_min")
+	salaryMax := getFloatField(data, "salary_max")
+	currency := getStringField(data, "currency")
+	var remote uint8
+	if r, ok := data["remote"].(bool); ok && r {
+		remote = 1
+	}
 
-	// Insert into jobs table
-	err := a.ClickHouse.Exec(ctx, `
-		INSERT INTO jobs (
-			id, job_hash, title, company, description, location, remote,
-			salary_min, salary_max, currency, job_type, experience_level,
-			real_score, source_url, source_platform, tags,
-			posted_at, updated_at, version
-		) VALUES (
+	args := []interface{}{
+		jobID, jobHash, title, company, description, location, remote,
+		salaryMin, salaryMax, currency, jobType, nil,
+		85, sourceURL, sourcePlatform, []string{},
+		postedAt, updatedAt, version, contentHash,
+	}
+
+	if a.Batcher != nil {
+		if err := a.Batcher.Enqueue(ctx, chbatch.Row{Table: JobsTable, Args: args}); err != nil {
+			return "", fmt.Errorf("enqueuing parsed job %s: %w", jobID, err)
+		}
+		jobsStoreOutcome.WithLabelValues(string(outcome)).Inc()
+		log.Printf("✅ Enqueued parsed job (%s, v%d): %s - %s at %s", outcome, version, jobID, title, company)
+		return outcome, nil
+	}
+
+	if err := a.ClickHouse.Exec(ctx, JobsInsertQuery+`
+		VALUES (
 			?, ?, ?, ?, ?, ?, ?,
 			?, ?, ?, ?, ?,
 			?, ?, ?, ?,
-			?, ?, ?
+			?, ?, ?, ?
 		)
-	`,
-		jobID, jobHash, title, company, description, location, 0,
-		nil, nil, nil, jobType, nil,
-		85, sourceURL, sourcePlatform, []string{},
-		postedAt, updatedAt, 1,
-	)
+	`, args...); err != nil {
+		return "", fmt.Errorf("failed to insert job: %w", err)
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to insert job: %w", err)
+	jobsStoreOutcome.WithLabelValues(string(outcome)).Inc()
+	log.Printf("✅ Stored parsed job (%s, v%d): %s - %s at %s", outcome, version, jobID, title, company)
+	return outcome, nil
+}
+
+// jobHashOf derives the same stable job_hash storeParsedJob dedups on and
+// ScoreJobActivity keys job_scores rows by, from the three fields that
+// identify a listing (its source URL, title, and company) regardless of how
+// many times its content has since changed.
+func jobHashOf(data map[string]interface{}) string {
+	hashInput := fmt.Sprintf("%v%v%v",
+		data["source_url"],
+		data["title"],
+		data["company"])
+	hash := sha256.Sum256([]byte(hashInput))
+	return hex.EncodeToString(hash[:])
+}
+
+// nextJobVersion looks up jobHash's current version/content_hash in the
+// jobs table (FINAL forces ReplacingMergeTree's dedup collapse immediately,
+// at the cost of extra read work, rather than waiting on a background
+// merge) and decides what storeParsedJob should do with it next.
+func (a *CrawlActivities) nextJobVersion(ctx context.Context, jobHash, contentHash string) (version uint64, outcome ParsedJobOutcome, err error) {
+	var existingVersion uint64
+	var existingContentHash string
+	row := a.ClickHouse.QueryRow(ctx, `SELECT version, content_hash FROM jobs FINAL WHERE job_hash = ? LIMIT 1`, jobHash)
+
+	switch err := row.Scan(&existingVersion, &existingContentHash); {
+	case errors.Is(err, sql.ErrNoRows):
+		return 1, JobInserted, nil
+	case err != nil:
+		return 0, "", err
+	case existingContentHash == contentHash:
+		return existingVersion, JobSkippedDup, nil
+	default:
+		return existingVersion + 1, JobUpdated, nil
 	}
+}
 
-	log.Printf("✅ Stored parsed job: %s - %s at %s", jobID, title, company)
-	return nil
+// contentHashOf sha256-hashes a stable, field-ordered encoding of data's
+// listing content — everything storeParsedJob derives from the crawled
+// page itself, excluding identifiers like source_url that don't reflect
+// whether the listing's actual content changed — so storeParsedJob can
+// tell an unchanged re-crawl from a genuine edit.
+func contentHashOf(data map[string]interface{}) string {
+	fields := []string{
+		getStringField(data, "title"),
+		getStringField(data, "company"),
+		getStringField(data, "description"),
+		getStringField(data, "location"),
+		getStringField(data, "source_platform"),
+		getStringField(data, "job_type"),
+		getStringField(data, "posted_at"),
+		getStringField(data, "updated_at"),
+		fmt.Sprintf("%v", data["remote"]),
+		formatFloatField(data, "salary_min"),
+		formatFloatField(data, "salary_max"),
+		getStringField(data, "currency"),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatFloatField renders a nullable numeric field (see getFloatField) as
+// a string suitable for contentHashOf, with a nil value distinguishable
+// from a present zero.
+func formatFloatField(data map[string]interface{}, key string) string {
+	v := getFloatField(data, key)
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
 }
 
 // Helper function to safely get string fields from map
@@ -333,15 +665,246 @@ func getStringField(data map[string]interface{}, key string, defaultVal ...strin
 	return ""
 }
 
-// ScoreJobActivity calculates authenticity score for a job
-func (a *CrawlActivities) ScoreJobActivity(_ context.Context, jobData map[string]interface{}) (int, error) {
+// getFloatField safely reads a nullable numeric field from a parsed-job
+// map. Local parsers (see internal/parsers) store it as *float64; the
+// Python Parser service's JSON response decodes numbers as plain float64.
+// Either shape, or a missing/null value, is handled; only a present value
+// of one of those two types returns non-nil.
+func getFloatField(data map[string]interface{}, key string) *float64 {
+	switch v := data[key].(type) {
+	case *float64:
+		return v
+	case float64:
+		return &v
+	default:
+		return nil
+	}
+}
+
+// ScoreJobActivity computes a 0-100 authenticity score for a parsed job from
+// a weighted combination of independent signals: JSON-LD JobPosting
+// presence, the source domain's TLD reputation, description buzzword-to-
+// requirement ratio, salary specificity, description length percentile
+// against the rest of the corpus, how many distinct companies have posted
+// the same title in the last 30 days, and OSINT hits on the company. html
+// is the job's raw crawled page (see JobData.HTML) and jobData is
+// ParseJobActivity's result map. Every sub-score is persisted alongside the
+// final score in job_scores (see storeJobScore) for later ML training; a
+// ClickHouse- or OSINT-backed feature that can't be computed right now
+// (connection unavailable, service error) falls back to a neutral or
+// best-case score rather than failing the whole activity, the same
+// degraded-but-running tolerance storeParsedJob gets from a nil ClickHouse.
+//
+// Registered with the worker (see cmd/worker/main.go) but, like
+// ExtractHiringManagerActivity, not yet called from any workflow — wiring a
+// call into ParseJobActivity's workflow and threading its result back into
+// jobs.real_score (still hardcoded in storeParsedJob) is follow-up work.
+func (a *CrawlActivities) ScoreJobActivity(ctx context.Context, html string, jobData map[string]interface{}) (int, error) {
 	log.Printf("Scoring job: %v", jobData["title"])
 
-	// TODO: Call the RealScore service API
-	// This should analyze the job and return a score 0-100
+	title := getStringField(jobData, "title")
+	company := getStringField(jobData, "company")
+
+	features := scoring.Features{
+		JSONLD:            scoring.JSONLDScore(parsers.HasValidJobPosting(html)),
+		DomainReputation:  scoring.DomainReputationScore(getStringField(jobData, "source_platform")),
+		BuzzwordRatio:     scoring.BuzzwordRatioScore(getStringField(jobData, "description")),
+		SalarySpecificity: scoring.SalarySpecificityScore(getFloatField(jobData, "salary_min"), getFloatField(jobData, "salary_max")),
+	}
+
+	// These three each make their own round trip (two ClickHouse queries, one
+	// retrying OSINT call) with no dependency on one another, so they run
+	// concurrently rather than paying their full latency one after another —
+	// the same fan-out-with-a-WaitGroup shape mergeSubdomainResults uses for
+	// DiscoveryActivities' independent subdomain sources.
+	description := getStringField(jobData, "description")
+	var wg sync.WaitGroup
+	var descLenErr, dupTitleErr, osintErr error
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		features.DescriptionLength, descLenErr = a.descriptionLengthPercentile(ctx, description)
+	}()
+	go func() {
+		defer wg.Done()
+		features.DuplicateTitle, dupTitleErr = a.duplicateTitleScore(ctx, title)
+	}()
+	go func() {
+		defer wg.Done()
+		features.OSINTHits, osintErr = a.osintHitsScore(ctx, company)
+	}()
+	wg.Wait()
+
+	if descLenErr != nil {
+		log.Printf("Warning: description length percentile unavailable, scoring neutral: %v", descLenErr)
+		features.DescriptionLength = 50
+	}
+	if dupTitleErr != nil {
+		log.Printf("Warning: duplicate title check unavailable, scoring best-case: %v", dupTitleErr)
+		features.DuplicateTitle = 100
+	}
+	if osintErr != nil {
+		log.Printf("Warning: OSINT hits check unavailable, scoring neutral: %v", osintErr)
+		features.OSINTHits = 50
+	}
+
+	score := scoring.Combine(features, scoring.LoadWeights())
+
+	if err := a.storeJobScore(ctx, jobHashOf(jobData), score, features); err != nil {
+		log.Printf("Warning: failed to store job score: %v", err)
+	}
+
+	return score, nil
+}
+
+// dupTitleSuspectCompanies is the distinct-company count at which
+// duplicateTitleScore bottoms out at 0 — beyond this, a title reads as a
+// copy-pasted listing blasted across many companies rather than a
+// genuinely common role name.
+const dupTitleSuspectCompanies = 5
+
+// duplicateTitleScore scores down the more distinct companies have posted a
+// job with the exact same title in the last 30 days. A nil ClickHouse or an
+// empty title returns a best-case 100 rather than treating "can't check" as
+// "suspicious".
+//
+// This scans the whole jobs table FINAL per call — there's no index on
+// title (jobs is ORDER BY job_hash, for storeParsedJob's dedup lookups) — an
+// accepted cost for now, the same tradeoff nextJobVersion's FINAL-on-read
+// already makes, since the count needs to be exact for the suspicion
+// judgment rather than approximate like descriptionLengthPercentile's.
+func (a *CrawlActivities) duplicateTitleScore(ctx context.Context, title string) (float64, error) {
+	if a.ClickHouse == nil || title == "" {
+		return 100, nil
+	}
+
+	var distinctCompanies uint64
+	row := a.ClickHouse.QueryRow(ctx, `
+		SELECT countDistinct(company) FROM jobs FINAL
+		WHERE title = ? AND posted_at >= now() - INTERVAL 30 DAY
+	`, title)
+	if err := row.Scan(&distinctCompanies); err != nil {
+		return 0, fmt.Errorf("querying duplicate title count: %w", err)
+	}
+
+	if distinctCompanies <= 1 {
+		return 100, nil
+	}
+	if distinctCompanies >= dupTitleSuspectCompanies {
+		return 0, nil
+	}
+	return 100 * float64(dupTitleSuspectCompanies-distinctCompanies) / float64(dupTitleSuspectCompanies-1), nil
+}
+
+// descriptionLengthPercentile returns what percentage of the jobs table's
+// rows have a description no longer than description — a short, thin
+// description scores low, a thorough one scores high. A nil ClickHouse or
+// an empty corpus returns a neutral 50.
+//
+// Unlike duplicateTitleScore, this deliberately skips FINAL: it's a
+// statistical percentile over the whole corpus, not an exact count feeding
+// a suspicion judgment, so a few not-yet-merged stale ReplacingMergeTree
+// duplicates in the tail don't meaningfully shift the result, and every
+// scored job would otherwise force a FINAL merge across the entire table.
+func (a *CrawlActivities) descriptionLengthPercentile(ctx context.Context, description string) (float64, error) {
+	if a.ClickHouse == nil {
+		return 50, nil
+	}
+
+	var total, atOrBelow uint64
+	row := a.ClickHouse.QueryRow(ctx, `
+		SELECT count(), countIf(length(description) <= ?) FROM jobs
+	`, len(description))
+	if err := row.Scan(&total, &atOrBelow); err != nil {
+		return 0, fmt.Errorf("querying description length percentile: %w", err)
+	}
+	if total == 0 {
+		return 50, nil
+	}
+	return 100 * float64(atOrBelow) / float64(total), nil
+}
+
+// osintHitsSaturation is the OSINT hit count at which osintHitsScore maxes
+// out at 100 — beyond this many corroborating hits, more hits don't make a
+// company meaningfully more verified.
+const osintHitsSaturation = 10
+
+// osintHitsScore asks the OSINT service how many corroborating hits it
+// finds for company (news mentions, GitHub presence, and the like) and
+// scores up to osintHitsSaturation hits onto a 0-100 scale. An empty
+// company returns a neutral 50 without calling out.
+func (a *CrawlActivities) osintHitsScore(ctx context.Context, company string) (float64, error) {
+	if company == "" {
+		return 50, nil
+	}
+
+	var result struct {
+		Hits int `json:"hits"`
+	}
+	if err := a.osint().post(ctx, "/search/company", map[string]string{"company": company}, &result); err != nil {
+		return 0, err
+	}
+
+	if result.Hits <= 0 {
+		return 0, nil
+	}
+	if result.Hits >= osintHitsSaturation {
+		return 100, nil
+	}
+	return 100 * float64(result.Hits) / float64(osintHitsSaturation), nil
+}
+
+// storeJobScore inserts score and every one of features' sub-scores into
+// job_scores, versioned like storeParsedJob's jobs-table rows so a re-score
+// of the same job_hash collapses to the newest row under FINAL rather than
+// accumulating stale ones.
+func (a *CrawlActivities) storeJobScore(ctx context.Context, jobHash string, score int, features scoring.Features) error {
+	if a.ClickHouse == nil {
+		log.Println("Warning: ClickHouse connection not available, skipping job score storage")
+		return nil
+	}
+
+	version, err := a.nextJobScoreVersion(ctx, jobHash)
+	if err != nil {
+		return fmt.Errorf("checking existing job score %s: %w", jobHash, err)
+	}
+
+	err = a.ClickHouse.Exec(ctx, `
+		INSERT INTO job_scores (
+			job_hash, score, jsonld_score, domain_reputation_score,
+			buzzword_ratio_score, salary_specificity_score,
+			description_length_score, duplicate_title_score, osint_hits_score,
+			scored_at, version
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, jobHash, score, features.JSONLD, features.DomainReputation,
+		features.BuzzwordRatio, features.SalarySpecificity,
+		features.DescriptionLength, features.DuplicateTitle, features.OSINTHits,
+		time.Now(), version)
+	if err != nil {
+		return fmt.Errorf("failed to insert job score: %w", err)
+	}
+
+	log.Printf("✅ Stored job score (v%d): %s -> %d", version, jobHash[:8], score)
+	return nil
+}
 
-	// Placeholder: return a random score between 70-95
-	return 85, nil
+// nextJobScoreVersion looks up jobHash's current version in job_scores
+// (FINAL forces ReplacingMergeTree's dedup collapse immediately, the same
+// tradeoff nextJobVersion makes for the jobs table) so storeJobScore always
+// inserts strictly ahead of whatever's already there.
+func (a *CrawlActivities) nextJobScoreVersion(ctx context.Context, jobHash string) (uint64, error) {
+	var existingVersion uint64
+	row := a.ClickHouse.QueryRow(ctx, `SELECT version FROM job_scores FINAL WHERE job_hash = ? LIMIT 1`, jobHash)
+
+	switch err := row.Scan(&existingVersion); {
+	case errors.Is(err, sql.ErrNoRows):
+		return 1, nil
+	case err != nil:
+		return 0, err
+	default:
+		return existingVersion + 1, nil
+	}
 }
 
 // ExtractHiringManagerActivity extracts hiring manager info
@@ -356,3 +919,24 @@ func (a *CrawlActivities) ExtractHiringManagerActivity(_ context.Context, jobDat
 		"email": "john.doe@example.com",
 	}, nil
 }
+
+// PublishCrawlEvent fans one crawl lifecycle event (e.g. "crawl.started",
+// "crawl.job_stored") out through a.EventBus, stamping the envelope's
+// EventID and OccurredAt here since those are inherently non-deterministic
+// and can't be set inside the calling workflow. envelope's other fields
+// (WorkflowID, RunID, URL, CompanyID, Payload) are the caller's to fill in.
+// A nil EventBus makes this a no-op so a crawl that isn't configured with
+// one still runs exactly as before this activity existed.
+func (a *CrawlActivities) PublishCrawlEvent(ctx context.Context, topic string, envelope eventbus.Envelope) error {
+	if a.EventBus == nil {
+		return nil
+	}
+
+	envelope.EventID = uuid.New().String()
+	envelope.OccurredAt = time.Now()
+
+	if err := a.EventBus.Publish(ctx, topic, envelope); err != nil {
+		return fmt.Errorf("publishing %s event: %w", topic, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/httpx"
+)
+
+// hrefPattern matches an anchor tag's href attribute value, case
+// insensitively, stopping at the closing quote so it doesn't swallow the
+// tag's other attributes.
+var hrefPattern = regexp.MustCompile(`(?i)<a\s+[^>]*href\s*=\s*["']([^"'#]+)[^"']*["']`)
+
+// FetchAndExtractLinks fetches pageURL via the crawler service and returns
+// every absolute http(s) link found in the page's anchor tags, with
+// relative links resolved against pageURL. CareerSiteCrawlWorkflow calls
+// this once per frontier URL per BFS level.
+func (a *CrawlActivities) FetchAndExtractLinks(ctx context.Context, pageURL string) ([]string, error) {
+	jobData, err := a.crawlSingleJob(ctx, pageURL, "career_site_crawl")
+	if err != nil {
+		return nil, httpx.WrapErr(fmt.Sprintf("failed to fetch %s", pageURL), err)
+	}
+	return extractLinks(pageURL, jobData.HTML)
+}
+
+// extractLinks resolves every anchor href found in html against baseURL and
+// returns the deduplicated, absolute http(s) results in document order.
+func extractLinks(baseURL string, html string) ([]string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL %q: %w", baseURL, err)
+	}
+
+	seen := make(map[string]bool)
+	var links []string
+	for _, match := range hrefPattern.FindAllStringSubmatch(html, -1) {
+		ref, err := url.Parse(strings.TrimSpace(match[1]))
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+		resolved.Fragment = ""
+		link := resolved.String()
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links, nil
+}
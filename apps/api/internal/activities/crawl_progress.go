@@ -0,0 +1,41 @@
+package activities
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// CrawlProgress is the periodic checkpoint a long-running crawl or parse
+// activity emits while it's fetching or processing a page, mirroring
+// DiscoveryProgress's role for discovery activities. Stage names the step
+// ("fetch", "parse"), URL is whatever page is in flight, and BytesRead lets
+// a caller tell a merely-slow page apart from one that's stalled outright.
+type CrawlProgress struct {
+	Stage     string
+	URL       string
+	BytesRead int64
+	ElapsedMs int64
+}
+
+// CrawlProgressSink receives the same CrawlProgress events a Temporal
+// worker sees via activity.RecordHeartbeat, for callers that aren't running
+// inside a Temporal activity context (a CLI tool, a test). Set
+// CrawlActivities.ProgressSink to receive them; leave it nil otherwise.
+type CrawlProgressSink interface {
+	OnCrawlProgress(progress CrawlProgress)
+}
+
+// reportCrawlProgress records progress as a Temporal activity heartbeat —
+// so ReaperWorkflow and Temporal's own stuck-activity detection can tell a
+// crawl that's merely slow apart from one that's actually hung — and
+// forwards the same event to a.ProgressSink if one is configured.
+func (a *CrawlActivities) reportCrawlProgress(ctx context.Context, startedAt time.Time, progress CrawlProgress) {
+	progress.ElapsedMs = time.Since(startedAt).Milliseconds()
+
+	activity.RecordHeartbeat(ctx, progress)
+	if a.ProgressSink != nil {
+		a.ProgressSink.OnCrawlProgress(progress)
+	}
+}
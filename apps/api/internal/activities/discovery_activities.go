@@ -2,16 +2,13 @@
 package activities
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
 	"database/sql"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -21,6 +18,29 @@ type DiscoveryActivities struct {
 	HTTPClient *http.Client
 	OSINTUrl   string
 	PostgreSQL *sql.DB
+	// Providers configures the DiscoveryProvider registry DiscoverFromProvider
+	// dispatches to. Nil falls back to DefaultProviders(a).
+	Providers ProvidersMap
+	// ProgressSink, if set, receives the DiscoveryProgress events long-running
+	// activities also report via activity.RecordHeartbeat. Nil is fine outside
+	// a Temporal worker.
+	ProgressSink ProgressSink
+	// Comparator orders CareerPageInfo for RankCareerPages, best first. Nil
+	// falls back to a comparator built on the default CareerPageScorer.
+	Comparator func(x, y CareerPageInfo) int
+
+	osintOnce   sync.Once
+	osintClient *osintClient
+}
+
+// osint returns a's shared osintClient, building it on first use so every
+// call to the OSINT service from this DiscoveryActivities goes through the
+// same bounded worker pool.
+func (a *DiscoveryActivities) osint() *osintClient {
+	a.osintOnce.Do(func() {
+		a.osintClient = newOSINTClient(a.HTTPClient, a.OSINTUrl)
+	})
+	return a.osintClient
 }
 
 // DiscoverCompaniesFromGitHub discovers companies from GitHub
@@ -32,32 +52,6 @@ func (a *DiscoveryActivities) DiscoverCompaniesFromGitHub(ctx context.Context, q
 		"max_results": maxResults,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/discover/github", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result struct {
 		Companies []struct {
 			Name        string `json:"name"`
@@ -66,8 +60,8 @@ func (a *DiscoveryActivities) DiscoverCompaniesFromGitHub(ctx context.Context, q
 		} `json:"companies"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := a.osint().post(ctx, "/discover/github", payload, &result); err != nil {
+		return nil, err
 	}
 
 	companies := make([]CompanyInfo, len(result.Companies))
@@ -122,32 +116,6 @@ func (a *DiscoveryActivities) DiscoverCompaniesFromGoogleDorks(ctx context.Conte
 		"max_results": maxResults,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/discover/google-dork", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result struct {
 		URLs []struct {
 			URL    string `json:"url"`
@@ -155,13 +123,15 @@ func (a *DiscoveryActivities) DiscoverCompaniesFromGoogleDorks(ctx context.Conte
 		} `json:"urls"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := a.osint().post(ctx, "/discover/google-dork", payload, &result); err != nil {
+		return nil, err
 	}
 
+	startedAt := time.Now()
+
 	// Group by domain to get unique companies
 	domainMap := make(map[string]CompanyInfo)
-	for _, u := range result.URLs {
+	for i, u := range result.URLs {
 		if _, exists := domainMap[u.Domain]; !exists {
 			domainMap[u.Domain] = CompanyInfo{
 				Name:   u.Domain,
@@ -169,6 +139,14 @@ func (a *DiscoveryActivities) DiscoverCompaniesFromGoogleDorks(ctx context.Conte
 				Source: "google_dork",
 			}
 		}
+		if i%progressReportInterval == 0 {
+			a.reportProgress(ctx, startedAt, DiscoveryProgress{
+				Stage:     "google_dork",
+				Processed: i + 1,
+				Total:     len(result.URLs),
+				LastItem:  u.URL,
+			})
+		}
 	}
 
 	companies := make([]CompanyInfo, 0, len(domainMap))
@@ -194,40 +172,17 @@ func (a *DiscoveryActivities) AddCompanyManually(ctx context.Context, domain str
 	}, nil
 }
 
-// DiscoverCareerPages discovers career pages for a company domain
-func (a *DiscoveryActivities) DiscoverCareerPages(ctx context.Context, domain string, companyName string) ([]CareerPageInfo, error) {
+// DiscoverCareerPages discovers career pages for a company domain. source is
+// the CompanyInfo.Source of the company this domain belongs to (e.g.
+// "manual", "github"), carried onto each returned page for the default
+// CareerPageScorer's source-trust weighting.
+func (a *DiscoveryActivities) DiscoverCareerPages(ctx context.Context, domain string, companyName string, source string) ([]CareerPageInfo, error) {
 	log.Printf("Discovering career pages for: %s (%s)", domain, companyName)
 
 	payload := map[string]string{
 		"domain": domain,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/discover/career-pages", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result struct {
 		Pages []struct {
 			URL        string  `json:"url"`
@@ -236,8 +191,8 @@ func (a *DiscoveryActivities) DiscoverCareerPages(ctx context.Context, domain st
 		} `json:"career_pages"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := a.osint().post(ctx, "/discover/career-pages", payload, &result); err != nil {
+		return nil, err
 	}
 
 	pages := make([]CareerPageInfo, len(result.Pages))
@@ -248,6 +203,7 @@ func (a *DiscoveryActivities) DiscoverCareerPages(ctx context.Context, domain st
 			PageType:   p.PageType,
 			Confidence: p.Confidence,
 			Priority:   1,
+			Source:     source,
 		}
 	}
 
@@ -255,62 +211,65 @@ func (a *DiscoveryActivities) DiscoverCareerPages(ctx context.Context, domain st
 	return pages, nil
 }
 
-// EnumerateSubdomains enumerates subdomains for a domain
+// EnumerateSubdomains enumerates subdomains for a domain by fanning out to
+// the free CT/passive-DNS sources in subdomainSources() alongside the OSINT
+// service's own /discover/subdomains endpoint, deduping by hostname and
+// scoring each hostname's confidence by how many independent sources agreed
+// on it. Dead hosts (no HEAD/GET response) are dropped before they'd
+// otherwise reach QueueURLsForCrawling.
 func (a *DiscoveryActivities) EnumerateSubdomains(ctx context.Context, domain string) ([]CareerPageInfo, error) {
 	log.Printf("Enumerating subdomains for: %s", domain)
 
-	payload := map[string]string{
-		"domain": domain,
-	}
+	hits := a.mergeSubdomainResults(ctx, domain)
+	totalSources := float64(len(subdomainSources()) + 1) // +1 for the OSINT endpoint
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/discover/subdomains", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	startedAt := time.Now()
+	liveClient := &http.Client{Timeout: subdomainLivenessTimeout}
+	pages := make([]CareerPageInfo, 0, len(hits))
+	processed := 0
+	for host, count := range hits {
+		processed++
+		if !isHostLive(ctx, liveClient, host) {
+			continue
+		}
+		pages = append(pages, CareerPageInfo{
+			URL:        "https://" + host,
+			Domain:     domain,
+			PageType:   "subdomain",
+			Confidence: float64(count) / totalSources,
+			Priority:   2,
+			Source:     "subdomains",
+		})
+		if processed%progressReportInterval == 0 {
+			a.reportProgress(ctx, startedAt, DiscoveryProgress{
+				Stage:     "subdomain_liveness",
+				Processed: processed,
+				Total:     len(hits),
+				LastItem:  host,
+			})
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
+	log.Printf("Found %d live subdomains for %s (%d candidates)", len(pages), domain, len(hits))
+	return pages, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
+// osintSubdomains calls the OSINT service's /discover/subdomains endpoint,
+// the original (pre-CT/passive-DNS) subdomain source.
+func (a *DiscoveryActivities) osintSubdomains(ctx context.Context, domain string) ([]string, error) {
+	payload := map[string]string{
+		"domain": domain,
 	}
 
 	var result struct {
 		Subdomains []string `json:"subdomains"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Convert subdomains to potential career page URLs
-	pages := make([]CareerPageInfo, len(result.Subdomains))
-	for i, subdomain := range result.Subdomains {
-		pages[i] = CareerPageInfo{
-			URL:        "https://" + subdomain,
-			Domain:     domain,
-			PageType:   "subdomain",
-			Confidence: 0.5,
-			Priority:   2,
-		}
+	if err := a.osint().post(ctx, "/discover/subdomains", payload, &result); err != nil {
+		return nil, err
 	}
 
-	log.Printf("Found %d subdomains for %s", len(pages), domain)
-	return pages, nil
+	return result.Subdomains, nil
 }
 
 // DetectATS detects ATS platform for a URL
@@ -321,40 +280,15 @@ func (a *DiscoveryActivities) DetectATS(ctx context.Context, url string) (ATSInf
 		"url": url,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return ATSInfo{}, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/detect/ats", bytes.NewBuffer(body))
-	if err != nil {
-		return ATSInfo{}, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return ATSInfo{}, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return ATSInfo{}, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result struct {
-		IsATS      bool    `json:"is_ats"`
-		Platform   string  `json:"platform"`
-		Confidence float64 `json:"confidence"`
+		IsATS      bool     `json:"is_ats"`
+		Platform   string   `json:"platform"`
+		Confidence float64  `json:"confidence"`
+		Skills     []string `json:"skills"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return ATSInfo{}, fmt.Errorf("failed to decode response: %w", err)
+	if err := a.osint().post(ctx, "/detect/ats", payload, &result); err != nil {
+		return ATSInfo{}, err
 	}
 
 	return ATSInfo{
@@ -362,58 +296,10 @@ func (a *DiscoveryActivities) DetectATS(ctx context.Context, url string) (ATSInf
 		IsATS:      result.IsATS,
 		Platform:   result.Platform,
 		Confidence: result.Confidence,
+		Skills:     result.Skills,
 	}, nil
 }
 
-// QueueURLsForCrawling queues discovered URLs for the crawler
-func (a *DiscoveryActivities) QueueURLsForCrawling(ctx context.Context, pages []CareerPageInfo) (int, error) {
-	log.Printf("Queuing %d URLs for crawling", len(pages))
-
-	if a.PostgreSQL == nil {
-		log.Println("Warning: PostgreSQL connection not available, skipping storage")
-		return len(pages), nil
-	}
-
-	queued := 0
-	for _, page := range pages {
-		// Generate URL hash
-		hash := sha256.Sum256([]byte(page.URL))
-		urlHash := hex.EncodeToString(hash[:])
-
-		// Get company ID from domain
-		var companyID *int
-		err := a.PostgreSQL.QueryRowContext(ctx, `
-			SELECT id FROM companies WHERE domain = $1 LIMIT 1
-		`, page.Domain).Scan(&companyID)
-		if err != nil && err != sql.ErrNoRows {
-			log.Printf("Error finding company for domain %s: %v", page.Domain, err)
-			continue
-		}
-
-		// Insert discovered URL
-		_, err = a.PostgreSQL.ExecContext(ctx, `
-			INSERT INTO discovered_urls (
-				company_id, url, url_hash, page_type, confidence,
-				ats_platform, discovered_via, priority
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			ON CONFLICT (url_hash) DO UPDATE
-			SET confidence = GREATEST(discovered_urls.confidence, EXCLUDED.confidence),
-			    priority = GREATEST(discovered_urls.priority, EXCLUDED.priority)
-		`, companyID, page.URL, urlHash, page.PageType, page.Confidence,
-			page.ATSPlatform, "osint", page.Priority)
-
-		if err != nil {
-			log.Printf("Warning: Failed to queue URL %s: %v", page.URL, err)
-			continue
-		}
-
-		queued++
-	}
-
-	log.Printf("✅ Queued %d/%d URLs for crawling", queued, len(pages))
-	return queued, nil
-}
-
 // GenerateDorkQueries generates Google dork queries for a keyword
 func (a *DiscoveryActivities) GenerateDorkQueries(_ context.Context, keyword string) ([]string, error) {
 	log.Printf("Generating dork queries for keyword: %s", keyword)
@@ -440,40 +326,14 @@ func (a *DiscoveryActivities) ExecuteDorkQuery(ctx context.Context, query string
 		"max_results": maxResults,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", a.OSINTUrl+"/discover/google-dork", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := a.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OSINT service: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Failed to close OSINT response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSINT service returned status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result struct {
 		URLs []struct {
 			URL string `json:"url"`
 		} `json:"urls"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := a.osint().post(ctx, "/discover/google-dork", payload, &result); err != nil {
+		return nil, err
 	}
 
 	urls := make([]string, len(result.URLs))
@@ -496,6 +356,8 @@ func (a *DiscoveryActivities) DetectATSAndExtractDomain(ctx context.Context, url
 		ATSPlatform: atsInfo.Platform,
 		Confidence:  atsInfo.Confidence,
 		Priority:    1,
+		Source:      "ats",
+		Skills:      atsInfo.Skills,
 	}, nil
 }
 
@@ -514,6 +376,13 @@ type CareerPageInfo struct {
 	Confidence  float64
 	ATSPlatform string
 	Priority    int
+	// Source names the discovery provider that produced this page (e.g.
+	// "subdomains", "ats", or an osint-service-derived company's Source),
+	// used by the default CareerPageScorer's source-trust weighting.
+	Source string
+	// Skills carries the skill tags DetectATS's underlying parse found for
+	// this page's job listing, if any.
+	Skills []string
 }
 
 type ATSInfo struct {
@@ -521,4 +390,7 @@ type ATSInfo struct {
 	IsATS      bool
 	Platform   string
 	Confidence float64
+	// Skills holds keyword tags the OSINT service's parser extracted from
+	// the job listing at URL, empty when it didn't run a skills pass.
+	Skills []string
 }
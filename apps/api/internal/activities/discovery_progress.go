@@ -0,0 +1,45 @@
+package activities
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// progressReportInterval bounds how often reportProgress fires inside a tight
+// loop, so a heartbeat/sink event doesn't get emitted on every single item.
+const progressReportInterval = 25
+
+// DiscoveryProgress is the periodic checkpoint a long-running discovery
+// activity emits as it works through a batch. Processed/Total let a caller
+// show a progress bar; LastItem names whatever was just handled (a dork URL,
+// a candidate subdomain, a queued URL) for a human-readable log line.
+type DiscoveryProgress struct {
+	Stage     string
+	Processed int
+	Total     int
+	LastItem  string
+	ElapsedMs int64
+}
+
+// ProgressSink receives the same DiscoveryProgress events a Temporal worker
+// sees via activity.RecordHeartbeat, for callers that aren't running inside a
+// Temporal activity context (a CLI tool, a test). Set
+// DiscoveryActivities.ProgressSink to receive them; leave it nil otherwise.
+type ProgressSink interface {
+	OnProgress(progress DiscoveryProgress)
+}
+
+// reportProgress records progress as a Temporal activity heartbeat — so a
+// worker restart can resume mid-batch from the heartbeat details instead of
+// re-running the activity from scratch — and forwards the same event to
+// a.ProgressSink if one is configured.
+func (a *DiscoveryActivities) reportProgress(ctx context.Context, startedAt time.Time, progress DiscoveryProgress) {
+	progress.ElapsedMs = time.Since(startedAt).Milliseconds()
+
+	activity.RecordHeartbeat(ctx, progress)
+	if a.ProgressSink != nil {
+		a.ProgressSink.OnProgress(progress)
+	}
+}
@@ -0,0 +1,270 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DiscoveryRequest is the uniform input handed to every DiscoveryProvider.
+// Providers read whichever fields are relevant to them: company-discovery
+// sources use Query, subdomain enumeration uses Domain, ATS detection uses URL.
+type DiscoveryRequest struct {
+	Query      string
+	Domain     string
+	URL        string
+	MaxResults int
+}
+
+// FilterSet bounds what a DiscoveryProvider is allowed to hand back to the
+// workflow: which domains to keep or drop, how confident a result has to be,
+// how many results to return, and which TLDs are in scope.
+type FilterSet struct {
+	IncludeDomains []string
+	ExcludeDomains []string
+	MinConfidence  float64
+	MaxResults     int
+	AllowedTLDs    []string
+}
+
+// DiscoveryProvider is a single OSINT discovery source. Implementations wrap
+// whatever's needed to talk to that source (an OSINT service endpoint, a
+// third-party API, a static list) and return whatever they find as companies
+// and/or career pages.
+type DiscoveryProvider interface {
+	// Name identifies the provider; it's also the key used in
+	// DiscoveryInput.Sources and ProvidersMap.
+	Name() string
+	// Discover runs the provider against req and returns any companies and
+	// career pages it found.
+	Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error)
+	// SupportsFilters returns the FilterSet this provider recommends when none
+	// is configured in ProvidersMap.
+	SupportsFilters() FilterSet
+}
+
+// ProviderConfig pairs a registered DiscoveryProvider with the FilterSet its
+// results are run through before reaching the workflow.
+type ProviderConfig struct {
+	Provider DiscoveryProvider
+	Filters  FilterSet
+}
+
+// ProvidersMap is the registry of discovery sources available to
+// DiscoverFromProvider, keyed by provider name. Adding a new source (e.g.
+// crt.sh, Wayback) means registering it here — the workflow and
+// DiscoverFromProvider never need to change.
+type ProvidersMap map[string]ProviderConfig
+
+// DefaultProviders builds the standard ProvidersMap for a, wiring each
+// built-in provider to the OSINT service client a already holds and applying
+// conservative default filters. Set DiscoveryActivities.Providers to override
+// this, e.g. with tighter filters or an additional source.
+func DefaultProviders(a *DiscoveryActivities) ProvidersMap {
+	return ProvidersMap{
+		"github": {
+			Provider: &githubProvider{a: a},
+			Filters:  FilterSet{MaxResults: 50},
+		},
+		"google_dork": {
+			Provider: &googleDorkProvider{a: a},
+			Filters:  FilterSet{MaxResults: 100},
+		},
+		"manual": {
+			Provider: &manualProvider{a: a},
+			Filters:  FilterSet{},
+		},
+		"subdomains": {
+			// Confidence is the share of the five subdomain sources (crt.sh,
+			// HackerTarget, OTX, Anubis, OSINT service) that reported a
+			// hostname, so MinConfidence 0.2 keeps any hostname at least one
+			// of them reported.
+			Provider: &subdomainProvider{a: a},
+			Filters:  FilterSet{MinConfidence: 0.2, MaxResults: 50},
+		},
+		"ats": {
+			Provider: &atsDetectionProvider{a: a},
+			Filters:  FilterSet{MinConfidence: 0.5},
+		},
+	}
+}
+
+// providers returns a.Providers, falling back to DefaultProviders(a) when
+// it's unset — the same lazy-default pattern archiveBackend() uses for
+// CrawlActivities.Archive.
+func (a *DiscoveryActivities) providers() ProvidersMap {
+	if a.Providers != nil {
+		return a.Providers
+	}
+	return DefaultProviders(a)
+}
+
+// DiscoveryProviderResult bundles a provider's companies and career pages
+// into the single return value Temporal activities require.
+type DiscoveryProviderResult struct {
+	Companies   []CompanyInfo
+	CareerPages []CareerPageInfo
+}
+
+// DiscoverFromProvider runs the registered DiscoveryProvider named
+// providerName and applies its configured FilterSet to the results. This is
+// the one activity the workflow calls for every entry in
+// DiscoveryInput.Sources, so new providers never require a workflow change.
+func (a *DiscoveryActivities) DiscoverFromProvider(ctx context.Context, providerName string, req DiscoveryRequest) (DiscoveryProviderResult, error) {
+	cfg, ok := a.providers()[providerName]
+	if !ok {
+		return DiscoveryProviderResult{}, fmt.Errorf("unknown discovery provider: %s", providerName)
+	}
+
+	companies, pages, err := cfg.Provider.Discover(ctx, req)
+	if err != nil {
+		return DiscoveryProviderResult{}, fmt.Errorf("provider %s: %w", providerName, err)
+	}
+
+	return DiscoveryProviderResult{
+		Companies:   filterCompanies(companies, cfg.Filters),
+		CareerPages: filterCareerPages(pages, cfg.Filters),
+	}, nil
+}
+
+// filterCompanies applies f's domain and result-count rules to companies.
+func filterCompanies(companies []CompanyInfo, f FilterSet) []CompanyInfo {
+	out := make([]CompanyInfo, 0, len(companies))
+	for _, c := range companies {
+		if matchesDomainFilters(c.Domain, f) {
+			out = append(out, c)
+		}
+	}
+	if f.MaxResults > 0 && len(out) > f.MaxResults {
+		out = out[:f.MaxResults]
+	}
+	return out
+}
+
+// filterCareerPages applies f's confidence, domain, and result-count rules to pages.
+func filterCareerPages(pages []CareerPageInfo, f FilterSet) []CareerPageInfo {
+	out := make([]CareerPageInfo, 0, len(pages))
+	for _, p := range pages {
+		if p.Confidence < f.MinConfidence {
+			continue
+		}
+		if !matchesDomainFilters(p.Domain, f) {
+			continue
+		}
+		out = append(out, p)
+	}
+	if f.MaxResults > 0 && len(out) > f.MaxResults {
+		out = out[:f.MaxResults]
+	}
+	return out
+}
+
+// matchesDomainFilters reports whether domain passes f's TLD allowlist and
+// include/exclude patterns. An empty domain (a provider that doesn't set one,
+// e.g. ATS detection before the domain is known) always passes.
+func matchesDomainFilters(domain string, f FilterSet) bool {
+	if domain == "" {
+		return true
+	}
+	if len(f.AllowedTLDs) > 0 && !matchesAnyTLD(domain, f.AllowedTLDs) {
+		return false
+	}
+	if len(f.IncludeDomains) > 0 && !matchesAnyPattern(domain, f.IncludeDomains) {
+		return false
+	}
+	if matchesAnyPattern(domain, f.ExcludeDomains) {
+		return false
+	}
+	return true
+}
+
+func matchesAnyPattern(domain string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(domain, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyTLD(domain string, tlds []string) bool {
+	for _, tld := range tlds {
+		if strings.HasSuffix(domain, "."+strings.TrimPrefix(tld, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// githubProvider wraps DiscoverCompaniesFromGitHub as a DiscoveryProvider.
+type githubProvider struct{ a *DiscoveryActivities }
+
+func (p *githubProvider) Name() string { return "github" }
+
+func (p *githubProvider) Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error) {
+	companies, err := p.a.DiscoverCompaniesFromGitHub(ctx, req.Query, req.MaxResults)
+	return companies, nil, err
+}
+
+func (p *githubProvider) SupportsFilters() FilterSet {
+	return FilterSet{MaxResults: 50}
+}
+
+// googleDorkProvider wraps DiscoverCompaniesFromGoogleDorks as a DiscoveryProvider.
+type googleDorkProvider struct{ a *DiscoveryActivities }
+
+func (p *googleDorkProvider) Name() string { return "google_dork" }
+
+func (p *googleDorkProvider) Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error) {
+	companies, err := p.a.DiscoverCompaniesFromGoogleDorks(ctx, req.Query, req.MaxResults)
+	return companies, nil, err
+}
+
+func (p *googleDorkProvider) SupportsFilters() FilterSet {
+	return FilterSet{MaxResults: 100}
+}
+
+// manualProvider wraps AddCompanyManually as a DiscoveryProvider.
+type manualProvider struct{ a *DiscoveryActivities }
+
+func (p *manualProvider) Name() string { return "manual" }
+
+func (p *manualProvider) Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error) {
+	companies, err := p.a.AddCompanyManually(ctx, req.Query)
+	return companies, nil, err
+}
+
+func (p *manualProvider) SupportsFilters() FilterSet {
+	return FilterSet{}
+}
+
+// subdomainProvider wraps EnumerateSubdomains as a DiscoveryProvider.
+type subdomainProvider struct{ a *DiscoveryActivities }
+
+func (p *subdomainProvider) Name() string { return "subdomains" }
+
+func (p *subdomainProvider) Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error) {
+	pages, err := p.a.EnumerateSubdomains(ctx, req.Domain)
+	return nil, pages, err
+}
+
+func (p *subdomainProvider) SupportsFilters() FilterSet {
+	return FilterSet{MinConfidence: 0.2, MaxResults: 50}
+}
+
+// atsDetectionProvider wraps DetectATSAndExtractDomain as a DiscoveryProvider.
+type atsDetectionProvider struct{ a *DiscoveryActivities }
+
+func (p *atsDetectionProvider) Name() string { return "ats" }
+
+func (p *atsDetectionProvider) Discover(ctx context.Context, req DiscoveryRequest) ([]CompanyInfo, []CareerPageInfo, error) {
+	page, err := p.a.DetectATSAndExtractDomain(ctx, req.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, []CareerPageInfo{page}, nil
+}
+
+func (p *atsDetectionProvider) SupportsFilters() FilterSet {
+	return FilterSet{MinConfidence: 0.5}
+}
@@ -0,0 +1,276 @@
+package activities
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/crawlqueue"
+	"github.com/lib/pq"
+)
+
+// QueueStats summarizes one QueueURLsForCrawling batch, so callers can emit
+// accurate metrics instead of a single opaque count.
+type QueueStats struct {
+	Attempted int
+	Inserted  int
+	Updated   int
+	Skipped   int
+}
+
+// queuedURL is one page deduped by url_hash and ready to be bulk-loaded into
+// discovered_urls.
+type queuedURL struct {
+	page    CareerPageInfo
+	urlHash string
+}
+
+// QueueURLsForCrawling queues discovered URLs for the crawler. It dedupes
+// pages by url_hash in memory, batch-resolves every page.Domain to a company
+// ID with a single query, then bulk-loads the batch through a temp table via
+// pq.CopyIn and merges it into discovered_urls with one
+// INSERT ... SELECT ... ON CONFLICT DO UPDATE — instead of one SELECT and one
+// INSERT per URL. Beyond that insert, it does nothing else: it no longer
+// spawns any crawl workflow itself. A crawlqueue.Acquirer running in a
+// separate crawl-worker process claims the rows this inserts (woken by the
+// pg_notify this sends on crawlqueue.NotifyChannel) and starts
+// CareerPageCrawlWorkflow for each one, so discovery throughput and crawl
+// worker capacity are no longer coupled to each other.
+func (a *DiscoveryActivities) QueueURLsForCrawling(ctx context.Context, pages []CareerPageInfo) (QueueStats, error) {
+	log.Printf("Queuing %d URLs for crawling", len(pages))
+	stats := QueueStats{Attempted: len(pages)}
+
+	if a.PostgreSQL == nil {
+		log.Println("Warning: PostgreSQL connection not available, skipping storage")
+		stats.Skipped = len(pages)
+		return stats, nil
+	}
+
+	rows, duplicates := dedupeByURLHash(pages)
+	stats.Skipped += duplicates
+	if len(rows) == 0 {
+		return stats, nil
+	}
+
+	companyIDs, err := a.resolveCompanyIDs(ctx, rows)
+	if err != nil {
+		return stats, fmt.Errorf("resolving company IDs: %w", err)
+	}
+
+	inserted, updated, err := a.bulkQueueURLs(ctx, rows, companyIDs)
+	if err != nil {
+		return stats, fmt.Errorf("bulk queueing URLs: %w", err)
+	}
+	stats.Inserted = inserted
+	stats.Updated = updated
+
+	log.Printf("✅ Queued %d/%d URLs for crawling (%d inserted, %d updated, %d skipped)",
+		inserted+updated, stats.Attempted, inserted, updated, stats.Skipped)
+	return stats, nil
+}
+
+// dedupeByURLHash collapses pages that hash to the same url_hash, keeping the
+// entry with the highest confidence (ties broken by priority). It returns the
+// deduped rows and how many input pages were dropped as duplicates.
+func dedupeByURLHash(pages []CareerPageInfo) ([]queuedURL, int) {
+	byHash := make(map[string]queuedURL, len(pages))
+	duplicates := 0
+
+	for _, page := range pages {
+		hash := sha256.Sum256([]byte(page.URL))
+		urlHash := hex.EncodeToString(hash[:])
+
+		existing, ok := byHash[urlHash]
+		if !ok {
+			byHash[urlHash] = queuedURL{page: page, urlHash: urlHash}
+			continue
+		}
+
+		duplicates++
+		if page.Confidence > existing.page.Confidence ||
+			(page.Confidence == existing.page.Confidence && page.Priority > existing.page.Priority) {
+			byHash[urlHash] = queuedURL{page: page, urlHash: urlHash}
+		}
+	}
+
+	rows := make([]queuedURL, 0, len(byHash))
+	for _, row := range byHash {
+		rows = append(rows, row)
+	}
+	return rows, duplicates
+}
+
+// resolveCompanyIDs batch-resolves every distinct domain in rows to its
+// company ID with a single query, instead of one SELECT per URL.
+func (a *DiscoveryActivities) resolveCompanyIDs(ctx context.Context, rows []queuedURL) (map[string]int, error) {
+	domainSet := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		if row.page.Domain != "" {
+			domainSet[row.page.Domain] = struct{}{}
+		}
+	}
+	if len(domainSet) == 0 {
+		return map[string]int{}, nil
+	}
+
+	domains := make([]string, 0, len(domainSet))
+	for domain := range domainSet {
+		domains = append(domains, domain)
+	}
+
+	dbRows, err := a.PostgreSQL.QueryContext(ctx,
+		`SELECT id, domain FROM companies WHERE domain = ANY($1)`, pq.Array(domains))
+	if err != nil {
+		return nil, fmt.Errorf("querying companies: %w", err)
+	}
+	defer dbRows.Close()
+
+	companyIDs := make(map[string]int, len(domains))
+	for dbRows.Next() {
+		var id int
+		var domain string
+		if err := dbRows.Scan(&id, &domain); err != nil {
+			return nil, fmt.Errorf("scanning company row: %w", err)
+		}
+		companyIDs[domain] = id
+	}
+	return companyIDs, dbRows.Err()
+}
+
+// bulkQueueURLs loads rows into a temp table via pq.CopyIn, then merges that
+// table into discovered_urls with a single INSERT ... SELECT ... ON CONFLICT
+// DO UPDATE, returning how many rows were newly inserted vs. updated.
+func (a *DiscoveryActivities) bulkQueueURLs(ctx context.Context, rows []queuedURL, companyIDs map[string]int) (int, int, error) {
+	startedAt := time.Now()
+
+	tx, err := a.PostgreSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE pending_urls (
+			company_id   INTEGER,
+			url          TEXT,
+			url_hash     TEXT,
+			page_type    TEXT,
+			confidence   DOUBLE PRECISION,
+			ats_platform TEXT,
+			priority     INTEGER,
+			tags         JSONB
+		) ON COMMIT DROP
+	`); err != nil {
+		tx.Rollback()
+		return 0, 0, fmt.Errorf("creating temp table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("pending_urls",
+		"company_id", "url", "url_hash", "page_type", "confidence", "ats_platform", "priority", "tags"))
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, fmt.Errorf("preparing COPY: %w", err)
+	}
+
+	for i, row := range rows {
+		var companyID *int
+		if id, ok := companyIDs[row.page.Domain]; ok {
+			companyID = &id
+		}
+		tagsJSON, err := acquirerTags(row.page.ATSPlatform)
+		if err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("encoding tags for %s: %w", row.page.URL, err)
+		}
+		if _, err := stmt.ExecContext(ctx, companyID, row.page.URL, row.urlHash,
+			row.page.PageType, row.page.Confidence, row.page.ATSPlatform, row.page.Priority, tagsJSON); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("copying row %s: %w", row.page.URL, err)
+		}
+		if i%progressReportInterval == 0 {
+			a.reportProgress(ctx, startedAt, DiscoveryProgress{
+				Stage:     "queue_urls",
+				Processed: i + 1,
+				Total:     len(rows),
+				LastItem:  row.page.URL,
+			})
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return 0, 0, fmt.Errorf("flushing COPY: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		tx.Rollback()
+		return 0, 0, fmt.Errorf("closing COPY statement: %w", err)
+	}
+
+	mergeRows, err := tx.QueryContext(ctx, `
+		INSERT INTO discovered_urls (
+			company_id, url, url_hash, page_type, confidence,
+			ats_platform, discovered_via, priority, tags, status
+		)
+		SELECT company_id, url, url_hash, page_type, confidence,
+		       ats_platform, 'osint', priority, tags, 'pending'
+		FROM pending_urls
+		ON CONFLICT (url_hash) DO UPDATE
+		SET confidence = GREATEST(discovered_urls.confidence, EXCLUDED.confidence),
+		    priority = GREATEST(discovered_urls.priority, EXCLUDED.priority)
+		RETURNING (xmax = 0) AS inserted
+	`)
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, fmt.Errorf("merging pending_urls: %w", err)
+	}
+
+	var inserted, updated int
+	for mergeRows.Next() {
+		var isInsert bool
+		if err := mergeRows.Scan(&isInsert); err != nil {
+			mergeRows.Close()
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("scanning merge result: %w", err)
+		}
+		if isInsert {
+			inserted++
+		} else {
+			updated++
+		}
+	}
+	if err := mergeRows.Err(); err != nil {
+		mergeRows.Close()
+		tx.Rollback()
+		return 0, 0, err
+	}
+	mergeRows.Close()
+
+	if inserted > 0 {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, '')`, crawlqueue.NotifyChannel); err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("notifying %s: %w", crawlqueue.NotifyChannel, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return inserted, updated, nil
+}
+
+// acquirerTags builds the tags JSONB a crawlqueue.Acquirer matches against
+// when claiming this row. Only platform is known at queue time today; a
+// future tag (e.g. region) just needs another key added here.
+func acquirerTags(atsPlatform string) ([]byte, error) {
+	tags := make(map[string]string, 1)
+	if atsPlatform != "" {
+		tags["platform"] = atsPlatform
+	}
+	return json.Marshal(tags)
+}
@@ -0,0 +1,39 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// EnsureJobScoresTable creates job_scores if it doesn't already exist, as a
+// ReplacingMergeTree ordered by job_hash: ScoreJobActivity re-scores a job
+// every time it's re-parsed (see storeJobScore), and ReplacingMergeTree
+// (version) is what collapses the table down to the newest score per
+// job_hash, the same FINAL-on-read tradeoff EnsureJobsTable documents. Every
+// feature sub-score is kept alongside the final score so the table doubles
+// as training data for a future learned model, not just the linear one
+// scoring.Combine runs today.
+func EnsureJobScoresTable(ctx context.Context, conn clickhouse.Conn) error {
+	err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS job_scores (
+			job_hash String,
+			score Int32,
+			jsonld_score Float64,
+			domain_reputation_score Float64,
+			buzzword_ratio_score Float64,
+			salary_specificity_score Float64,
+			description_length_score Float64,
+			duplicate_title_score Float64,
+			osint_hits_score Float64,
+			scored_at DateTime,
+			version UInt64
+		) ENGINE = ReplacingMergeTree(version)
+		ORDER BY job_hash
+	`)
+	if err != nil {
+		return fmt.Errorf("creating job_scores table: %w", err)
+	}
+	return nil
+}
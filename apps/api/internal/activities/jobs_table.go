@@ -0,0 +1,46 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// EnsureJobsTable creates the jobs table if it doesn't already exist, as a
+// ReplacingMergeTree ordered by job_hash: storeParsedJob's dedup check (see
+// nextJobVersion) only inserts a new row when a job_hash's content actually
+// changed, incrementing version each time, and ReplacingMergeTree(version)
+// is what collapses the table down to the newest row per job_hash on
+// background merge (or immediately, for a query, with FINAL).
+func EnsureJobsTable(ctx context.Context, conn clickhouse.Conn) error {
+	err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS jobs (
+			id String,
+			job_hash String,
+			title String,
+			company String,
+			description String,
+			location String,
+			remote UInt8,
+			salary_min Nullable(Float64),
+			salary_max Nullable(Float64),
+			currency Nullable(String),
+			job_type String,
+			experience_level Nullable(String),
+			real_score Int32,
+			source_url String,
+			source_platform String,
+			tags Array(String),
+			posted_at DateTime,
+			updated_at DateTime,
+			version UInt64,
+			content_hash String
+		) ENGINE = ReplacingMergeTree(version)
+		ORDER BY job_hash
+	`)
+	if err != nil {
+		return fmt.Errorf("creating jobs table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/httpx"
+	"github.com/itisrohit/quiethire/apps/api/internal/retry"
+)
+
+// Tuning constants for every call the OSINT client makes on behalf of
+// DiscoveryActivities.
+const (
+	// osintMaxConcurrency bounds how many OSINT-service calls can be in
+	// flight at once across every DiscoveryActivities method, so a workflow
+	// fanning out DetectATSAndExtractDomain over thousands of dork results
+	// doesn't stampede the sidecar.
+	osintMaxConcurrency = 8
+	osintMaxRetries     = 4
+	osintBaseBackoff    = 500 * time.Millisecond
+	osintMaxBackoff     = 30 * time.Second
+	// osintHeartbeatInterval is how often a slow call records a Temporal
+	// activity heartbeat, extending its heartbeat deadline instead of letting
+	// the activity get killed and retried from scratch.
+	osintHeartbeatInterval = 10 * time.Second
+)
+
+// osintClient wraps HTTPClient with the retry, backoff, concurrency-bounding,
+// and heartbeat-extension behavior every DiscoveryActivities method needs
+// when calling the OSINT service, so each activity only has to describe its
+// endpoint, request payload, and response shape. The retry/backoff/circuit-
+// breaker mechanics themselves live in httpx.Client, the same one
+// CrawlActivities uses for the Crawler/Parser services, so a fix to that
+// shared behavior doesn't need porting to a second copy here.
+type osintClient struct {
+	baseURL string
+	sem     chan struct{}
+	client  *httpx.Client
+}
+
+// newOSINTClient builds an osintClient that calls baseURL through httpClient,
+// bounding concurrent in-flight calls to osintMaxConcurrency.
+func newOSINTClient(httpClient *http.Client, baseURL string) *osintClient {
+	return &osintClient{
+		baseURL: baseURL,
+		sem:     make(chan struct{}, osintMaxConcurrency),
+		client:  httpx.New(httpClient),
+	}
+}
+
+// post sends a JSON POST to path (relative to baseURL) with body marshaled
+// from payload and decodes the JSON response into out (skipped if out is
+// nil). It retries on 429/5xx with exponential backoff plus jitter, honoring
+// Retry-After when the upstream sends one, and acquires a slot from the
+// shared worker pool before calling so at most osintMaxConcurrency requests
+// to the OSINT service are ever in flight at once. Once the OSINT host's
+// circuit breaker is open, post fails immediately with a non-retryable
+// Temporal ApplicationError instead of making a call at all.
+func (c *osintClient) post(ctx context.Context, path string, payload, out interface{}) error {
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	stopHeartbeat := extendDeadline(ctx)
+	defer stopHeartbeat()
+
+	cfg := retry.Config{
+		InitialDelay: osintBaseBackoff,
+		Multiplier:   2.0,
+		MaxDelay:     osintMaxBackoff,
+		MaxAttempts:  osintMaxRetries + 1,
+	}
+
+	respBody, statusCode, err := c.client.PostWithConfig(ctx, c.baseURL+path, body, cfg)
+	if err != nil {
+		return httpx.WrapErr(fmt.Sprintf("calling OSINT service %s", path), err)
+	}
+
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("OSINT service returned status %d: %s", statusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// extendDeadline starts a goroutine that records a Temporal activity
+// heartbeat every osintHeartbeatInterval until the returned stop func is
+// called. A long osintClient.post call uses this to extend its heartbeat
+// deadline for as long as it's genuinely waiting on the upstream, rather than
+// being killed and retried from scratch by Temporal's heartbeat timeout.
+func extendDeadline(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(osintHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				activity.RecordHeartbeat(ctx)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,79 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// ReaperActivities finds CareerPageCrawlWorkflow executions that have been
+// running past a staleness threshold and cancels them, the same "detect and
+// reclaim work a dead worker abandoned" role Coder's provisioner daemon
+// plays for its own build jobs, applied here to Temporal executions instead.
+// A Client is required for both activities; there's no other way to reach
+// visibility or issue a termination from inside a worker process.
+type ReaperActivities struct {
+	Client    client.Client
+	Namespace string // defaults to "default" when empty
+}
+
+func (a *ReaperActivities) namespace() string {
+	if a.Namespace == "" {
+		return "default"
+	}
+	return a.Namespace
+}
+
+// StuckWorkflow identifies one CareerPageCrawlWorkflow execution that has
+// been running longer than ReaperWorkflow's staleness threshold.
+type StuckWorkflow struct {
+	WorkflowID string
+	RunID      string
+	StartedAt  time.Time
+}
+
+// FindStuckCareerPageCrawls lists CareerPageCrawlWorkflow executions still
+// running before olderThan started, via a visibility query rather than
+// tracking per-crawl progress ourselves. Executions are selected by start
+// time alone: heartbeats and other progress are not checked, so a crawl
+// that is merely slow (a large career page, a slow upstream server) but
+// started before olderThan is returned too.
+func (a *ReaperActivities) FindStuckCareerPageCrawls(ctx context.Context, olderThan time.Time) ([]StuckWorkflow, error) {
+	query := fmt.Sprintf(
+		"WorkflowType = 'CareerPageCrawlWorkflow' AND ExecutionStatus = 'Running' AND StartTime < '%s'",
+		olderThan.UTC().Format(time.RFC3339),
+	)
+
+	resp, err := a.Client.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: a.namespace(),
+		Query:     query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing stuck career page crawls: %w", err)
+	}
+
+	stuck := make([]StuckWorkflow, 0, len(resp.Executions))
+	for _, exec := range resp.Executions {
+		stuck = append(stuck, StuckWorkflow{
+			WorkflowID: exec.Execution.WorkflowId,
+			RunID:      exec.Execution.RunId,
+			StartedAt:  exec.StartTime.AsTime(),
+		})
+	}
+	return stuck, nil
+}
+
+// CancelStuckWorkflow terminates workflowID/runID with reason recorded
+// against the execution. Temporal's graceful CancelWorkflow doesn't accept
+// a reason, so a forced TerminateWorkflow is used instead — acceptable here
+// since ReaperWorkflow only reaches for this once an execution has already
+// run well past its staleness threshold with nothing left to clean up.
+func (a *ReaperActivities) CancelStuckWorkflow(ctx context.Context, workflowID, runID, reason string) error {
+	if err := a.Client.TerminateWorkflow(ctx, workflowID, runID, reason); err != nil {
+		return fmt.Errorf("terminating stuck workflow %s/%s: %w", workflowID, runID, err)
+	}
+	return nil
+}
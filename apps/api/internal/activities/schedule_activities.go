@@ -0,0 +1,284 @@
+package activities
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleCronParser accepts the standard 5-field cron syntax
+// ("minute hour dom month dow"), matching crontab(5) and what operators
+// typing a cron_expr by hand expect.
+var scheduleCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// CrawlSchedule is one crawl_schedules row: a platform crawl that
+// SchedulerWorkflow starts on its own cron_expr, instead of
+// ScheduledCrawlWorkflow's old hardcoded platform slice.
+type CrawlSchedule struct {
+	ID       int64
+	Platform string
+	SeedURLs []string
+	CronExpr string
+	MaxJobs  int
+	Enabled  bool
+	Tags     map[string]string
+	// JitterSeconds bounds a random delay added to each computed
+	// next_run_at, so schedules sharing a cron_expr (e.g. several platforms
+	// all due "every 6 hours") don't all start their CrawlCoordinatorWorkflow
+	// children in the same instant.
+	JitterSeconds int
+	NextRunAt     time.Time
+	LastRunAt     *time.Time
+}
+
+// ScheduleActivities groups the Temporal activities SchedulerWorkflow calls
+// to read due schedules and record that they ran.
+type ScheduleActivities struct {
+	PostgreSQL *sql.DB
+}
+
+// EnsureCrawlSchedulesTable creates crawl_schedules if it doesn't already
+// exist.
+func EnsureCrawlSchedulesTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS crawl_schedules (
+			id             BIGSERIAL PRIMARY KEY,
+			platform       TEXT NOT NULL,
+			seed_urls      JSONB NOT NULL DEFAULT '[]'::jsonb,
+			cron_expr      TEXT NOT NULL,
+			max_jobs       INT NOT NULL DEFAULT 1000,
+			enabled        BOOLEAN NOT NULL DEFAULT true,
+			tags           JSONB NOT NULL DEFAULT '{}'::jsonb,
+			jitter_seconds INT NOT NULL DEFAULT 0,
+			next_run_at    TIMESTAMPTZ NOT NULL,
+			last_run_at    TIMESTAMPTZ,
+			created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating crawl_schedules table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS crawl_schedules_due_idx
+		ON crawl_schedules (enabled, next_run_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating crawl_schedules index: %w", err)
+	}
+	return nil
+}
+
+// CreateSchedule inserts a new schedule, computing its first next_run_at
+// from cronExpr relative to now.
+func CreateSchedule(ctx context.Context, db *sql.DB, platform string, seedURLs []string, cronExpr string, maxJobs, jitterSeconds int, tags map[string]string) (int64, error) {
+	next, err := scheduleCronParser.Parse(cronExpr)
+	if err != nil {
+		return 0, fmt.Errorf("parsing cron_expr %q: %w", cronExpr, err)
+	}
+
+	seedURLsJSON, err := json.Marshal(seedURLs)
+	if err != nil {
+		return 0, fmt.Errorf("encoding seed_urls: %w", err)
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return 0, fmt.Errorf("encoding tags: %w", err)
+	}
+
+	var id int64
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO crawl_schedules (platform, seed_urls, cron_expr, max_jobs, tags, jitter_seconds, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, platform, seedURLsJSON, cronExpr, maxJobs, tagsJSON, jitterSeconds, next.Next(time.Now())).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("inserting crawl_schedule: %w", err)
+	}
+	return id, nil
+}
+
+// scanSchedule reads one crawl_schedules row from a *sql.Row or *sql.Rows
+// positioned at a matching SELECT list (see ListSchedules/GetSchedule).
+func scanSchedule(scan func(dest ...interface{}) error) (CrawlSchedule, error) {
+	var (
+		s            CrawlSchedule
+		seedURLsJSON []byte
+		tagsJSON     []byte
+	)
+	if err := scan(&s.ID, &s.Platform, &seedURLsJSON, &s.CronExpr, &s.MaxJobs,
+		&s.Enabled, &tagsJSON, &s.JitterSeconds, &s.NextRunAt, &s.LastRunAt); err != nil {
+		return CrawlSchedule{}, err
+	}
+	if err := json.Unmarshal(seedURLsJSON, &s.SeedURLs); err != nil {
+		return CrawlSchedule{}, fmt.Errorf("decoding seed_urls for schedule %d: %w", s.ID, err)
+	}
+	if err := json.Unmarshal(tagsJSON, &s.Tags); err != nil {
+		return CrawlSchedule{}, fmt.Errorf("decoding tags for schedule %d: %w", s.ID, err)
+	}
+	return s, nil
+}
+
+const scheduleSelectList = `
+	id, platform, seed_urls, cron_expr, max_jobs, enabled, tags, jitter_seconds, next_run_at, last_run_at
+	FROM crawl_schedules
+`
+
+// ListSchedules returns every schedule, for CRUD endpoints that list what's
+// configured.
+func ListSchedules(ctx context.Context, db *sql.DB) ([]CrawlSchedule, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+scheduleSelectList+` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("listing crawl_schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []CrawlSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scanning crawl_schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// GetSchedule looks up one schedule by id, returning (nil, nil) if it
+// doesn't exist.
+func GetSchedule(ctx context.Context, db *sql.DB, id int64) (*CrawlSchedule, error) {
+	s, err := scanSchedule(db.QueryRowContext(ctx, `SELECT `+scheduleSelectList+` WHERE id = $1`, id).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting crawl_schedule %d: %w", id, err)
+	}
+	return &s, nil
+}
+
+// UpdateSchedule replaces a schedule's editable fields. Changing cron_expr
+// recomputes next_run_at relative to now, the same way CreateSchedule does,
+// so a edited schedule doesn't keep firing on its old cadence until the next
+// scheduled run.
+func UpdateSchedule(ctx context.Context, db *sql.DB, id int64, platform string, seedURLs []string, cronExpr string, maxJobs, jitterSeconds int, tags map[string]string) error {
+	next, err := scheduleCronParser.Parse(cronExpr)
+	if err != nil {
+		return fmt.Errorf("parsing cron_expr %q: %w", cronExpr, err)
+	}
+
+	seedURLsJSON, err := json.Marshal(seedURLs)
+	if err != nil {
+		return fmt.Errorf("encoding seed_urls: %w", err)
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("encoding tags: %w", err)
+	}
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE crawl_schedules
+		SET platform = $2, seed_urls = $3, cron_expr = $4, max_jobs = $5,
+		    tags = $6, jitter_seconds = $7, next_run_at = $8
+		WHERE id = $1
+	`, id, platform, seedURLsJSON, cronExpr, maxJobs, tagsJSON, jitterSeconds, next.Next(time.Now()))
+	if err != nil {
+		return fmt.Errorf("updating crawl_schedule %d: %w", id, err)
+	}
+	return requireRowsAffected(result, id)
+}
+
+// SetScheduleEnabled pauses (enabled=false) or resumes (enabled=true) a
+// schedule without disturbing its cron_expr or next_run_at.
+func SetScheduleEnabled(ctx context.Context, db *sql.DB, id int64, enabled bool) error {
+	result, err := db.ExecContext(ctx, `UPDATE crawl_schedules SET enabled = $2 WHERE id = $1`, id, enabled)
+	if err != nil {
+		return fmt.Errorf("updating crawl_schedule %d enabled state: %w", id, err)
+	}
+	return requireRowsAffected(result, id)
+}
+
+func requireRowsAffected(result sql.Result, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected for crawl_schedule %d: %w", id, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("crawl_schedule %d not found", id)
+	}
+	return nil
+}
+
+// LoadDueSchedules is a Temporal activity: it returns every enabled
+// schedule whose next_run_at has passed, locking the matching rows
+// FOR UPDATE SKIP LOCKED so a concurrently-running SchedulerWorkflow
+// (e.g. during a deploy overlap) can't pick up the same row twice.
+func (a *ScheduleActivities) LoadDueSchedules(ctx context.Context) ([]CrawlSchedule, error) {
+	if a.PostgreSQL == nil {
+		return nil, nil
+	}
+
+	rows, err := a.PostgreSQL.QueryContext(ctx, `
+		SELECT `+scheduleSelectList+`
+		WHERE enabled AND next_run_at <= now()
+		FOR UPDATE SKIP LOCKED
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("loading due crawl_schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var due []CrawlSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scanning due crawl_schedule: %w", err)
+		}
+		due = append(due, s)
+	}
+	return due, rows.Err()
+}
+
+// MarkScheduleRun is a Temporal activity: it records that a schedule just
+// ran at ranAt and advances next_run_at to the cron_expr's next occurrence
+// after ranAt, plus up to jitter_seconds of random jitter so schedules that
+// share a cadence don't all start their CrawlCoordinatorWorkflow child in
+// the same instant.
+func (a *ScheduleActivities) MarkScheduleRun(ctx context.Context, id int64, ranAt time.Time) error {
+	if a.PostgreSQL == nil {
+		return fmt.Errorf("marking crawl_schedule %d run: no PostgreSQL connection", id)
+	}
+
+	var (
+		cronExpr      string
+		jitterSeconds int
+	)
+	if err := a.PostgreSQL.QueryRowContext(ctx,
+		`SELECT cron_expr, jitter_seconds FROM crawl_schedules WHERE id = $1`, id,
+	).Scan(&cronExpr, &jitterSeconds); err != nil {
+		return fmt.Errorf("loading crawl_schedule %d for run marker: %w", id, err)
+	}
+
+	schedule, err := scheduleCronParser.Parse(cronExpr)
+	if err != nil {
+		return fmt.Errorf("parsing cron_expr %q for schedule %d: %w", cronExpr, id, err)
+	}
+	next := schedule.Next(ranAt)
+	if jitterSeconds > 0 {
+		next = next.Add(time.Duration(rand.Intn(jitterSeconds+1)) * time.Second)
+	}
+
+	result, err := a.PostgreSQL.ExecContext(ctx, `
+		UPDATE crawl_schedules SET last_run_at = $2, next_run_at = $3 WHERE id = $1
+	`, id, ranAt, next)
+	if err != nil {
+		return fmt.Errorf("marking crawl_schedule %d run: %w", id, err)
+	}
+	return requireRowsAffected(result, id)
+}
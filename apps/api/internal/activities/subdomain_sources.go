@@ -0,0 +1,264 @@
+package activities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subdomainSourceTimeout bounds a single CT/passive-DNS source lookup so one
+// slow source can't stall EnumerateSubdomains.
+const subdomainSourceTimeout = 10 * time.Second
+
+// subdomainLivenessTimeout bounds a single HEAD/GET liveness probe.
+const subdomainLivenessTimeout = 5 * time.Second
+
+// subdomainSource is a small pluggable client for one free certificate-
+// transparency or passive-DNS lookup service.
+type subdomainSource interface {
+	Name() string
+	Fetch(ctx context.Context, domain string) ([]string, error)
+}
+
+// subdomainSources returns the built-in CT/passive-DNS clients, each with its
+// own short-timeout http.Client. Add a new free source by implementing
+// subdomainSource and appending it here.
+func subdomainSources() []subdomainSource {
+	return []subdomainSource{
+		&crtShSource{client: &http.Client{Timeout: subdomainSourceTimeout}},
+		&hackerTargetSource{client: &http.Client{Timeout: subdomainSourceTimeout}},
+		&otxSource{client: &http.Client{Timeout: subdomainSourceTimeout}},
+		&anubisSource{client: &http.Client{Timeout: subdomainSourceTimeout}},
+	}
+}
+
+// mergeSubdomainResults fans out to every registered subdomainSource plus the
+// OSINT service's /discover/subdomains endpoint in parallel, normalizes and
+// dedupes hostnames per source, and returns how many independent sources
+// reported each hostname.
+func (a *DiscoveryActivities) mergeSubdomainResults(ctx context.Context, domain string) map[string]int {
+	hits := make(map[string]int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	record := func(sourceName string, hosts []string) {
+		seen := make(map[string]bool, len(hosts))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, h := range hosts {
+			h = normalizeHostname(h)
+			if h == "" || seen[h] {
+				continue
+			}
+			seen[h] = true
+			hits[h]++
+		}
+		log.Printf("Subdomain source %s found %d hostnames for %s", sourceName, len(seen), domain)
+	}
+
+	for _, src := range subdomainSources() {
+		wg.Add(1)
+		go func(src subdomainSource) {
+			defer wg.Done()
+			hosts, err := src.Fetch(ctx, domain)
+			if err != nil {
+				log.Printf("⚠️ Subdomain source %s failed for %s: %v", src.Name(), domain, err)
+				return
+			}
+			record(src.Name(), hosts)
+		}(src)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		hosts, err := a.osintSubdomains(ctx, domain)
+		if err != nil {
+			log.Printf("⚠️ OSINT subdomain lookup failed for %s: %v", domain, err)
+			return
+		}
+		record("osint", hosts)
+	}()
+
+	wg.Wait()
+	return hits
+}
+
+// normalizeHostname lowercases h, trims surrounding whitespace, and strips a
+// leading wildcard label so "*.Example.com " and "example.com" dedupe together.
+func normalizeHostname(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.TrimPrefix(h, "*.")
+}
+
+// isHostLive reports whether host responds to a HEAD or GET over HTTPS or
+// HTTP, used to filter dead names out of EnumerateSubdomains' results.
+func isHostLive(ctx context.Context, client *http.Client, host string) bool {
+	for _, scheme := range []string{"https://", "http://"} {
+		if probeURL(ctx, client, http.MethodHead, scheme+host) {
+			return true
+		}
+		if probeURL(ctx, client, http.MethodGet, scheme+host) {
+			return true
+		}
+	}
+	return false
+}
+
+func probeURL(ctx context.Context, client *http.Client, method, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// crtShSource queries crt.sh's certificate-transparency log search in JSON mode.
+type crtShSource struct{ client *http.Client }
+
+func (s *crtShSource) Name() string { return "crt.sh" }
+
+func (s *crtShSource) Fetch(ctx context.Context, domain string) ([]string, error) {
+	url := fmt.Sprintf("https://crt.sh/?q=%%.%s&output=json", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building crt.sh request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying crt.sh: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("crt.sh returned status %d", resp.StatusCode)
+	}
+
+	var entries []struct {
+		NameValue string `json:"name_value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding crt.sh response: %w", err)
+	}
+
+	var hosts []string
+	for _, e := range entries {
+		hosts = append(hosts, strings.Split(e.NameValue, "\n")...)
+	}
+	return hosts, nil
+}
+
+// hackerTargetSource queries HackerTarget's free hostsearch API, which
+// returns "hostname,ip" pairs as plain text, one per line.
+type hackerTargetSource struct{ client *http.Client }
+
+func (s *hackerTargetSource) Name() string { return "hackertarget" }
+
+func (s *hackerTargetSource) Fetch(ctx context.Context, domain string) ([]string, error) {
+	url := fmt.Sprintf("https://api.hackertarget.com/hostsearch/?q=%s", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building hackertarget request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying hackertarget: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading hackertarget response: %w", err)
+	}
+
+	var hosts []string
+	for _, line := range strings.Split(string(body), "\n") {
+		host := strings.SplitN(line, ",", 2)[0]
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// otxSource queries AlienVault OTX's passive DNS API.
+type otxSource struct{ client *http.Client }
+
+func (s *otxSource) Name() string { return "otx" }
+
+func (s *otxSource) Fetch(ctx context.Context, domain string) ([]string, error) {
+	url := fmt.Sprintf("https://otx.alienvault.com/api/v1/indicators/domain/%s/passive_dns", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building otx request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying otx: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("otx returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PassiveDNS []struct {
+			Hostname string `json:"hostname"`
+		} `json:"passive_dns"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding otx response: %w", err)
+	}
+
+	hosts := make([]string, 0, len(result.PassiveDNS))
+	for _, p := range result.PassiveDNS {
+		hosts = append(hosts, p.Hostname)
+	}
+	return hosts, nil
+}
+
+// anubisSource queries jonlu.ca's Anubis subdomain enumeration API.
+type anubisSource struct{ client *http.Client }
+
+func (s *anubisSource) Name() string { return "anubis" }
+
+func (s *anubisSource) Fetch(ctx context.Context, domain string) ([]string, error) {
+	url := fmt.Sprintf("https://jonlu.ca/anubis/subdomains/%s", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building anubis request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying anubis: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anubis returned status %d", resp.StatusCode)
+	}
+
+	var hosts []string
+	if err := json.NewDecoder(resp.Body).Decode(&hosts); err != nil {
+		return nil, fmt.Errorf("decoding anubis response: %w", err)
+	}
+	return hosts, nil
+}
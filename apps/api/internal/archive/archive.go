@@ -0,0 +1,56 @@
+// Package archive stores and retrieves raw crawled HTML behind a pluggable
+// Backend, so large payloads can live on cheap storage while the jobs table
+// only keeps a small URI pointing at them.
+package archive
+
+import (
+	"fmt"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// Backend stores and retrieves raw HTML for a crawled job, keyed by an
+// opaque URI whose format is owned by the backend.
+type Backend interface {
+	// Store persists html for jobID and returns the URI it was stored under.
+	Store(jobID string, html []byte, meta map[string]string) (uri string, err error)
+
+	// Fetch retrieves the html previously stored at uri.
+	Fetch(uri string) ([]byte, error)
+
+	// Exists reports whether uri currently has stored content.
+	Exists(uri string) bool
+
+	// CleanUp removes everything stored before the given time.
+	CleanUp(before time.Time) error
+}
+
+// Config selects and configures a Backend, decoded from JSON like
+// {"kind":"s3","bucket":"...","prefix":"..."}.
+type Config struct {
+	Kind   string `json:"kind"`
+	Root   string `json:"root,omitempty"`   // file
+	Bucket string `json:"bucket,omitempty"` // s3
+	Prefix string `json:"prefix,omitempty"` // s3
+	Region string `json:"region,omitempty"` // s3
+}
+
+// New builds the Backend selected by cfg.Kind. ch is only used by the
+// "clickhouse" kind and may be nil for "file" or "s3".
+func New(cfg Config, ch clickhouse.Conn) (Backend, error) {
+	switch cfg.Kind {
+	case "file":
+		root := cfg.Root
+		if root == "" {
+			root = "./data/raw-html"
+		}
+		return NewFileBackend(root)
+	case "s3":
+		return NewS3Backend(cfg.Bucket, cfg.Prefix, cfg.Region)
+	case "clickhouse":
+		return NewClickHouseBackend(ch), nil
+	default:
+		return nil, fmt.Errorf("unknown archive backend kind: %q", cfg.Kind)
+	}
+}
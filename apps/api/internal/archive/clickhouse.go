@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/chbatch"
+)
+
+// JobsRawHTMLTable and JobsRawHTMLInsertQuery let a caller wire a
+// chbatch.ClickHouseBatcher for the jobs_raw_html table with the exact
+// table name and column list ClickHouseBackend itself inserts with, via
+// NewClickHouseBackendWithBatcher.
+const (
+	JobsRawHTMLTable       = "jobs_raw_html"
+	JobsRawHTMLInsertQuery = "INSERT INTO jobs_raw_html (id, url, html, status)"
+)
+
+// enqueueTimeout bounds how long Store waits to hand a row to a batcher
+// whose queue is full, since Store itself has no caller-supplied context to
+// derive a deadline from.
+const enqueueTimeout = 10 * time.Second
+
+// ClickHouseBackend stores raw HTML directly in the jobs_raw_html table,
+// matching QuietHire's original behavior from before archive.Backend existed.
+type ClickHouseBackend struct {
+	conn    clickhouse.Conn
+	batcher *chbatch.ClickHouseBatcher
+}
+
+// NewClickHouseBackend wraps an existing ClickHouse connection, inserting
+// one row per Store call.
+func NewClickHouseBackend(conn clickhouse.Conn) *ClickHouseBackend {
+	return &ClickHouseBackend{conn: conn}
+}
+
+// NewClickHouseBackendWithBatcher is like NewClickHouseBackend, but routes
+// Store through batcher instead of issuing one INSERT per call. batcher
+// must have been constructed with a TableConfig{Table: JobsRawHTMLTable,
+// InsertQuery: JobsRawHTMLInsertQuery} and already be running (Run called).
+func NewClickHouseBackendWithBatcher(conn clickhouse.Conn, batcher *chbatch.ClickHouseBatcher) *ClickHouseBackend {
+	return &ClickHouseBackend{conn: conn, batcher: batcher}
+}
+
+// Store inserts html into jobs_raw_html keyed by jobID. meta["url"], if
+// present, is recorded alongside it. When b.batcher is set, the row is
+// buffered for a later batched flush instead of inserted immediately — the
+// returned URI is valid either way since it only encodes jobID.
+func (b *ClickHouseBackend) Store(jobID string, html []byte, meta map[string]string) (string, error) {
+	if b.conn == nil {
+		return "", fmt.Errorf("clickhouse archive backend has no connection")
+	}
+
+	uri := "clickhouse://jobs_raw_html/" + jobID
+
+	if b.batcher != nil {
+		// Store takes no context (it's part of the Backend interface other,
+		// non-batching backends implement too), so enqueueTimeout is what
+		// keeps a stalled batcher queue from blocking this call forever.
+		ctx, cancel := context.WithTimeout(context.Background(), enqueueTimeout)
+		defer cancel()
+
+		row := chbatch.Row{Table: JobsRawHTMLTable, Args: []interface{}{jobID, meta["url"], string(html), "success"}}
+		if err := b.batcher.Enqueue(ctx, row); err != nil {
+			return "", fmt.Errorf("enqueuing raw HTML for %s: %w", jobID, err)
+		}
+		return uri, nil
+	}
+
+	err := b.conn.Exec(context.Background(), `
+		INSERT INTO jobs_raw_html (id, url, html, status)
+		VALUES (?, ?, ?, ?)
+	`, jobID, meta["url"], string(html), "success")
+	if err != nil {
+		return "", fmt.Errorf("storing raw HTML for %s: %w", jobID, err)
+	}
+
+	return uri, nil
+}
+
+// Fetch reads back the html column for the job ID encoded in uri.
+func (b *ClickHouseBackend) Fetch(uri string) ([]byte, error) {
+	id, err := clickhouseID(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var html string
+	row := b.conn.QueryRow(context.Background(), `SELECT html FROM jobs_raw_html WHERE id = ? LIMIT 1`, id)
+	if err := row.Scan(&html); err != nil {
+		return nil, fmt.Errorf("fetching raw HTML for %s: %w", id, err)
+	}
+
+	return []byte(html), nil
+}
+
+// Exists reports whether a row for the job ID encoded in uri is present.
+func (b *ClickHouseBackend) Exists(uri string) bool {
+	id, err := clickhouseID(uri)
+	if err != nil {
+		return false
+	}
+
+	var count uint64
+	row := b.conn.QueryRow(context.Background(), `SELECT count() FROM jobs_raw_html WHERE id = ?`, id)
+	if err := row.Scan(&count); err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+// CleanUp is a no-op: retention for jobs_raw_html is handled by a ClickHouse
+// TTL setting on the table itself rather than explicit deletes from here.
+func (b *ClickHouseBackend) CleanUp(_ time.Time) error {
+	return nil
+}
+
+func clickhouseID(uri string) (string, error) {
+	const prefix = "clickhouse://jobs_raw_html/"
+	if !strings.HasPrefix(uri, prefix) {
+		return "", fmt.Errorf("not a clickhouse jobs_raw_html uri: %q", uri)
+	}
+	return strings.TrimPrefix(uri, prefix), nil
+}
@@ -0,0 +1,110 @@
+package archive
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileBackend stores raw HTML as gzip-compressed files under a rooted
+// directory, for single-node runs that don't need object storage.
+type FileBackend struct {
+	root string
+}
+
+// NewFileBackend creates a FileBackend rooted at root, creating the
+// directory if it doesn't already exist.
+func NewFileBackend(root string) (*FileBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("creating archive root %s: %w", root, err)
+	}
+	return &FileBackend{root: root}, nil
+}
+
+func (b *FileBackend) pathFor(jobID string) string {
+	return filepath.Join(b.root, jobID+".html.gz")
+}
+
+// Store gzip-compresses html and writes it to <root>/<jobID>.html.gz.
+func (b *FileBackend) Store(jobID string, html []byte, _ map[string]string) (string, error) {
+	path := b.pathFor(jobID)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(html); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("closing %s: %w", path, err)
+	}
+
+	return "file://" + path, nil
+}
+
+// Fetch reads and decompresses the file at the path encoded in uri.
+func (b *FileBackend) Fetch(uri string) ([]byte, error) {
+	path, err := filePath(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	return io.ReadAll(gz)
+}
+
+// Exists reports whether the file at the path encoded in uri is present.
+func (b *FileBackend) Exists(uri string) bool {
+	path, err := filePath(uri)
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// CleanUp removes every archived file last modified before cutoff.
+func (b *FileBackend) CleanUp(before time.Time) error {
+	return filepath.Walk(b.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if info.ModTime().Before(before) {
+			if rmErr := os.Remove(path); rmErr != nil {
+				return fmt.Errorf("removing %s: %w", path, rmErr)
+			}
+		}
+		return nil
+	})
+}
+
+func filePath(uri string) (string, error) {
+	const prefix = "file://"
+	if !strings.HasPrefix(uri, prefix) {
+		return "", fmt.Errorf("not a file:// uri: %q", uri)
+	}
+	return strings.TrimPrefix(uri, prefix), nil
+}
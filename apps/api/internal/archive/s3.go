@@ -0,0 +1,148 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Backend stores raw HTML as gzip objects in S3 under a lifecycle-friendly
+// yyyy/mm/dd/source/jobid.html.gz key layout, encrypted server-side.
+type S3Backend struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Backend builds an S3Backend for bucket, using the default AWS
+// credential chain. prefix namespaces keys under the bucket and may be empty.
+func NewS3Backend(bucket, prefix, region string) (*S3Backend, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 archive backend requires a bucket")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	return &S3Backend{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+// keyFor builds the yyyy/mm/dd/source/jobid.html.gz key for jobID, using
+// meta["source"] (falling back to "unknown") to group objects by ATS.
+func (b *S3Backend) keyFor(jobID string, meta map[string]string) string {
+	source := meta["source"]
+	if source == "" {
+		source = "unknown"
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%04d/%02d/%02d/%s/%s.html.gz", now.Year(), now.Month(), now.Day(), source, jobID)
+	if b.prefix != "" {
+		key = b.prefix + "/" + key
+	}
+	return key
+}
+
+// Store gzip-compresses html and uploads it with AES256 server-side
+// encryption.
+func (b *S3Backend) Store(jobID string, html []byte, meta map[string]string) (string, error) {
+	key := b.keyFor(jobID, meta)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(html); err != nil {
+		return "", fmt.Errorf("compressing %s: %w", key, err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("compressing %s: %w", key, err)
+	}
+
+	_, err := b.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:               aws.String(b.bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(buf.Bytes()),
+		ContentType:          aws.String("text/html"),
+		ContentEncoding:      aws.String("gzip"),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+	if err != nil {
+		return "", fmt.Errorf("uploading %s: %w", key, err)
+	}
+
+	return fmt.Sprintf("s3://%s/%s", b.bucket, key), nil
+}
+
+// Fetch downloads and decompresses the object at uri.
+func (b *S3Backend) Fetch(uri string) ([]byte, error) {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := b.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", uri, err)
+	}
+	defer out.Body.Close()
+
+	gz, err := gzip.NewReader(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing %s: %w", uri, err)
+	}
+	defer gz.Close()
+
+	return io.ReadAll(gz)
+}
+
+// Exists issues a HEAD request for the object at uri.
+func (b *S3Backend) Exists(uri string) bool {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return false
+	}
+
+	_, err = b.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err == nil
+}
+
+// CleanUp is a no-op: retention is handled by an S3 bucket lifecycle rule on
+// the yyyy/mm/dd prefix rather than per-object deletes from here.
+func (b *S3Backend) CleanUp(_ time.Time) error {
+	return nil
+}
+
+func parseS3URI(uri string) (bucket, key string, err error) {
+	const prefix = "s3://"
+	if !strings.HasPrefix(uri, prefix) {
+		return "", "", fmt.Errorf("not an s3:// uri: %q", uri)
+	}
+
+	rest := strings.TrimPrefix(uri, prefix)
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed s3 uri: %q", uri)
+	}
+
+	return parts[0], parts[1], nil
+}
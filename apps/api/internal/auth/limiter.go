@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRateLimitPerMin is the request budget a key gets when it has no
+// rate_limit_per_min override of its own.
+const DefaultRateLimitPerMin = 60
+
+// Limiter is a per-key token bucket. Each key refills continuously at
+// perMinute/60 tokens per second up to a burst of perMinute, the same shape
+// as a classic web API rate limit rather than a hard per-minute window, so
+// a key isn't stuck waiting for a wall-clock minute boundary to roll over.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewLimiter returns an empty Limiter.
+func NewLimiter() *Limiter {
+	return &Limiter{buckets: make(map[string]*bucket)}
+}
+
+// Allow reports whether keyID may make one more request right now, given
+// its perMinute budget, and consumes a token if so.
+func (l *Limiter) Allow(keyID string, perMinute int32) bool {
+	if perMinute <= 0 {
+		perMinute = DefaultRateLimitPerMin
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[keyID]
+	if !ok {
+		b = &bucket{tokens: float64(perMinute), lastRefill: now}
+		l.buckets[keyID] = b
+	}
+
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens += elapsed * float64(perMinute) / 60
+	if b.tokens > float64(perMinute) {
+		b.tokens = float64(perMinute)
+	}
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
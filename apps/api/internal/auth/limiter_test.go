@@ -0,0 +1,44 @@
+package auth
+
+import "testing"
+
+func TestLimiterAllowsUpToBurstThenBlocks(t *testing.T) {
+	l := NewLimiter()
+	const perMinute = 3
+
+	for i := 0; i < perMinute; i++ {
+		if !l.Allow("key-a", perMinute) {
+			t.Fatalf("Allow call %d/%d = false, want true (within burst)", i+1, perMinute)
+		}
+	}
+	if l.Allow("key-a", perMinute) {
+		t.Fatal("Allow after exhausting the burst = true, want false")
+	}
+}
+
+func TestLimiterZeroOrNegativePerMinuteFallsBackToDefault(t *testing.T) {
+	l := NewLimiter()
+	for i := 0; i < DefaultRateLimitPerMin; i++ {
+		if !l.Allow("key-b", 0) {
+			t.Fatalf("Allow call %d/%d with perMinute=0 = false, want true (DefaultRateLimitPerMin burst)", i+1, DefaultRateLimitPerMin)
+		}
+	}
+	if l.Allow("key-b", 0) {
+		t.Fatal("Allow after exhausting DefaultRateLimitPerMin burst = true, want false")
+	}
+}
+
+func TestLimiterTracksKeysIndependently(t *testing.T) {
+	l := NewLimiter()
+	const perMinute = 1
+
+	if !l.Allow("key-c", perMinute) {
+		t.Fatal("first Allow for key-c = false, want true")
+	}
+	if l.Allow("key-c", perMinute) {
+		t.Fatal("second Allow for key-c = true, want false (burst exhausted)")
+	}
+	if !l.Allow("key-d", perMinute) {
+		t.Fatal("first Allow for key-d = false, want true (should not share key-c's bucket)")
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/gofiber/fiber/v2"
+)
+
+// scopesLocalsKey is the fiber.Ctx locals key Middleware attaches a key's
+// scopes under, for handlers that want to branch on more than the single
+// scope their route already required.
+const scopesLocalsKey = "auth_scopes"
+
+// Middleware builds a Fiber handler that requires a valid, non-revoked,
+// non-expired API key carrying requiredScope, under a shared per-key rate
+// limit. Every route built from the same Middleware value shares limiter,
+// so a key's 60-req/min budget (or its own rate_limit_per_min override) is
+// spent across every endpoint it calls, not reset per route.
+func Middleware(conn clickhouse.Conn, limiter *Limiter, requiredScope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if conn == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "database connection not available",
+			})
+		}
+
+		header := c.Get("Authorization")
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || raw == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "missing or malformed Authorization header",
+			})
+		}
+
+		key, err := Lookup(context.Background(), conn, HashKey(raw))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to validate api key",
+			})
+		}
+		if key == nil || !key.Active(time.Now()) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "invalid or revoked api key",
+			})
+		}
+		if !key.HasScope(requiredScope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "api key missing required scope: " + requiredScope,
+			})
+		}
+
+		var perMinute int32
+		if key.RateLimitPerMin != nil {
+			perMinute = *key.RateLimitPerMin
+		}
+		if !limiter.Allow(key.ID, perMinute) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "rate limit exceeded",
+			})
+		}
+
+		c.Locals(scopesLocalsKey, key.Scopes)
+		return c.Next()
+	}
+}
+
+// Scopes returns the scopes Middleware attached to c, or nil if c wasn't
+// authenticated through it.
+func Scopes(c *fiber.Ctx) []string {
+	scopes, _ := c.Locals(scopesLocalsKey).([]string)
+	return scopes
+}
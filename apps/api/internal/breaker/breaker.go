@@ -0,0 +1,161 @@
+// Package breaker is a per-host circuit breaker for QuietHire's outbound
+// calls to its sidecar services (Crawler, Parser, OSINT): once a host
+// racks up enough consecutive failures, Allow stops letting calls through
+// at all until a cooldown elapses, at which point a single HalfOpen probe
+// decides whether to close again. Conceptually the same Closed/Open/
+// HalfOpen state machine as apps/proxy-manager's per-proxy breaker.go, but
+// in-process only (no persistence — a dead sidecar is worth re-probing
+// from scratch on worker restart) and keyed by host rather than by proxy.
+package breaker
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// State is one state in a Breaker.
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half_open"
+)
+
+// failThreshold is how many consecutive failures trip a Closed breaker to
+// Open.
+const failThreshold = 5
+
+// baseBackoff/maxBackoff bound how long an Open breaker waits before
+// allowing a HalfOpen probe: backoff doubles with every trip, capped at
+// maxBackoff so a long-dead sidecar still gets probed occasionally instead
+// of being abandoned forever.
+const (
+	baseBackoff = 5 * time.Second
+	maxBackoff  = 2 * time.Minute
+)
+
+// Breaker is one host's trip state. Zero value is a Closed breaker ready
+// to use.
+type Breaker struct {
+	mu          sync.Mutex
+	state       State
+	failures    int
+	trips       int
+	nextProbeAt time.Time
+	probing     bool
+}
+
+// Allow reports whether a call should be attempted right now. An Open
+// breaker whose backoff has elapsed transitions to HalfOpen in place and
+// claims its single probe slot; a HalfOpen breaker with a probe already in
+// flight refuses every other caller until that probe's outcome is
+// reported via RecordSuccess/RecordFailure.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Now().Before(b.nextProbeAt) {
+			return false
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// ReleaseProbe clears b's in-flight HalfOpen probe claim without counting
+// it as a success or failure — for a call that never actually completed
+// (its context was cancelled before getting a real response from the
+// host), so that call's claimed probe slot doesn't stay claimed forever
+// and wedge the breaker open for every later caller.
+func (b *Breaker) ReleaseProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// RecordSuccess closes b, the outcome of either a normal Closed-state call
+// or a successful HalfOpen probe, and resets its failure/trip counters.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.trips = 0
+	b.probing = false
+}
+
+// RecordFailure counts a failure, tripping b open once failThreshold
+// consecutive failures are reached. A failed HalfOpen probe re-opens
+// immediately with a doubled backoff, the same way a Closed breaker's
+// threshold-triggered trip does.
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if b.state == HalfOpen {
+		b.trip()
+		return
+	}
+
+	b.failures++
+	if b.failures >= failThreshold {
+		b.trip()
+	}
+}
+
+// trip must be called with b.mu held.
+func (b *Breaker) trip() {
+	b.state = Open
+	b.failures = 0
+
+	backoff := baseBackoff
+	for i := 0; i < b.trips && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/5 + 1))
+	b.nextProbeAt = time.Now().Add(backoff + jitter)
+	b.trips++
+}
+
+// Registry hands out one Breaker per host, creating it on first use.
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*Breaker)}
+}
+
+// For returns host's Breaker, creating a new Closed one the first time
+// host is seen.
+func (r *Registry) For(host string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[host]
+	if !ok {
+		b = &Breaker{}
+		r.breakers[host] = b
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package breaker
+
+import "testing"
+
+func TestBreakerZeroValueIsClosedAndAllows(t *testing.T) {
+	var b Breaker
+	if !b.Allow() {
+		t.Fatal("zero-value Breaker.Allow() = false, want true")
+	}
+}
+
+func TestBreakerTripsOpenAfterThreshold(t *testing.T) {
+	var b Breaker
+	for i := 0; i < failThreshold; i++ {
+		b.RecordFailure()
+	}
+	if b.Allow() {
+		t.Fatal("Breaker.Allow() = true after failThreshold consecutive failures, want false (Open)")
+	}
+}
+
+func TestBreakerStaysClosedBelowThreshold(t *testing.T) {
+	var b Breaker
+	for i := 0; i < failThreshold-1; i++ {
+		b.RecordFailure()
+	}
+	if !b.Allow() {
+		t.Fatal("Breaker.Allow() = false below failThreshold failures, want true (still Closed)")
+	}
+}
+
+func TestBreakerRecordSuccessResetsFailureCount(t *testing.T) {
+	var b Breaker
+	for i := 0; i < failThreshold-1; i++ {
+		b.RecordFailure()
+	}
+	b.RecordSuccess()
+	for i := 0; i < failThreshold-1; i++ {
+		b.RecordFailure()
+	}
+	if !b.Allow() {
+		t.Fatal("Breaker.Allow() = false after RecordSuccess reset the failure count, want true")
+	}
+}
+
+func TestBreakerOpenRefusesUntilBackoffElapses(t *testing.T) {
+	var b Breaker
+	for i := 0; i < failThreshold; i++ {
+		b.RecordFailure()
+	}
+	// nextProbeAt is in the future right after tripping, so an Allow call
+	// made right away shouldn't transition to HalfOpen yet.
+	if b.Allow() {
+		t.Fatal("Breaker.Allow() = true immediately after tripping Open, want false")
+	}
+	if b.state != Open {
+		t.Fatalf("Breaker.state = %v after tripping, want Open", b.state)
+	}
+}
+
+func TestBreakerHalfOpenAllowsOnlyOneProbeAtATime(t *testing.T) {
+	var b Breaker
+	b.mu.Lock()
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	if !b.Allow() {
+		t.Fatal("first Allow() on a HalfOpen breaker = false, want true (claims the probe)")
+	}
+	if b.Allow() {
+		t.Fatal("second Allow() on a HalfOpen breaker with a probe in flight = true, want false")
+	}
+}
+
+func TestBreakerReleaseProbeFreesTheSlot(t *testing.T) {
+	var b Breaker
+	b.mu.Lock()
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	if !b.Allow() {
+		t.Fatal("Allow() on HalfOpen breaker = false, want true")
+	}
+	b.ReleaseProbe()
+	if !b.Allow() {
+		t.Fatal("Allow() after ReleaseProbe = false, want true (probe slot freed)")
+	}
+}
+
+func TestBreakerFailedHalfOpenProbeReopensImmediately(t *testing.T) {
+	var b Breaker
+	b.mu.Lock()
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	b.RecordFailure()
+	if b.state != Open {
+		t.Fatalf("Breaker.state = %v after a failed HalfOpen probe, want Open", b.state)
+	}
+}
+
+func TestBreakerSuccessfulHalfOpenProbeCloses(t *testing.T) {
+	var b Breaker
+	b.mu.Lock()
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	b.RecordSuccess()
+	if b.state != Closed {
+		t.Fatalf("Breaker.state = %v after a successful HalfOpen probe, want Closed", b.state)
+	}
+}
+
+func TestRegistryForReturnsSameBreakerPerHost(t *testing.T) {
+	r := NewRegistry()
+	a := r.For("crawler.internal")
+	b := r.For("crawler.internal")
+	if a != b {
+		t.Error("Registry.For returned different *Breaker values for the same host")
+	}
+}
+
+func TestRegistryForIsolatesDifferentHosts(t *testing.T) {
+	r := NewRegistry()
+	a := r.For("crawler.internal")
+	b := r.For("osint.internal")
+	if a == b {
+		t.Fatal("Registry.For returned the same *Breaker for two different hosts")
+	}
+
+	for i := 0; i < failThreshold; i++ {
+		a.RecordFailure()
+	}
+	if a.Allow() {
+		t.Error("host a's breaker should be Open after tripping")
+	}
+	if !b.Allow() {
+		t.Error("host b's breaker tripped alongside host a's, want it unaffected")
+	}
+}
@@ -0,0 +1,208 @@
+// Package chbatch batches row inserts into ClickHouse so callers like
+// CrawlActivities don't pay one network round-trip per row — ClickHouse's
+// own docs call frequent small inserts an anti-pattern that wrecks
+// throughput at scale. A ClickHouseBatcher owns one buffered channel per
+// table; a background goroutine per table drains it into
+// PrepareBatch(...).Append(...).Send() whenever either FlushRows rows or
+// FlushInterval has elapsed, whichever comes first.
+package chbatch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Row is one buffered insert. Args are passed to Batch.Append in order, so
+// they must match Table's TableConfig.InsertQuery column list.
+type Row struct {
+	Table string
+	Args  []interface{}
+}
+
+// TableConfig describes one table's batched insert.
+type TableConfig struct {
+	// Table is the destination table name, and the Row.Table callers enqueue
+	// under.
+	Table string
+	// InsertQuery is passed to Conn.PrepareBatch verbatim, e.g.
+	// "INSERT INTO jobs_raw_html (id, url, html, status)".
+	InsertQuery string
+}
+
+// Config tunes a ClickHouseBatcher's flush behavior, shared across every
+// table it owns.
+type Config struct {
+	// FlushRows triggers a table's flush once its buffer reaches this many
+	// rows. <= 0 falls back to 1000.
+	FlushRows int
+	// FlushInterval is the period of a fixed ticker that flushes whatever a
+	// table has buffered on every tick, even if FlushRows hasn't been
+	// reached yet. <= 0 falls back to 500ms.
+	FlushInterval time.Duration
+	// QueueSize bounds how many rows a table's channel buffers before
+	// Enqueue blocks. <= 0 falls back to 10 * FlushRows.
+	QueueSize int
+	// FlushTimeout bounds each individual PrepareBatch+Send call, so a
+	// stuck ClickHouse connection can't wedge a flush goroutine (and, in
+	// turn, Stop) forever. <= 0 falls back to 10s.
+	FlushTimeout time.Duration
+}
+
+// ClickHouseBatcher buffers rows per table and flushes them to ClickHouse
+// in PrepareBatch-sized batches, one background goroutine per table,
+// started by Run and drained by Stop.
+type ClickHouseBatcher struct {
+	conn   clickhouse.Conn
+	cfg    Config
+	tables map[string]TableConfig
+	queues map[string]chan Row
+
+	wg sync.WaitGroup
+}
+
+// NewClickHouseBatcher builds a ClickHouseBatcher over conn for the given
+// tables, applying cfg's defaults. Call Run to start its flush goroutines.
+func NewClickHouseBatcher(conn clickhouse.Conn, cfg Config, tables ...TableConfig) *ClickHouseBatcher {
+	if cfg.FlushRows <= 0 {
+		cfg.FlushRows = 1000
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 500 * time.Millisecond
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = cfg.FlushRows * 10
+	}
+	if cfg.FlushTimeout <= 0 {
+		cfg.FlushTimeout = 10 * time.Second
+	}
+
+	b := &ClickHouseBatcher{
+		conn:   conn,
+		cfg:    cfg,
+		tables: make(map[string]TableConfig, len(tables)),
+		queues: make(map[string]chan Row, len(tables)),
+	}
+	for _, t := range tables {
+		b.tables[t.Table] = t
+		b.queues[t.Table] = make(chan Row, cfg.QueueSize)
+	}
+	return b
+}
+
+// Run starts one flush goroutine per registered table and returns
+// immediately. ctx only bounds the ClickHouse calls a flush makes, not the
+// goroutines' lifetime — call Stop to shut them down.
+func (b *ClickHouseBatcher) Run(ctx context.Context) {
+	for table := range b.tables {
+		b.wg.Add(1)
+		go b.flushLoop(ctx, table)
+	}
+}
+
+// Enqueue buffers row for its table's next flush. row.Table must be one
+// this ClickHouseBatcher was constructed with. If the table's queue is full
+// (ClickHouse has fallen behind), Enqueue blocks until it has room or ctx
+// is done, rather than indefinitely — letting a caller's own deadline (a
+// Temporal activity's, say) fail the call instead of wedging the worker.
+func (b *ClickHouseBatcher) Enqueue(ctx context.Context, row Row) error {
+	q, ok := b.queues[row.Table]
+	if !ok {
+		return fmt.Errorf("chbatch: no table registered for %q", row.Table)
+	}
+	select {
+	case q <- row:
+		rowsBuffered.WithLabelValues(row.Table).Inc()
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("chbatch: enqueuing row for %s: %w", row.Table, ctx.Err())
+	}
+}
+
+// Stop closes every table's queue and waits for its flush goroutine to
+// drain whatever's left, so a Temporal worker shutdown doesn't drop
+// buffered-but-unflushed rows. Blocks until every goroutine has returned.
+func (b *ClickHouseBatcher) Stop() {
+	for _, q := range b.queues {
+		close(q)
+	}
+	b.wg.Wait()
+}
+
+func (b *ClickHouseBatcher) flushLoop(ctx context.Context, table string) {
+	defer b.wg.Done()
+
+	q := b.queues[table]
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var buf []Row
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		if err := b.flush(ctx, table, buf); err != nil {
+			log.Printf("chbatch: flushing %d rows to %s: %v", len(buf), table, err)
+			flushErrors.WithLabelValues(table).Inc()
+		} else {
+			batchesFlushed.WithLabelValues(table).Inc()
+		}
+		rowsBuffered.WithLabelValues(table).Sub(float64(len(buf)))
+		buf = buf[:0]
+	}
+
+	for {
+		select {
+		case row, ok := <-q:
+			if !ok {
+				flush()
+				return
+			}
+			buf = append(buf, row)
+			if len(buf) >= b.cfg.FlushRows {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (b *ClickHouseBatcher) flush(ctx context.Context, table string, rows []Row) error {
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.FlushTimeout)
+	defer cancel()
+
+	batch, err := b.conn.PrepareBatch(ctx, b.tables[table].InsertQuery)
+	if err != nil {
+		return fmt.Errorf("preparing batch for %s: %w", table, err)
+	}
+
+	for _, row := range rows {
+		if err := batch.Append(row.Args...); err != nil {
+			return fmt.Errorf("appending row to %s batch: %w", table, err)
+		}
+	}
+
+	return batch.Send()
+}
+
+var (
+	rowsBuffered = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "chbatch_rows_buffered",
+		Help: "Rows currently queued for a table, enqueued but not yet flushed.",
+	}, []string{"table"})
+	batchesFlushed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "chbatch_batches_flushed_total",
+		Help: "Batches successfully sent to ClickHouse, per table.",
+	}, []string{"table"})
+	flushErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "chbatch_flush_errors_total",
+		Help: "Batch flushes that failed and were dropped, per table.",
+	}, []string{"table"})
+)
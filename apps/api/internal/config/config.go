@@ -19,10 +19,11 @@ type Config struct {
 
 // AppConfig contains application-level settings
 type AppConfig struct {
-	Name        string
-	Environment string
-	Port        string
-	LogLevel    string
+	Name         string
+	Environment  string
+	Port         string
+	LogLevel     string
+	AllowOrigins string
 }
 
 // DatabaseConfig contains PostgreSQL database settings
@@ -68,10 +69,11 @@ type ServicesConfig struct {
 func Load() (*Config, error) {
 	cfg := &Config{
 		App: AppConfig{
-			Name:        getEnv("APP_NAME", "QuietHire API"),
-			Environment: getEnv("ENV", "development"),
-			Port:        getEnv("API_PORT", "3000"),
-			LogLevel:    getEnv("LOG_LEVEL", "info"),
+			Name:         getEnv("APP_NAME", "QuietHire API"),
+			Environment:  getEnv("ENV", "development"),
+			Port:         getEnv("API_PORT", "3000"),
+			LogLevel:     getEnv("LOG_LEVEL", "info"),
+			AllowOrigins: getEnv("CORS_ALLOW_ORIGINS", "*"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResponseCache persists fetched page bodies to disk, keyed by a hash of
+// their URL, so repeated discovery runs during development re-parse
+// previously-fetched pages instead of re-fetching them over the network.
+// It implements http.RoundTripper so a Discoverer can plug it straight
+// into colly via Collector.WithTransport.
+type ResponseCache struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewResponseCache returns a ResponseCache rooted at dir (created if
+// missing) that falls back to next for any URL not already cached. A nil
+// next falls back to http.DefaultTransport.
+func NewResponseCache(dir string, next http.RoundTripper) (*ResponseCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating response cache dir %s: %w", dir, err)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &ResponseCache{dir: dir, next: next}, nil
+}
+
+func (c *ResponseCache) pathFor(rawURL string) string {
+	hash := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".html")
+}
+
+// RoundTrip serves req from disk when a prior response for its URL was
+// cached, otherwise delegates to next and caches a successful body before
+// returning it. A cache write failure is swallowed rather than returned —
+// caching is best-effort and must never fail a discovery run that
+// otherwise succeeded.
+func (c *ResponseCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := c.pathFor(req.URL.String())
+
+	if body, err := os.ReadFile(path); err == nil {
+		header := make(http.Header)
+		// colly only runs OnHTML callbacks when the response's
+		// Content-Type contains "html" — every cached page was itself
+		// only cached after a real, successful HTML fetch (see below), so
+		// it's safe to assume html here too.
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Status:        "200 OK (cached)",
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		_ = os.WriteFile(path, body, 0o644)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
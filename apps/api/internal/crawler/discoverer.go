@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// defaultUserAgent is sent with every request a Discoverer issues.
+const defaultUserAgent = "QuietHireCrawler/1.0 (+https://quiethire.example/bot)"
+
+// Config tunes a Discoverer's politeness and caching behavior.
+type Config struct {
+	// UserAgent is sent with every request colly issues. Empty falls back
+	// to defaultUserAgent.
+	UserAgent string
+	// QPS/Burst bound each host's discovery request rate independently
+	// (see HostRateLimiter). Robots.txt Crawl-delay raises the effective
+	// interval further when it's stricter than 1/QPS.
+	QPS   float64
+	Burst float64
+	// Parallelism bounds how many requests colly has in flight at once
+	// across all hosts. <= 0 falls back to 2.
+	Parallelism int
+	// CacheDir, set, persists fetched listing pages on disk keyed by URL
+	// hash so repeated discovery runs during development don't refetch
+	// them. Empty disables caching.
+	CacheDir string
+}
+
+// Discoverer discovers a platform's job-detail URLs by actually crawling
+// its search/listing pages with colly — following Selector.Pagination and
+// extracting Selector.DetailLink — rather than synthesizing URLs, honoring
+// a per-host rate limit and robots.txt along the way.
+type Discoverer struct {
+	cfg     Config
+	limiter *HostRateLimiter
+	robots  *robotsCache
+}
+
+// NewDiscoverer builds a Discoverer from cfg, applying its defaults.
+func NewDiscoverer(cfg Config) (*Discoverer, error) {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+	if cfg.QPS <= 0 {
+		cfg.QPS = 1
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 2
+	}
+
+	return &Discoverer{
+		cfg:     cfg,
+		limiter: NewHostRateLimiter(cfg.QPS, cfg.Burst),
+		robots:  newRobotsCache(cfg.UserAgent),
+	}, nil
+}
+
+// DiscoverURLs crawls seedURL — and, while sel.Pagination matches, every
+// subsequent listing page it links to — collecting every URL matched by
+// sel.DetailLink, honoring per-host rate limiting and robots.txt. Results
+// are deduplicated but otherwise returned in the order first seen.
+func (d *Discoverer) DiscoverURLs(ctx context.Context, seedURL string, sel Selector) ([]string, error) {
+	c := colly.NewCollector(colly.UserAgent(d.cfg.UserAgent), colly.Async(true))
+	if err := c.Limit(&colly.LimitRule{DomainGlob: "*", Parallelism: d.cfg.Parallelism}); err != nil {
+		return nil, fmt.Errorf("configuring crawl limits: %w", err)
+	}
+	if d.cfg.CacheDir != "" {
+		cache, err := NewResponseCache(d.cfg.CacheDir, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.WithTransport(cache)
+	}
+
+	// Guards detailURLs/seen/requestErr below: colly.Async(true) runs
+	// every OnRequest/OnHTML callback on its own goroutine per in-flight
+	// request, up to cfg.Parallelism at once.
+	var mu sync.Mutex
+	var detailURLs []string
+	seen := make(map[string]bool)
+	var requestErr error
+
+	c.OnRequest(func(r *colly.Request) {
+		rawURL := r.URL.String()
+		if !d.robots.Allowed(ctx, rawURL) {
+			r.Abort()
+			return
+		}
+		if err := d.limiter.Wait(ctx, rawURL, d.robots.CrawlDelay(ctx, rawURL)); err != nil {
+			mu.Lock()
+			requestErr = err
+			mu.Unlock()
+			r.Abort()
+		}
+	})
+
+	if sel.DetailLink != "" {
+		c.OnHTML(sel.DetailLink, func(e *colly.HTMLElement) {
+			href := e.Attr("href")
+			if href == "" {
+				return
+			}
+			absolute := e.Request.AbsoluteURL(href)
+			if absolute == "" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[absolute] {
+				return
+			}
+			seen[absolute] = true
+			detailURLs = append(detailURLs, absolute)
+		})
+	}
+	if sel.Pagination != "" {
+		c.OnHTML(sel.Pagination, func(e *colly.HTMLElement) {
+			href := e.Attr("href")
+			if href == "" {
+				return
+			}
+			var alreadyVisited *colly.AlreadyVisitedError
+			if err := e.Request.Visit(e.Request.AbsoluteURL(href)); err != nil && !errors.As(err, &alreadyVisited) {
+				mu.Lock()
+				if requestErr == nil {
+					requestErr = fmt.Errorf("following pagination link: %w", err)
+				}
+				mu.Unlock()
+			}
+		})
+	}
+
+	if err := c.Visit(seedURL); err != nil {
+		return nil, fmt.Errorf("discovering URLs from %s: %w", seedURL, err)
+	}
+	c.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requestErr != nil {
+		return nil, requestErr
+	}
+	return detailURLs, nil
+}
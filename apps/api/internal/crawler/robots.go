@@ -0,0 +1,175 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsCacheTTL bounds how long a parsed robots.txt is reused before
+// robotsCache refetches it, so a site that changes its rules doesn't stay
+// misconfigured here for the life of the process.
+const robotsCacheTTL = 24 * time.Hour
+
+// robotsRules is one host's parsed robots.txt: just enough to answer
+// Allowed and a Crawl-delay — longest-prefix Allow/Disallow matching under
+// the wildcard User-agent group, not a full RFC 9309 implementation.
+type robotsRules struct {
+	disallow   []string
+	allow      []string
+	crawlDelay time.Duration
+	fetchedAt  time.Time
+}
+
+// Allowed reports whether path is allowed, per the longest matching
+// Allow/Disallow prefix — the same precedence most crawlers give an
+// overlapping Allow/Disallow pair.
+func (r *robotsRules) Allowed(path string) bool {
+	bestAllow, bestDisallow := -1, -1
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) > bestAllow {
+			bestAllow = len(p)
+		}
+	}
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > bestDisallow {
+			bestDisallow = len(p)
+		}
+	}
+	return bestAllow >= bestDisallow
+}
+
+// robotsCache fetches and caches each host's robots.txt, so Discoverer
+// pays the fetch cost once per robotsCacheTTL rather than once per request.
+type robotsCache struct {
+	client    *http.Client
+	userAgent string
+
+	mu    sync.Mutex
+	rules map[string]*robotsRules
+}
+
+func newRobotsCache(userAgent string) *robotsCache {
+	return &robotsCache{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		userAgent: userAgent,
+		rules:     make(map[string]*robotsRules),
+	}
+}
+
+// Allowed reports whether rawURL is allowed by its host's robots.txt. A
+// robots.txt that can't be fetched at all is treated as allow-everything,
+// the conservative convention when a site doesn't publish one (or is
+// temporarily unreachable).
+func (c *robotsCache) Allowed(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return c.rulesFor(ctx, u).Allowed(u.Path)
+}
+
+// CrawlDelay returns rawURL's host's robots.txt Crawl-delay, or 0 if it
+// doesn't publish one.
+func (c *robotsCache) CrawlDelay(ctx context.Context, rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	return c.rulesFor(ctx, u).crawlDelay
+}
+
+func (c *robotsCache) rulesFor(ctx context.Context, u *url.URL) *robotsRules {
+	host := u.Host
+
+	c.mu.Lock()
+	cached, ok := c.rules[host]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < robotsCacheTTL {
+		return cached
+	}
+
+	rules := c.fetch(ctx, u)
+	c.mu.Lock()
+	c.rules[host] = rules
+	c.mu.Unlock()
+	return rules
+}
+
+func (c *robotsCache) fetch(ctx context.Context, u *url.URL) *robotsRules {
+	rules := &robotsRules{fetchedAt: time.Now()}
+
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", u.Scheme, u.Host)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return rules
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return rules
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("crawler: closing robots.txt response for %s: %v", u.Host, closeErr)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return rules
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return rules
+	}
+	parseRobots(rules, body)
+	return rules
+}
+
+// parseRobots fills rules from body, honoring only the "User-agent: *"
+// group — this crawler doesn't identify itself with a distinct UA that
+// sites give different rules to.
+func parseRobots(rules *robotsRules, body []byte) {
+	inWildcardGroup := false
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		directive := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch directive {
+		case "user-agent":
+			inWildcardGroup = value == "*"
+		case "disallow":
+			if inWildcardGroup && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		case "allow":
+			if inWildcardGroup && value != "" {
+				rules.allow = append(rules.allow, value)
+			}
+		case "crawl-delay":
+			if inWildcardGroup {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil {
+					rules.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+}
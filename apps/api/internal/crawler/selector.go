@@ -0,0 +1,35 @@
+// Package crawler discovers job listing URLs by actually crawling a
+// platform's search results pages with gocolly, rather than synthesizing
+// them — see Discoverer.
+package crawler
+
+// Selector describes how to discover one platform's job-detail URLs from
+// its search/listing pages: the CSS selector for an individual job's
+// detail-page link, and (optionally) the selector for a "next page" link
+// to follow for pagination.
+type Selector struct {
+	// DetailLink is the CSS selector matching an anchor that links to an
+	// individual job's detail page, scoped to one listing page.
+	DetailLink string
+	// Pagination is the CSS selector for the "next page" link on a
+	// listing page. Empty means the platform has no pagination to follow,
+	// so only the seed listing page is visited.
+	Pagination string
+}
+
+// PlatformSelectors are the built-in Selector configs DiscoverURLs picks
+// from by platform name. A platform absent here has no selector-driven
+// discovery configured.
+var PlatformSelectors = map[string]Selector{
+	"linkedin": {
+		DetailLink: "a.base-card__full-link",
+		Pagination: "button[aria-label='Next']",
+	},
+	"indeed": {
+		DetailLink: "a.jcs-JobTitle",
+		Pagination: "a[data-testid='pagination-page-next']",
+	},
+	"greenhouse": {
+		DetailLink: "div.opening a",
+	},
+}
@@ -0,0 +1,238 @@
+// Package crawlqueue lets discovery and crawl workers agree on queued career
+// pages through Postgres instead of a parent workflow spawning a child
+// CareerPageCrawlWorkflow directly. QueueURLsForCrawling (in the activities
+// package) does a plain INSERT into discovered_urls and a pg_notify; an
+// Acquirer running inside a separate crawl-worker process LISTENs on that
+// channel and claims matching rows with SELECT ... FOR UPDATE SKIP LOCKED,
+// falling back to a poll interval in case a notification is ever missed.
+// This decouples how fast discovery can enqueue URLs from how many crawl
+// workers happen to be online to drain them.
+package crawlqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// NotifyChannel is the Postgres NOTIFY channel QueueURLsForCrawling and
+// Acquirer share.
+const NotifyChannel = "discovered_urls_queue"
+
+// DefaultPollInterval is the fallback claim-attempt period used when no
+// NOTIFY arrives, so a missed notification (e.g. during listener reconnect)
+// never stalls a worker indefinitely.
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultDebounce is how long Acquirer waits after a NOTIFY for more of the
+// same burst before attempting a claim, so a thousand-URL discovery batch
+// wakes workers once instead of a thousand times.
+const DefaultDebounce = 200 * time.Millisecond
+
+// Job is one discovered_urls row claimed by an Acquirer.
+type Job struct {
+	ID          int64
+	URL         string
+	CompanyID   *int
+	ATSPlatform string
+	Priority    int
+	Confidence  float64
+	Tags        map[string]string
+}
+
+var (
+	queueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "crawlqueue_depth",
+		Help: "Rows in discovered_urls with status='pending', not yet claimed by an Acquirer.",
+	})
+	acquireLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "crawlqueue_acquire_latency_seconds",
+		Help:    "Time a successful Acquire call's claim query took.",
+		Buckets: prometheus.DefBuckets,
+	})
+	acquireConflicts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "crawlqueue_acquire_conflicts_total",
+		Help: "Acquire calls that found a matching pending row but lost the claim race to another Acquirer.",
+	})
+)
+
+// Acquirer claims discovered_urls rows matching a tag filter, waking on
+// Postgres NOTIFY instead of busy-polling. Workers call Acquire in a loop;
+// each call blocks until a matching row is claimed or ctx is done.
+type Acquirer struct {
+	db       *sql.DB
+	listener *pq.Listener
+
+	// PollInterval is the fallback claim-attempt period. <= 0 falls back to
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// Debounce coalesces a burst of NOTIFYs into one claim attempt. <= 0
+	// falls back to DefaultDebounce.
+	Debounce time.Duration
+}
+
+// NewAcquirer opens a dedicated LISTEN connection on NotifyChannel. connStr
+// must be the same DSN db was opened with: pq.Listener needs its own
+// connection, separate from db's pool, to receive NOTIFYs.
+func NewAcquirer(db *sql.DB, connStr string) *Acquirer {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("crawlqueue: listener event %v: %v", ev, err)
+		}
+	})
+	if err := listener.Listen(NotifyChannel); err != nil {
+		log.Printf("crawlqueue: failed to LISTEN on %s: %v", NotifyChannel, err)
+	}
+
+	return &Acquirer{db: db, listener: listener}
+}
+
+// Close releases the Acquirer's LISTEN connection.
+func (a *Acquirer) Close() error {
+	return a.listener.Close()
+}
+
+// Acquire blocks until a pending row whose tags satisfy every key/value in
+// want is claimed, or ctx is done. A nil or empty want matches any row.
+func (a *Acquirer) Acquire(ctx context.Context, want map[string]string) (*Job, error) {
+	pollInterval := a.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	debounce := a.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	for {
+		job, err := a.tryClaim(ctx, want)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			return job, nil
+		}
+
+		if err := a.waitForWork(ctx, pollInterval, debounce); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForWork blocks until the next NOTIFY (debounced) or pollInterval
+// elapses, whichever comes first.
+func (a *Acquirer) waitForWork(ctx context.Context, pollInterval, debounce time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case n := <-a.listener.Notify:
+		if n == nil {
+			// Listener reconnected; the next poll tick picks up anything
+			// notified during the gap.
+			return nil
+		}
+		return a.drainBurst(ctx, debounce)
+	case <-time.After(pollInterval):
+		return nil
+	}
+}
+
+// drainBurst keeps resetting debounce for as long as further NOTIFYs keep
+// arriving, so a burst of them collapses into a single wake-up.
+func (a *Acquirer) drainBurst(ctx context.Context, debounce time.Duration) error {
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-a.listener.Notify:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(debounce)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryClaim attempts to claim one pending row matching want, using
+// SELECT ... FOR UPDATE SKIP LOCKED so concurrent Acquirers never block on
+// or double-claim the same row. Returns (nil, nil) if nothing matched.
+func (a *Acquirer) tryClaim(ctx context.Context, want map[string]string) (*Job, error) {
+	started := time.Now()
+
+	if want == nil {
+		// A nil map marshals to JSON null, which no tags object contains.
+		want = map[string]string{}
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		return nil, fmt.Errorf("encoding tag filter: %w", err)
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var job Job
+	var tagsJSON []byte
+	err = tx.QueryRowContext(ctx, `
+		UPDATE discovered_urls
+		SET status = 'claimed', claimed_at = now()
+		WHERE id = (
+			SELECT id FROM discovered_urls
+			WHERE status = 'pending' AND tags @> $1::jsonb
+			ORDER BY priority DESC, confidence DESC, id ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, url, company_id, ats_platform, priority, confidence, tags
+	`, wantJSON).Scan(&job.ID, &job.URL, &job.CompanyID, &job.ATSPlatform, &job.Priority, &job.Confidence, &tagsJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.recordConflictIfContended(ctx, wantJSON)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claiming job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing claim: %w", err)
+	}
+
+	if len(tagsJSON) > 0 {
+		if err := json.Unmarshal(tagsJSON, &job.Tags); err != nil {
+			return nil, fmt.Errorf("decoding tags: %w", err)
+		}
+	}
+
+	acquireLatency.Observe(time.Since(started).Seconds())
+	queueDepth.Add(-1)
+	return &job, nil
+}
+
+// recordConflictIfContended increments acquireConflicts when a matching
+// pending row exists but every candidate was locked by another Acquirer
+// (SKIP LOCKED made this attempt see zero rows anyway), as opposed to the
+// queue genuinely having nothing to offer.
+func (a *Acquirer) recordConflictIfContended(ctx context.Context, wantJSON []byte) {
+	var contended bool
+	err := a.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM discovered_urls WHERE status = 'pending' AND tags @> $1::jsonb)
+	`, wantJSON).Scan(&contended)
+	if err == nil && contended {
+		acquireConflicts.Inc()
+	}
+}
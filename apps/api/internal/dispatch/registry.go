@@ -0,0 +1,81 @@
+// Package dispatch lets operators start one of the registered Temporal
+// workflows over HTTP instead of only from a cmd/* CLI, by name, with a
+// JSON body decoded into that workflow's input type.
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/workflows"
+	"go.temporal.io/sdk/client"
+)
+
+// TaskQueue is the single Temporal task queue every cmd/worker instance
+// polls, matching the literal string cmd/worker's worker.New call and
+// every other dispatcher (cmd/crawl-acquirer, cmd/schedule-discovery, ...)
+// already use.
+const TaskQueue = "job-crawl-queue"
+
+// entry pairs a registered workflow's function (for its name, via
+// client.ExecuteWorkflow's reflection-based lookup) with a constructor for
+// a fresh, zero-valued input struct to decode a dispatch request body into.
+type entry struct {
+	workflowFunc interface{}
+	newInput     func() interface{}
+}
+
+// Registry is the set of workflows this API exposes for manual dispatch.
+// Workflows not listed here (e.g. ScheduledCrawlWorkflow, which is meant to
+// be started on a schedule, not ad hoc) are intentionally not reachable
+// through this package.
+var registry = map[string]entry{
+	"CrawlCoordinatorWorkflow": {
+		workflowFunc: workflows.CrawlCoordinatorWorkflow,
+		newInput:     func() interface{} { return &workflows.JobCrawlInput{} },
+	},
+	"CareerPageCrawlWorkflow": {
+		workflowFunc: workflows.CareerPageCrawlWorkflow,
+		newInput:     func() interface{} { return &workflows.CareerPageCrawlInput{} },
+	},
+	// CompanyDiscoveryWorkflow's parameter is workflows.DiscoveryInput, not
+	// a CompanyDiscoveryInput type — there isn't one.
+	"CompanyDiscoveryWorkflow": {
+		workflowFunc: workflows.CompanyDiscoveryWorkflow,
+		newInput:     func() interface{} { return &workflows.DiscoveryInput{} },
+	},
+}
+
+// Known reports whether name is a registered workflow.
+func Known(name string) bool {
+	_, ok := registry[name]
+	return ok
+}
+
+// DecodeInput unmarshals body into the input type registered for name.
+func DecodeInput(name string, body []byte) (interface{}, error) {
+	e, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown workflow %q", name)
+	}
+	input := e.newInput()
+	if err := json.Unmarshal(body, input); err != nil {
+		return nil, fmt.Errorf("decoding input for %q: %w", name, err)
+	}
+	return input, nil
+}
+
+// Start executes the registered workflow under workflowID on TaskQueue,
+// returning its run. input should come from DecodeInput so its type
+// already matches what name expects.
+func Start(ctx context.Context, c client.Client, workflowID, name string, input interface{}) (client.WorkflowRun, error) {
+	e, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown workflow %q", name)
+	}
+	return c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: TaskQueue,
+	}, e.workflowFunc, input)
+}
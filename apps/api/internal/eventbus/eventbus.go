@@ -0,0 +1,54 @@
+// Package eventbus fans crawl lifecycle events out to a pubsub topic behind
+// a pluggable Publisher, so consumers (scoring, hiring-manager extraction,
+// notifications, ...) can react to a crawl in real time instead of polling
+// ClickHouse or waiting on workflow completion. This mirrors how Coder's
+// provisionerdserver uses database/pubsub to decouple job state from its
+// consumers.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Envelope is the stable shape every published event carries, regardless of
+// backend. Payload holds the event-specific fields (e.g. a job_extracted
+// event's parsed title/company).
+type Envelope struct {
+	EventID    string                 `json:"event_id"`
+	WorkflowID string                 `json:"workflow_id"`
+	RunID      string                 `json:"run_id"`
+	URL        string                 `json:"url"`
+	CompanyID  int                    `json:"company_id,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+}
+
+// Publisher fans an Envelope out to topic (e.g. "crawl.started",
+// "crawl.job_stored"). What a "topic" is (a NATS subject, a Redis stream
+// key, ...) is owned by the backend.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, envelope Envelope) error
+	Close() error
+}
+
+// Config selects and configures a Publisher, decoded from JSON like
+// {"kind":"redis","addr":"localhost:6379"}, the same shape archive.Config
+// uses for its own pluggable backend.
+type Config struct {
+	Kind string `json:"kind"` // "redis" or "nats"
+	Addr string `json:"addr"` // Redis address ("host:port") or NATS URL ("nats://host:port")
+}
+
+// New builds the Publisher selected by cfg.Kind.
+func New(cfg Config) (Publisher, error) {
+	switch cfg.Kind {
+	case "redis":
+		return NewRedisPublisher(cfg.Addr)
+	case "nats":
+		return NewNATSPublisher(cfg.Addr)
+	default:
+		return nil, fmt.Errorf("unknown eventbus backend kind: %q", cfg.Kind)
+	}
+}
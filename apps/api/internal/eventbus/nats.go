@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes to a NATS subject named after the topic.
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher connects to url (e.g. "nats://localhost:4222").
+func NewNATSPublisher(url string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to nats at %s: %w", url, err)
+	}
+	return &NATSPublisher{conn: conn}, nil
+}
+
+// Publish JSON-encodes envelope and publishes it on the subject topic.
+// ctx is accepted to satisfy Publisher; nats.Conn.Publish itself doesn't
+// take one.
+func (p *NATSPublisher) Publish(_ context.Context, topic string, envelope Envelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("encoding envelope for %s: %w", topic, err)
+	}
+
+	if err := p.conn.Publish(topic, body); err != nil {
+		return fmt.Errorf("publishing to nats subject %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close closes the underlying NATS connection without draining it first.
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package httpx is a shared client for QuietHire's outbound POSTs to its
+// Crawler and Parser sidecar services: every call goes through
+// retry.DefaultConfig's backoff schedule on 429/5xx/network errors,
+// honoring any Retry-After the sidecar sends, and is gated by a per-host
+// breaker.Breaker so a sidecar that's completely down fails fast with a
+// non-retryable Temporal ApplicationError instead of every in-flight
+// activity retrying it into the ground. See internal/activities/
+// osint_client.go for the same retry-and-backoff shape applied to the
+// OSINT service.
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/breaker"
+	"github.com/itisrohit/quiethire/apps/api/internal/retry"
+)
+
+// breakers is shared across every Client: CrawlActivities and
+// DiscoveryActivities each construct their own Client over the same
+// *http.Client's sidecar hosts, but a breaker trip for, say, the Parser
+// service should apply no matter which Client's caller noticed it first.
+var breakers = breaker.NewRegistry()
+
+// Client POSTs JSON bodies to a sidecar service and returns its response
+// body and status code, leaving response-shape decoding to the caller.
+type Client struct {
+	HTTP *http.Client
+}
+
+// New wraps httpClient.
+func New(httpClient *http.Client) *Client {
+	return &Client{HTTP: httpClient}
+}
+
+// Post sends body (already-marshaled JSON) to targetURL, retrying on
+// 429/5xx responses and network errors per retry.DefaultConfig (base
+// 200ms, doubling, capped at 30s, up to 5 attempts), honoring a
+// Retry-After header in place of that schedule's own delay. Once
+// targetURL's host breaker is open, Post fails immediately with a
+// non-retryable Temporal ApplicationError instead of making a call at all.
+func (c *Client) Post(ctx context.Context, targetURL string, body []byte) (respBody []byte, statusCode int, err error) {
+	return c.PostWithConfig(ctx, targetURL, body, retry.DefaultConfig())
+}
+
+// PostWithConfig is Post, but with the caller's own retry.Config instead of
+// retry.DefaultConfig — osintClient.post uses this to keep its
+// longer-established OSINT backoff schedule while still sharing Post's
+// breaker gating, Retry-After handling, and cancellation treatment. cfg's
+// IsRetryable and NextDelay are overwritten: every error is retryable up to
+// cfg.MaxAttempts, and NextDelay is how Retry-After response headers take
+// priority over the schedule's own delay.
+func (c *Client) PostWithConfig(ctx context.Context, targetURL string, body []byte, cfg retry.Config) (respBody []byte, statusCode int, err error) {
+	host, err := HostOf(targetURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b := breakers.For(host)
+	if !b.Allow() {
+		return nil, 0, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("circuit breaker open for %s, skipping call", host), "CircuitOpen", nil)
+	}
+
+	var retryAfter time.Duration
+
+	cfg.IsRetryable = func(error) bool { return true }
+	cfg.NextDelay = func(_ int, _ error) time.Duration { return retryAfter }
+
+	_, doErr := retry.Do(ctx, cfg, func() error {
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+		if reqErr != nil {
+			return fmt.Errorf("creating request for %s: %w", targetURL, reqErr)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, doErr := c.HTTP.Do(req)
+		if doErr != nil {
+			retryAfter = 0
+			return fmt.Errorf("calling %s: %w", targetURL, doErr)
+		}
+
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			retryAfter = 0
+			return fmt.Errorf("reading response from %s: %w", targetURL, readErr)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			retryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"))
+			return fmt.Errorf("%s returned status %d: %s", targetURL, resp.StatusCode, string(data))
+		}
+
+		respBody, statusCode = data, resp.StatusCode
+		return nil
+	})
+	if doErr != nil {
+		// A cancelled/expired ctx means retry.Do gave up without the
+		// sidecar ever actually failing a request, so it shouldn't count
+		// against the breaker — otherwise a handful of calls cancelled
+		// together (workflow cancellation, worker shutdown) could trip a
+		// healthy host's breaker for every other in-flight caller. Still
+		// release the probe slot Allow may have claimed, or a cancelled
+		// HalfOpen probe would wedge the breaker open forever.
+		if errors.Is(doErr, context.Canceled) || errors.Is(doErr, context.DeadlineExceeded) {
+			b.ReleaseProbe()
+		} else {
+			b.RecordFailure()
+		}
+		return nil, 0, doErr
+	}
+
+	b.RecordSuccess()
+	return respBody, statusCode, nil
+}
+
+// WrapErr wraps err with msg via fmt.Errorf's %w, unless err is already a
+// *temporal.ApplicationError (Post/PostWithConfig's circuit-breaker
+// rejection) — that type has to reach the Temporal SDK unwrapped, since
+// fmt.Errorf's %w wrapping changes err's dynamic type to *fmt.wrapError,
+// and the SDK's failure converter decides retryability with a type switch
+// rather than errors.As, so a wrapped ApplicationError silently loses its
+// NonRetryable flag and gets retried like any other error.
+func WrapErr(msg string, err error) error {
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// HostOf returns rawURL's host, the key a breaker.Registry tracks trips
+// under — shared by Client.Post and osintClient.post so both sidecar
+// clients key the same way.
+func HostOf(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing url %s: %w", rawURL, err)
+	}
+	return u.Host, nil
+}
+
+// ParseRetryAfter parses a Retry-After header, which may be either a number
+// of seconds or an HTTP date. It returns 0 if header is empty or
+// unparseable.
+func ParseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// Delta is what FetchDelta found since a watermark: jobs to upsert, job IDs
+// to delete from the index, and the new watermark to persist once both have
+// been applied successfully.
+type Delta struct {
+	Upserts   []Job
+	DeleteIDs []string
+	Watermark time.Time
+	// WatermarkIDs holds every job id seen at exactly Watermark (a
+	// second-precision timestamp) — pass it to the next FetchDelta call's
+	// sinceIDs so a job inserted later in the same wall-clock second as
+	// Watermark, but after this call already ran, isn't skipped forever by
+	// a plain "> watermark" comparison.
+	WatermarkIDs []string
+}
+
+// FetchDelta pulls every jobs row ClickHouse has touched at or after since,
+// splitting them into upserts and deletes by scoreThreshold, and separately
+// sweeps for rows that have aged out by maxAge — posted_at aging is a
+// passive clock effect, not a ClickHouse write, so it wouldn't show up in
+// an updated_at scan on its own.
+//
+// updated_at is a second-precision DateTime, so two polls can both observe
+// rows landing in the same wall-clock second: the first poll to run within
+// that second, and a row a concurrent crawl worker inserts immediately
+// after. Comparing "> since" alone would permanently drop the latter, since
+// it never again satisfies a strictly-greater comparison against that
+// second. Instead the query uses ">= since" and sinceIDs — the ids
+// FetchDelta previously returned in WatermarkIDs for that same second — to
+// skip only rows already delivered, not the whole second.
+//
+// The returned Watermark is the latest updated_at seen among the changed
+// rows; callers should persist both it and WatermarkIDs via SetWatermark
+// only after both Upserts and DeleteIDs have been applied.
+func FetchDelta(ctx context.Context, conn clickhouse.Conn, since time.Time, sinceIDs []string, scoreThreshold int32, maxAge time.Duration) (Delta, error) {
+	var delta Delta
+	watermark := since
+	watermarkIDs := append([]string(nil), sinceIDs...)
+
+	alreadySeen := make(map[string]bool, len(sinceIDs))
+	for _, id := range sinceIDs {
+		alreadySeen[id] = true
+	}
+
+	changedRows, err := conn.Query(ctx, `
+		SELECT
+			id, title, company, description, location, remote,
+			salary_min, salary_max, currency, job_type, experience_level,
+			real_score, hiring_manager_name, hiring_manager_email,
+			source_url, source_platform, tags,
+			toUnixTimestamp(posted_at) as posted_at,
+			toUnixTimestamp(updated_at) as updated_at,
+			updated_at
+		FROM jobs
+		WHERE updated_at >= ?
+		ORDER BY updated_at ASC
+	`, since)
+	if err != nil {
+		return delta, fmt.Errorf("querying changed jobs since %s: %w", since, err)
+	}
+	defer func() { _ = changedRows.Close() }()
+
+	for changedRows.Next() {
+		var job Job
+		var remote uint8
+		var postedAt, updatedAt uint32
+		var updatedAtTime time.Time
+
+		if scanErr := changedRows.Scan(
+			&job.ID, &job.Title, &job.Company, &job.Description, &job.Location, &remote,
+			&job.SalaryMin, &job.SalaryMax, &job.Currency, &job.JobType, &job.ExperienceLevel,
+			&job.RealScore, &job.HiringManagerName, &job.HiringManagerEmail,
+			&job.SourceURL, &job.SourcePlatform, &job.Tags,
+			&postedAt, &updatedAt, &updatedAtTime,
+		); scanErr != nil {
+			return delta, fmt.Errorf("scanning changed job row: %w", scanErr)
+		}
+
+		if updatedAtTime.Equal(since) && alreadySeen[job.ID] {
+			continue
+		}
+
+		switch {
+		case updatedAtTime.After(watermark):
+			watermark = updatedAtTime
+			watermarkIDs = []string{job.ID}
+		case updatedAtTime.Equal(watermark):
+			watermarkIDs = append(watermarkIDs, job.ID)
+		}
+
+		job.Remote = remote == 1
+		job.PostedAt = int64(postedAt)
+		job.UpdatedAt = int64(updatedAt)
+
+		if job.RealScore < scoreThreshold {
+			delta.DeleteIDs = append(delta.DeleteIDs, job.ID)
+			continue
+		}
+		delta.Upserts = append(delta.Upserts, job)
+	}
+	if err := changedRows.Err(); err != nil {
+		return delta, fmt.Errorf("iterating changed jobs: %w", err)
+	}
+
+	agedOutIDs, err := fetchAgedOutIDs(ctx, conn, maxAge)
+	if err != nil {
+		return delta, err
+	}
+	delta.DeleteIDs = append(delta.DeleteIDs, agedOutIDs...)
+
+	delta.Watermark = watermark
+	delta.WatermarkIDs = watermarkIDs
+	return delta, nil
+}
+
+// fetchAgedOutIDs returns IDs of jobs whose posted_at is older than maxAge,
+// regardless of whether they were part of this run's updated_at delta.
+func fetchAgedOutIDs(ctx context.Context, conn clickhouse.Conn, maxAge time.Duration) ([]string, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT id FROM jobs
+		WHERE posted_at < now() - INTERVAL ? SECOND
+	`, int64(maxAge.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("querying aged-out jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if scanErr := rows.Scan(&id); scanErr != nil {
+			return nil, fmt.Errorf("scanning aged-out job id: %w", scanErr)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating aged-out jobs: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,214 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/retry"
+	"github.com/typesense/typesense-go/typesense"
+	"github.com/typesense/typesense-go/typesense/api"
+	"github.com/typesense/typesense-go/typesense/api/pointer"
+)
+
+// BatchSize is how many documents Importer.Upsert sends to Typesense per
+// ImportJsonl call.
+const BatchSize = 40
+
+// Importer batches Job upserts and deletes against a single Typesense
+// collection. Collection can be a concrete "jobs_<ts>" name or the
+// searchindex.JobsAlias name — Client.Collection() resolves both the same
+// way, so callers don't need to know which they're holding.
+type Importer struct {
+	Client     *typesense.Client
+	Collection string
+}
+
+// NewImporter returns an Importer targeting the given Typesense collection
+// or alias name.
+func NewImporter(client *typesense.Client, collection string) *Importer {
+	return &Importer{Client: client, Collection: collection}
+}
+
+// Upsert imports jobs in batches of BatchSize, returning how many documents
+// Typesense accepted, how many it still rejected after retrying, and how
+// many retries it took across every batch. A batch-level transport error
+// that exhausts its retries stops the loop and is returned as err, with
+// indexed/errored/retries reflecting progress made before it hit.
+func (im *Importer) Upsert(ctx context.Context, jobs []Job) (indexed, errored, retries int, err error) {
+	for i := 0; i < len(jobs); i += BatchSize {
+		end := i + BatchSize
+		if end > len(jobs) {
+			end = len(jobs)
+		}
+
+		batchIndexed, batchErrored, batchRetries, batchErr := im.upsertBatch(ctx, jobs[i:end])
+		indexed += batchIndexed
+		errored += batchErrored
+		retries += batchRetries
+		if batchErr != nil {
+			return indexed, errored, retries, batchErr
+		}
+	}
+	return indexed, errored, retries, nil
+}
+
+// upsertBatch imports batch, retrying the whole call with backoff on
+// transport errors and HTTP 429/5xx responses, then — for any documents
+// Typesense accepted the request but rejected individually — re-batches
+// just the still-failing documents and retries those on the same backoff
+// schedule, so a handful of bad rows in a batch doesn't sink the rest of it.
+func (im *Importer) upsertBatch(ctx context.Context, batch []Job) (indexed, errored, retries int, err error) {
+	cfg := retry.DefaultConfig()
+	cfg.IsRetryable = isRetryableTypesenseErr
+
+	var results []bool
+	netRetries, importErr := retry.Do(ctx, cfg, func() error {
+		r, callErr := im.importCall(ctx, batch)
+		if callErr != nil {
+			return callErr
+		}
+		results = r
+		return nil
+	})
+	retries += netRetries
+	if importErr != nil {
+		return 0, len(batch), retries, fmt.Errorf("importing batch to %s: %w", im.Collection, importErr)
+	}
+
+	indexed, failed := splitByResult(batch, results)
+
+	if len(failed) > 0 {
+		docCfg := retry.DefaultConfig()
+		docRetries, _ := retry.Do(ctx, docCfg, func() error {
+			r, callErr := im.importCall(ctx, failed)
+			if callErr != nil {
+				return callErr
+			}
+
+			stillIndexed, stillFailed := splitByResult(failed, r)
+			indexed += stillIndexed
+
+			if len(stillFailed) == len(failed) {
+				// No progress this round — these documents are rejecting for
+				// a reason backoff won't fix (bad data, schema mismatch), so
+				// stop burning retries on them rather than looping to
+				// docCfg.MaxAttempts.
+				failed = stillFailed
+				return nil
+			}
+
+			failed = stillFailed
+			if len(failed) == 0 {
+				return nil
+			}
+			return fmt.Errorf("%d documents still rejected", len(failed))
+		})
+		retries += docRetries
+	}
+
+	for _, job := range failed {
+		log.Printf("   ⚠️  Document %s rejected after retries", job.ID)
+	}
+
+	return indexed, len(failed), retries, nil
+}
+
+// importCall does a single ImportJsonl round-trip for jobs and returns,
+// for each job in order, whether Typesense accepted it.
+func (im *Importer) importCall(ctx context.Context, jobs []Job) ([]bool, error) {
+	var buf bytes.Buffer
+	results := make([]bool, len(jobs))
+	for i, job := range jobs {
+		jobJSON, marshalErr := json.Marshal(job)
+		if marshalErr != nil {
+			log.Printf("⚠️  JSON marshal error: %v", marshalErr)
+			results[i] = false
+			continue
+		}
+		buf.Write(jobJSON)
+		buf.WriteString("\n")
+	}
+
+	action := "upsert"
+	params := &api.ImportDocumentsParams{
+		Action:    &action,
+		BatchSize: pointer.Int(BatchSize),
+	}
+
+	resp, importErr := im.Client.Collection(im.Collection).Documents().ImportJsonl(ctx, bytes.NewReader(buf.Bytes()), params)
+	if importErr != nil {
+		return nil, importErr
+	}
+	defer func() {
+		if closeErr := resp.Close(); closeErr != nil {
+			log.Printf("Warning: Failed to close response: %v", closeErr)
+		}
+	}()
+
+	var respBuf bytes.Buffer
+	if _, readErr := respBuf.ReadFrom(resp); readErr != nil {
+		return nil, fmt.Errorf("reading import response: %w", readErr)
+	}
+
+	// Response is JSONL with one result per input document, in order.
+	lines := strings.Split(strings.TrimSpace(respBuf.String()), "\n")
+	for i, line := range lines {
+		if i >= len(results) || line == "" {
+			continue
+		}
+		var res map[string]interface{}
+		if unmarshalErr := json.Unmarshal([]byte(line), &res); unmarshalErr != nil {
+			log.Printf("⚠️  Parse error: %v", unmarshalErr)
+			continue
+		}
+		if success, ok := res["success"].(bool); ok && success {
+			results[i] = true
+		} else if errMsg, ok := res["error"].(string); ok {
+			log.Printf("   ⚠️  Document error: %s", errMsg)
+		}
+	}
+
+	return results, nil
+}
+
+// splitByResult divides jobs into the ones results marks successful and the
+// ones it doesn't (or that importCall didn't get a result line for at all).
+func splitByResult(jobs []Job, results []bool) (indexed int, failed []Job) {
+	for i, job := range jobs {
+		if i < len(results) && results[i] {
+			indexed++
+			continue
+		}
+		failed = append(failed, job)
+	}
+	return indexed, failed
+}
+
+// isRetryableTypesenseErr reports whether err is worth retrying: a 429 or
+// 5xx HTTPError from Typesense, or a transport-level error (anything that
+// isn't an HTTPError at all, since ImportJsonl only returns one when it got
+// a non-200 response).
+func isRetryableTypesenseErr(err error) bool {
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status == http.StatusTooManyRequests || httpErr.Status >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// Delete removes a document by ID — used to expire jobs whose real_score
+// has dropped below the serving threshold or whose posted_at has aged out,
+// neither of which an upsert can express since the row itself may still
+// exist in ClickHouse.
+func (im *Importer) Delete(ctx context.Context, id string) error {
+	if _, err := im.Client.Collection(im.Collection).Document(id).Delete(ctx); err != nil {
+		return fmt.Errorf("deleting document %s from %s: %w", id, im.Collection, err)
+	}
+	return nil
+}
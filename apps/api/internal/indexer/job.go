@@ -0,0 +1,35 @@
+// Package indexer holds what cmd/index-jobs needs to get rows out of
+// ClickHouse and into Typesense across all three indexing modes it
+// supports: a full reindex into a fresh collection, a one-shot delta pull
+// of everything changed since the last run, and a long-running daemon that
+// keeps pulling deltas on an interval. The Job shape, batched JSONL
+// upsert/delete against Typesense, and the updated_at watermark that the
+// delta and daemon modes use to avoid re-scanning rows they've already
+// shipped all live here so cmd/index-jobs stays a thin CLI wrapper.
+package indexer
+
+// Job mirrors a row of the ClickHouse jobs table, shaped for Typesense's
+// JSONL import/delete format.
+//
+//nolint:govet // Field order optimized for readability over memory alignment
+type Job struct {
+	ID                 string   `json:"id"`
+	Title              string   `json:"title"`
+	Company            string   `json:"company"`
+	Description        string   `json:"description"`
+	Location           string   `json:"location"`
+	JobType            string   `json:"job_type"`
+	SourceURL          string   `json:"source_url"`
+	SourcePlatform     string   `json:"source_platform"`
+	Tags               []string `json:"tags,omitempty"`
+	PostedAt           int64    `json:"posted_at"`
+	UpdatedAt          int64    `json:"updated_at"`
+	RealScore          int32    `json:"real_score"`
+	SalaryMin          *int32   `json:"salary_min,omitempty"`
+	SalaryMax          *int32   `json:"salary_max,omitempty"`
+	Currency           *string  `json:"currency,omitempty"`
+	ExperienceLevel    *string  `json:"experience_level,omitempty"`
+	HiringManagerName  *string  `json:"hiring_manager_name,omitempty"`
+	HiringManagerEmail *string  `json:"hiring_manager_email,omitempty"`
+	Remote             bool     `json:"remote"`
+}
@@ -0,0 +1,13 @@
+package indexer
+
+import "time"
+
+// DefaultScoreThreshold and DefaultMaxAge define what counts as an "active"
+// job that delta indexing (and anything else watching for job changes)
+// should keep in Typesense: real_score at or above the threshold and
+// posted_at within maxAge — the same bounds cmd/api's /stats endpoint
+// already uses to define "active".
+const (
+	DefaultScoreThreshold = 70
+	DefaultMaxAge         = 90 * 24 * time.Hour
+)
@@ -0,0 +1,70 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// EnsureStateTable creates indexer_state if it doesn't already exist.
+// ReplacingMergeTree keyed on collection gives the same last-write-wins
+// dedup behavior the jobs table itself relies on, so repeated SetWatermark
+// calls for the same collection collapse down to one row on background
+// merge rather than needing an explicit UPDATE. last_ids holds every job id
+// FetchDelta saw at exactly last_updated_at (a second-precision DateTime),
+// so a later FetchDelta call can tell those rows apart from new ones that
+// land in the same wall-clock second — see FetchDelta's sinceIDs parameter.
+func EnsureStateTable(ctx context.Context, conn clickhouse.Conn) error {
+	err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS indexer_state (
+			collection String,
+			last_updated_at DateTime,
+			last_ids Array(String)
+		) ENGINE = ReplacingMergeTree
+		ORDER BY collection
+	`)
+	if err != nil {
+		return fmt.Errorf("creating indexer_state table: %w", err)
+	}
+	return nil
+}
+
+// GetWatermark returns the last_updated_at delta indexing left off at for
+// collection, and the ids of every job already seen at that exact second
+// (pass both straight through to FetchDelta), or the zero time and a nil
+// slice if nothing has been recorded yet — callers should treat a zero
+// watermark as "index everything."
+func GetWatermark(ctx context.Context, conn clickhouse.Conn, collection string) (time.Time, []string, error) {
+	var watermark time.Time
+	var ids []string
+	row := conn.QueryRow(ctx, `
+		SELECT last_updated_at, last_ids FROM indexer_state
+		WHERE collection = ?
+		ORDER BY last_updated_at DESC
+		LIMIT 1
+	`, collection)
+
+	if err := row.Scan(&watermark, &ids); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil, nil
+		}
+		return time.Time{}, nil, fmt.Errorf("reading watermark for %s: %w", collection, err)
+	}
+	return watermark, ids, nil
+}
+
+// SetWatermark records watermark as the new high-water mark for collection,
+// alongside ids, the job ids FetchDelta found at exactly that second.
+func SetWatermark(ctx context.Context, conn clickhouse.Conn, collection string, watermark time.Time, ids []string) error {
+	err := conn.Exec(ctx, `
+		INSERT INTO indexer_state (collection, last_updated_at, last_ids) VALUES (?, ?, ?)
+	`, collection, watermark, ids)
+	if err != nil {
+		return fmt.Errorf("recording watermark for %s: %w", collection, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package jobstream
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/indexer"
+	"github.com/itisrohit/quiethire/apps/api/internal/searchindex"
+)
+
+// Matches reports whether job satisfies every non-empty field of p, using
+// the same semantics searchindex.BuildFilterBy compiles into a Typesense
+// filter_by expression except for Tags: Matches requires every listed tag,
+// while BuildFilterBy's tags:=[...] clause matches a job carrying any one of
+// them. It is applied here directly against a Job struct, since
+// SSE subscribers receive jobs straight from the Hub rather than querying
+// Typesense. Callers should validate p with BuildFilterBy first so a
+// malformed query parameter still gets a 400 instead of silently matching
+// nothing.
+func Matches(p searchindex.FilterParams, job indexer.Job) bool {
+	if p.Remote != "" && job.Remote != (p.Remote == "true") {
+		return false
+	}
+
+	if p.ExperienceLevel != "" {
+		if job.ExperienceLevel == nil || *job.ExperienceLevel != p.ExperienceLevel {
+			return false
+		}
+	}
+
+	if p.JobType != "" && job.JobType != p.JobType {
+		return false
+	}
+
+	if p.SourcePlatform != "" && job.SourcePlatform != p.SourcePlatform {
+		return false
+	}
+
+	if p.Tags != "" {
+		for _, want := range strings.Split(p.Tags, ",") {
+			if !hasTag(job.Tags, want) {
+				return false
+			}
+		}
+	}
+
+	if p.SalaryMin != "" {
+		min, err := strconv.Atoi(p.SalaryMin)
+		if err != nil || job.SalaryMin == nil || int(*job.SalaryMin) < min {
+			return false
+		}
+	}
+
+	if p.PostedAfter != "" {
+		after, err := strconv.ParseInt(p.PostedAfter, 10, 64)
+		if err != nil || job.PostedAt <= after {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasTag(tags []string, want string) bool {
+	for _, t := range tags {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
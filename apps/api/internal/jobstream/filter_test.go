@@ -0,0 +1,96 @@
+package jobstream
+
+import (
+	"testing"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/indexer"
+	"github.com/itisrohit/quiethire/apps/api/internal/searchindex"
+)
+
+func strPtr(s string) *string { return &s }
+func i32Ptr(v int32) *int32   { return &v }
+
+func TestMatchesEmptyFilterMatchesAnyJob(t *testing.T) {
+	job := indexer.Job{ID: "1", JobType: "full_time"}
+	if !Matches(searchindex.FilterParams{}, job) {
+		t.Error("Matches with an empty FilterParams = false, want true")
+	}
+}
+
+func TestMatchesRemote(t *testing.T) {
+	remoteJob := indexer.Job{Remote: true}
+	onsiteJob := indexer.Job{Remote: false}
+
+	if !Matches(searchindex.FilterParams{Remote: "true"}, remoteJob) {
+		t.Error("Matches(remote=true) = false for a remote job, want true")
+	}
+	if Matches(searchindex.FilterParams{Remote: "true"}, onsiteJob) {
+		t.Error("Matches(remote=true) = true for an onsite job, want false")
+	}
+}
+
+func TestMatchesExperienceLevel(t *testing.T) {
+	p := searchindex.FilterParams{ExperienceLevel: "senior"}
+
+	if Matches(p, indexer.Job{ExperienceLevel: nil}) {
+		t.Error("Matches matched a job with no ExperienceLevel set, want false")
+	}
+	if Matches(p, indexer.Job{ExperienceLevel: strPtr("junior")}) {
+		t.Error("Matches matched a job with a different ExperienceLevel, want false")
+	}
+	if !Matches(p, indexer.Job{ExperienceLevel: strPtr("senior")}) {
+		t.Error("Matches did not match a job with the requested ExperienceLevel, want true")
+	}
+}
+
+func TestMatchesJobTypeAndSourcePlatform(t *testing.T) {
+	p := searchindex.FilterParams{JobType: "contract", SourcePlatform: "greenhouse"}
+	match := indexer.Job{JobType: "contract", SourcePlatform: "greenhouse"}
+	mismatch := indexer.Job{JobType: "full_time", SourcePlatform: "greenhouse"}
+
+	if !Matches(p, match) {
+		t.Error("Matches did not match a job satisfying both JobType and SourcePlatform")
+	}
+	if Matches(p, mismatch) {
+		t.Error("Matches matched a job with a mismatched JobType")
+	}
+}
+
+func TestMatchesTagsRequiresEveryRequestedTag(t *testing.T) {
+	p := searchindex.FilterParams{Tags: "go,remote"}
+
+	if !Matches(p, indexer.Job{Tags: []string{"go", "remote", "backend"}}) {
+		t.Error("Matches did not match a job with all requested tags present (plus extras)")
+	}
+	if Matches(p, indexer.Job{Tags: []string{"go"}}) {
+		t.Error("Matches matched a job missing one of the requested tags")
+	}
+}
+
+func TestMatchesSalaryMin(t *testing.T) {
+	p := searchindex.FilterParams{SalaryMin: "100000"}
+
+	if !Matches(p, indexer.Job{SalaryMin: i32Ptr(120000)}) {
+		t.Error("Matches did not match a job whose SalaryMin exceeds the requested floor")
+	}
+	if Matches(p, indexer.Job{SalaryMin: i32Ptr(50000)}) {
+		t.Error("Matches matched a job whose SalaryMin is below the requested floor")
+	}
+	if Matches(p, indexer.Job{SalaryMin: nil}) {
+		t.Error("Matches matched a job with no SalaryMin against a SalaryMin filter")
+	}
+}
+
+func TestMatchesPostedAfter(t *testing.T) {
+	p := searchindex.FilterParams{PostedAfter: "1000"}
+
+	if !Matches(p, indexer.Job{PostedAt: 2000}) {
+		t.Error("Matches did not match a job posted after the requested timestamp")
+	}
+	if Matches(p, indexer.Job{PostedAt: 500}) {
+		t.Error("Matches matched a job posted before the requested timestamp")
+	}
+	if Matches(p, indexer.Job{PostedAt: 1000}) {
+		t.Error("Matches matched a job posted exactly at PostedAfter, want strictly after")
+	}
+}
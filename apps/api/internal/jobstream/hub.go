@@ -0,0 +1,124 @@
+// Package jobstream is an in-process pub/sub hub that fans newly-upserted
+// jobs out to every connected SSE client on GET /api/v1/jobs/stream.
+//
+// It necessarily lives inside cmd/api's process: cmd/index-jobs --daemon
+// runs as a separate OS process, and a Go channel can't cross that boundary.
+// Instead, Hub.Watch polls ClickHouse the same way cmd/index-jobs --daemon
+// does — reusing indexer.FetchDelta and the indexer_state watermark table —
+// under its own watermark key, and Publishes whatever it finds to every
+// subscriber. This keeps the in-process fan-out the streaming endpoint
+// needs without requiring the indexer daemon and the API server to be the
+// same process.
+package jobstream
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	clickhouse "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/itisrohit/quiethire/apps/api/internal/indexer"
+)
+
+// subscriberBuffer is how many pending jobs a slow subscriber can queue
+// before Publish starts dropping events for it rather than blocking every
+// other subscriber.
+const subscriberBuffer = 64
+
+// streamWatermarkKey is the indexer_state row Watch advances. It's distinct
+// from searchindex.JobsAlias so watching the live stream never perturbs
+// cmd/index-jobs --daemon's own delta-indexing watermark.
+const streamWatermarkKey = "jobs-stream"
+
+// Hub fans Job values out to every currently-subscribed channel.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan indexer.Job]bool
+}
+
+// NewHub returns an empty Hub ready to Publish and Subscribe.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[chan indexer.Job]bool)}
+}
+
+// Subscribe registers a new subscriber and returns its channel along with
+// an unsubscribe func the caller must call exactly once (typically deferred)
+// when it stops reading from ch.
+func (h *Hub) Subscribe() (ch chan indexer.Job, unsubscribe func()) {
+	ch = make(chan indexer.Job, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = true
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish fans job out to every subscriber. A subscriber whose buffer is
+// already full has the event dropped for it rather than blocking Publish —
+// a client that falls behind should reconnect with ?since= to catch up from
+// ClickHouse, not stall every other subscriber.
+func (h *Hub) Publish(job indexer.Job) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+// Watch polls conn for jobs changed since the last streamWatermarkKey
+// watermark every interval and Publishes each one, until ctx is canceled.
+func (h *Hub) Watch(ctx context.Context, conn clickhouse.Conn, interval time.Duration) {
+	if err := indexer.EnsureStateTable(ctx, conn); err != nil {
+		log.Printf("jobstream: could not ensure indexer_state table: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.pollOnce(ctx, conn)
+		}
+	}
+}
+
+func (h *Hub) pollOnce(ctx context.Context, conn clickhouse.Conn) {
+	watermark, watermarkIDs, err := indexer.GetWatermark(ctx, conn, streamWatermarkKey)
+	if err != nil {
+		log.Printf("jobstream: reading watermark: %v", err)
+		return
+	}
+
+	delta, err := indexer.FetchDelta(ctx, conn, watermark, watermarkIDs, indexer.DefaultScoreThreshold, indexer.DefaultMaxAge)
+	if err != nil {
+		log.Printf("jobstream: fetching delta: %v", err)
+		return
+	}
+
+	for _, job := range delta.Upserts {
+		h.Publish(job)
+	}
+
+	if !delta.Watermark.IsZero() {
+		if err := indexer.SetWatermark(ctx, conn, streamWatermarkKey, delta.Watermark, delta.WatermarkIDs); err != nil {
+			log.Printf("jobstream: setting watermark: %v", err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package parsers is a local-first alternative to always calling out to the
+// Python Parser service: a small Registry of Parser implementations that
+// extract a JobListing directly from already-fetched HTML, tried before
+// ParseJobActivity falls back to the remote service.
+package parsers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// JobListing is a parsed job posting, shaped to match the fields
+// CrawlActivities.storeParsedJob already reads off the Python service's
+// response map, so a local Parser's result can be stored the same way.
+type JobListing struct {
+	Title          string
+	Company        string
+	Description    string
+	Location       string
+	SourcePlatform string
+	JobType        string
+	// PostedAt/UpdatedAt are RFC3339, empty when the source page doesn't
+	// say — storeParsedJob already defaults a missing value to time.Now().
+	PostedAt  string
+	UpdatedAt string
+	Remote    bool
+
+	SalaryMin *float64
+	SalaryMax *float64
+	Currency  string
+}
+
+// ToMap converts listing into the map[string]interface{} shape
+// ParseJobActivity returns regardless of which Parser (local or remote
+// Python service) produced it, and that storeParsedJob reads fields from.
+// sourceURL is filled in by the caller since a JobListing doesn't carry its
+// own URL.
+func (l *JobListing) ToMap(sourceURL string) map[string]interface{} {
+	return map[string]interface{}{
+		"title":           l.Title,
+		"company":         l.Company,
+		"description":     l.Description,
+		"location":        l.Location,
+		"source_url":      sourceURL,
+		"source_platform": l.SourcePlatform,
+		"job_type":        l.JobType,
+		"posted_at":       l.PostedAt,
+		"updated_at":      l.UpdatedAt,
+		"remote":          l.Remote,
+		"salary_min":      l.SalaryMin,
+		"salary_max":      l.SalaryMax,
+		"currency":        l.Currency,
+	}
+}
+
+// Parser extracts a JobListing from a job posting's already-fetched HTML.
+type Parser interface {
+	CanParse(url string) bool
+	Parse(html string, url string) (*JobListing, error)
+}
+
+// Factory constructs a new Parser instance. Factories are invoked fresh for
+// each Resolve call so parsers may hold per-parse state without leaking
+// between callers.
+type Factory func() Parser
+
+// registration describes a registered parser's dispatch precedence.
+type registration struct {
+	name     string
+	priority int
+	factory  Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*registration{}
+)
+
+// Register adds a parser factory to the registry under name. When multiple
+// registered parsers' CanParse matches the same URL, Resolve prefers the
+// one with the higher priority. Intended to be called from a parser
+// package's init(), mirroring apps/crawler-go/parsers.Register.
+func Register(name string, priority int, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = &registration{name: name, priority: priority, factory: factory}
+}
+
+// Resolve returns the highest-priority registered parser whose CanParse
+// matches url. Ties are broken by name for determinism. Returns an error
+// when nothing matches, so ParseJobActivity can fall back to the Python
+// Parser service.
+func Resolve(url string) (Parser, error) {
+	for _, reg := range sortedRegistrations() {
+		p := reg.factory()
+		if p.CanParse(url) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no local parser registered for url: %s", url)
+}
+
+func sortedRegistrations() []*registration {
+	registryMu.RLock()
+	out := make([]*registration, 0, len(registry))
+	for _, r := range registry {
+		out = append(out, r)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].priority != out[j].priority {
+			return out[i].priority > out[j].priority
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
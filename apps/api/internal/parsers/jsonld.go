@@ -0,0 +1,276 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// schemaOrgPriority is lower than every host-specific parser's priority, so
+// Resolve only falls back to SchemaOrgParser once nothing more specific
+// matches the URL.
+const schemaOrgPriority = -1
+
+// SchemaOrgParser extracts a JobListing from a page's schema.org JobPosting
+// JSON-LD, with no host-specific selectors. It's the registry's catch-all:
+// Resolve reaches for it once every host-specific parser's CanParse has
+// rejected the URL, so sites without a dedicated parser (Greenhouse, Lever,
+// self-hosted boards, ...) still parse locally instead of 422ing straight
+// to the Python service.
+type SchemaOrgParser struct{}
+
+// NewSchemaOrgParser returns a ready-to-use SchemaOrgParser.
+func NewSchemaOrgParser() *SchemaOrgParser {
+	return &SchemaOrgParser{}
+}
+
+// CanParse always returns true: SchemaOrgParser is the registry's lowest
+// priority entry, so it's only ever tried once nothing more specific
+// matched.
+func (p *SchemaOrgParser) CanParse(_ string) bool {
+	return true
+}
+
+func (p *SchemaOrgParser) Parse(htmlContent string, jobURL string) (*JobListing, error) {
+	listing, err := extractJSONLDFromHTML(htmlContent)
+	if err != nil {
+		return nil, fmt.Errorf("extracting schema.org JobPosting: %w", err)
+	}
+	listing.SourcePlatform = hostOf(jobURL)
+	return listing, nil
+}
+
+// HasValidJobPosting reports whether htmlContent contains at least one
+// schema.org JobPosting JSON-LD block that extractJSONLDFromHTML can
+// actually decode — the same presence-and-validity check SchemaOrgParser's
+// own Parse relies on, exposed for callers (scoring's JSON-LD feature) that
+// only need a yes/no rather than a full JobListing.
+func HasValidJobPosting(htmlContent string) bool {
+	_, err := extractJSONLDFromHTML(htmlContent)
+	return err == nil
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func init() {
+	Register("schema.org", schemaOrgPriority, func() Parser {
+		return NewSchemaOrgParser()
+	})
+}
+
+// extractJSONLDFromHTML finds every <script type="application/ld+json">
+// block in content, decodes the first one whose "@type" is "JobPosting",
+// and maps its schema.org fields into a JobListing.
+func extractJSONLDFromHTML(content string) (*JobListing, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing page HTML: %w", err)
+	}
+
+	for _, block := range findJSONLDScripts(doc) {
+		if posting, ok := decodeJobPosting(block); ok {
+			return jobPostingToJobListing(posting), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no schema.org JobPosting JSON-LD found")
+}
+
+// findJSONLDScripts returns the text content of every
+// <script type="application/ld+json"> element in the document.
+func findJSONLDScripts(n *html.Node) []string {
+	var scripts []string
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "script" {
+			for _, attr := range n.Attr {
+				if attr.Key == "type" && attr.Val == "application/ld+json" {
+					scripts = append(scripts, extractText(n))
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return scripts
+}
+
+// extractText concatenates every text node under n.
+func extractText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var text string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text += extractText(c)
+	}
+	return text
+}
+
+// decodeJobPosting parses block, which may be a single object, an array of
+// objects, or an "@graph"-wrapped list, and returns the first entry whose
+// "@type" is "JobPosting".
+func decodeJobPosting(block string) (map[string]interface{}, bool) {
+	var single map[string]interface{}
+	if err := json.Unmarshal([]byte(block), &single); err == nil {
+		if ldType(single) == "JobPosting" {
+			return single, true
+		}
+		if graph, ok := single["@graph"].([]interface{}); ok {
+			return firstJobPosting(graph)
+		}
+		return nil, false
+	}
+
+	var list []interface{}
+	if err := json.Unmarshal([]byte(block), &list); err == nil {
+		return firstJobPosting(list)
+	}
+
+	return nil, false
+}
+
+func firstJobPosting(items []interface{}) (map[string]interface{}, bool) {
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok && ldType(obj) == "JobPosting" {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+func ldType(obj map[string]interface{}) string {
+	t, _ := obj["@type"].(string)
+	return t
+}
+
+// jobPostingToJobListing maps a decoded schema.org JobPosting object into a
+// JobListing.
+func jobPostingToJobListing(posting map[string]interface{}) *JobListing {
+	job := &JobListing{
+		Title:       ldString(posting, "title"),
+		Description: ldString(posting, "description"),
+		PostedAt:    ldString(posting, "datePosted"),
+		JobType:     ldString(posting, "employmentType"),
+	}
+
+	if org, ok := posting["hiringOrganization"].(map[string]interface{}); ok {
+		job.Company = ldString(org, "name")
+	}
+
+	job.Location = ldJobLocation(posting["jobLocation"])
+	job.SalaryMin, job.SalaryMax, job.Currency = ldBaseSalary(posting["baseSalary"])
+
+	locationType, _ := posting["jobLocationType"].(string)
+	if strings.EqualFold(locationType, "TELECOMMUTE") {
+		job.Remote = true
+	} else if _, ok := posting["applicantLocationRequirements"]; ok && job.Location == "" {
+		// A posting with location requirements but no jobLocation is
+		// usually fully remote within those regions.
+		job.Remote = true
+	}
+
+	return job
+}
+
+func ldString(obj map[string]interface{}, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return ""
+	}
+}
+
+// ldJobLocation formats a schema.org jobLocation (a single Place, or an
+// array of Places) into a human-readable address string.
+func ldJobLocation(v interface{}) string {
+	switch loc := v.(type) {
+	case map[string]interface{}:
+		return ldPlaceAddress(loc)
+	case []interface{}:
+		parts := make([]string, 0, len(loc))
+		for _, item := range loc {
+			if place, ok := item.(map[string]interface{}); ok {
+				if addr := ldPlaceAddress(place); addr != "" {
+					parts = append(parts, addr)
+				}
+			}
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return ""
+	}
+}
+
+func ldPlaceAddress(place map[string]interface{}) string {
+	addr, ok := place["address"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	var parts []string
+	for _, field := range []string{"addressLocality", "addressRegion", "addressCountry"} {
+		if s := ldString(addr, field); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// ldBaseSalary coerces a schema.org MonetaryAmount's nested value into
+// (min, max, currency). A single "value" (rather than "minValue"/
+// "maxValue") is returned as both min and max.
+func ldBaseSalary(v interface{}) (min, max *float64, currency string) {
+	salary, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, nil, ""
+	}
+
+	value, ok := salary["value"].(map[string]interface{})
+	if !ok {
+		return nil, nil, ""
+	}
+
+	currency = ldString(salary, "currency")
+
+	if minV, maxV := ldNumber(value["minValue"]), ldNumber(value["maxValue"]); minV != nil && maxV != nil {
+		return minV, maxV, currency
+	}
+	if single := ldNumber(value["value"]); single != nil {
+		return single, single, currency
+	}
+	return nil, nil, currency
+}
+
+func ldNumber(v interface{}) *float64 {
+	switch n := v.(type) {
+	case float64:
+		return &n
+	default:
+		return nil
+	}
+}
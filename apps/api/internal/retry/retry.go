@@ -0,0 +1,93 @@
+// Package retry is a small exponential-backoff-with-jitter retry helper,
+// pulled out of activities.osintClient's inline retry loop so other clients
+// that call flaky external services (Typesense bulk import, for one) can
+// share the same schedule without depending on the activities package.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Config tunes Do's backoff schedule, retry budget, and which errors are
+// worth retrying at all.
+type Config struct {
+	// InitialDelay is how long Do waits before the first retry.
+	InitialDelay time.Duration
+	// Multiplier is applied to the delay after each retry.
+	Multiplier float64
+	// MaxDelay caps the backoff delay, before jitter is applied.
+	MaxDelay time.Duration
+	// MaxAttempts is the total number of calls to fn, including the first —
+	// MaxAttempts of 5 means up to 4 retries.
+	MaxAttempts int
+	// IsRetryable decides whether an error from fn should trigger another
+	// attempt. A nil IsRetryable retries on every non-nil error.
+	IsRetryable func(error) bool
+	// NextDelay, when set, is consulted before each retry and may override
+	// the computed backoff delay for that attempt given the error fn just
+	// returned — e.g. to honor an upstream Retry-After header instead of
+	// the exponential schedule. Returning <= 0 falls back to the normal
+	// backoffWithJitter delay.
+	NextDelay func(attempt int, err error) time.Duration
+}
+
+// DefaultConfig is the standard bulk-indexing retry schedule: 200ms initial
+// delay, doubling each attempt, capped at 30s, up to 5 attempts.
+func DefaultConfig() Config {
+	return Config{
+		InitialDelay: 200 * time.Millisecond,
+		Multiplier:   2.0,
+		MaxDelay:     30 * time.Second,
+		MaxAttempts:  5,
+	}
+}
+
+// Do calls fn, retrying with exponential backoff jittered to between 50% and
+// 100% of the computed delay whenever fn returns an error cfg.IsRetryable accepts, until cfg.MaxAttempts
+// is reached or ctx is canceled. It returns how many retries it actually
+// performed alongside fn's final error (nil on eventual success).
+func Do(ctx context.Context, cfg Config, fn func() error) (retries int, err error) {
+	var lastErr error
+
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			wait := backoffWithJitter(cfg, attempt)
+			if cfg.NextDelay != nil {
+				if override := cfg.NextDelay(attempt, lastErr); override > 0 {
+					wait = override
+				}
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return retries, ctx.Err()
+			}
+			retries++
+		}
+
+		lastErr = fn()
+		if lastErr == nil {
+			return retries, nil
+		}
+		if cfg.IsRetryable != nil && !cfg.IsRetryable(lastErr) {
+			return retries, lastErr
+		}
+	}
+
+	return retries, lastErr
+}
+
+// backoffWithJitter returns how long to wait before retry number attempt
+// (attempt is 1-indexed here, since Do only calls it for attempt > 0).
+func backoffWithJitter(cfg Config, attempt int) time.Duration {
+	backoff := time.Duration(float64(cfg.InitialDelay) * math.Pow(cfg.Multiplier, float64(attempt-1)))
+	if backoff > cfg.MaxDelay {
+		backoff = cfg.MaxDelay
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff/2 + jitter
+}
@@ -0,0 +1,252 @@
+// Package scoring computes ScoreJobActivity's 0-100 authenticity score for
+// a parsed job listing. Every signal is captured as an independent 0-100
+// Features field; Combine weights and averages them into the final score.
+// The weights themselves come from LoadWeights, which re-reads its
+// environment on every call (unlike internal/config's Load, which reads
+// once at process startup) so an operator can retune them by updating the
+// worker's environment without rebuilding or redeploying the binary.
+//
+// Some features (JSONLDScore, BuzzwordRatioScore, SalarySpecificityScore,
+// DomainReputationScore) are pure functions of already-in-hand data and
+// live in this package. Features that need ClickHouse or the OSINT service
+// — description-length percentile, duplicate-title-across-companies, and
+// OSINT hits — are gathered by CrawlActivities.ScoreJobActivity itself,
+// which already owns those dependencies, and passed in as Features fields
+// alongside this package's own.
+package scoring
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Features holds one job's sub-scores, each on a 0-100 scale, for every
+// signal Combine weighs into the final score. A zero Features value (every
+// field 0) is indistinguishable from "all signals scored worst", so a
+// caller unable to compute a given field should prefer a neutral value
+// (e.g. 50) over leaving it at the zero value, the same way
+// descriptionLengthPercentile and duplicateTitleScore fall back to neutral
+// or best-case scores when ClickHouse is unavailable.
+type Features struct {
+	// JSONLD is 100 if the page has a valid schema.org JobPosting JSON-LD
+	// block, 0 otherwise.
+	JSONLD float64
+	// DomainReputation scores the posting's source domain by TLD.
+	DomainReputation float64
+	// BuzzwordRatio scores how much of the description reads as concrete
+	// requirements versus vague buzzwords.
+	BuzzwordRatio float64
+	// SalarySpecificity scores how specific the posted salary range is.
+	SalarySpecificity float64
+	// DescriptionLength is the job description's length percentile against
+	// the rest of the corpus.
+	DescriptionLength float64
+	// DuplicateTitle scores down the more distinct companies have posted
+	// the exact same title in the last 30 days.
+	DuplicateTitle float64
+	// OSINTHits scores up the more corroborating OSINT hits the company
+	// turns up.
+	OSINTHits float64
+}
+
+// Weights are Combine's per-feature multipliers. They don't need to sum to
+// 1 — Combine divides by their sum — but DefaultWeights does, so that a
+// partial override (see LoadWeights) changes one feature's influence
+// without silently rescaling the final 0-100 range.
+type Weights struct {
+	JSONLD            float64
+	DomainReputation  float64
+	BuzzwordRatio     float64
+	SalarySpecificity float64
+	DescriptionLength float64
+	DuplicateTitle    float64
+	OSINTHits         float64
+}
+
+// DefaultWeights is used for any weight LoadWeights' environment doesn't
+// override. JSONLD and DuplicateTitle carry the most weight — a missing
+// JobPosting schema or a title copy-pasted across many companies are the
+// strongest single tells of a low-effort or fake listing seen so far —
+// with the rest split over the remaining, individually weaker signals.
+func DefaultWeights() Weights {
+	return Weights{
+		JSONLD:            0.25,
+		DomainReputation:  0.10,
+		BuzzwordRatio:     0.15,
+		SalarySpecificity: 0.10,
+		DescriptionLength: 0.10,
+		DuplicateTitle:    0.20,
+		OSINTHits:         0.10,
+	}
+}
+
+// LoadWeights returns DefaultWeights with any SCORE_WEIGHT_* environment
+// variable substituted in for its corresponding field. It's called fresh on
+// every ScoreJobActivity invocation rather than cached once at worker
+// startup like internal/config.Load, so a weight can be tuned by updating
+// the running worker's environment without a redeploy.
+func LoadWeights() Weights {
+	w := DefaultWeights()
+	w.JSONLD = getEnvAsFloat("SCORE_WEIGHT_JSONLD", w.JSONLD)
+	w.DomainReputation = getEnvAsFloat("SCORE_WEIGHT_DOMAIN_REPUTATION", w.DomainReputation)
+	w.BuzzwordRatio = getEnvAsFloat("SCORE_WEIGHT_BUZZWORD_RATIO", w.BuzzwordRatio)
+	w.SalarySpecificity = getEnvAsFloat("SCORE_WEIGHT_SALARY_SPECIFICITY", w.SalarySpecificity)
+	w.DescriptionLength = getEnvAsFloat("SCORE_WEIGHT_DESCRIPTION_LENGTH", w.DescriptionLength)
+	w.DuplicateTitle = getEnvAsFloat("SCORE_WEIGHT_DUPLICATE_TITLE", w.DuplicateTitle)
+	w.OSINTHits = getEnvAsFloat("SCORE_WEIGHT_OSINT_HITS", w.OSINTHits)
+	return w
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// Combine weights f's fields by w and returns the result as a 0-100 int,
+// clamped in case of an unusual weight configuration (e.g. all zero). A
+// zero total weight falls back to an unweighted average rather than
+// dividing by zero.
+func Combine(f Features, w Weights) int {
+	total := w.JSONLD + w.DomainReputation + w.BuzzwordRatio + w.SalarySpecificity +
+		w.DescriptionLength + w.DuplicateTitle + w.OSINTHits
+	if total <= 0 {
+		w = Weights{1, 1, 1, 1, 1, 1, 1}
+		total = 7
+	}
+
+	sum := f.JSONLD*w.JSONLD +
+		f.DomainReputation*w.DomainReputation +
+		f.BuzzwordRatio*w.BuzzwordRatio +
+		f.SalarySpecificity*w.SalarySpecificity +
+		f.DescriptionLength*w.DescriptionLength +
+		f.DuplicateTitle*w.DuplicateTitle +
+		f.OSINTHits*w.OSINTHits
+
+	score := int(sum/total + 0.5)
+	switch {
+	case score < 0:
+		return 0
+	case score > 100:
+		return 100
+	default:
+		return score
+	}
+}
+
+// JSONLDScore converts parsers.HasValidJobPosting's presence check into a
+// Features value.
+func JSONLDScore(hasValidJobPosting bool) float64 {
+	if hasValidJobPosting {
+		return 100
+	}
+	return 0
+}
+
+// buzzwordPattern matches vague, resume-filler phrasing common in
+// low-effort or fake postings.
+var buzzwordPattern = regexp.MustCompile(`(?i)\b(rockstar|ninja|guru|ground[- ]?breaking|fast[- ]?paced|wear many hats|ping[- ]?pong|unlimited pto|work hard play hard|move fast and break things|synerg\w*|self[- ]?starter|go[- ]?getter)\b`)
+
+// concreteRequirementPattern matches phrasing that names a specific,
+// checkable requirement — years of experience, a degree, or a certification
+// — the kind of detail a copy-pasted or AI-generated fake listing tends to
+// omit in favor of buzzwordPattern's vaguer language.
+var concreteRequirementPattern = regexp.MustCompile(`(?i)(\d+\+?\s*years?|bachelor'?s?|master'?s?|b\.?s\.?|m\.?s\.?|ph\.?d\.?|certifi(ed|cation))\b`)
+
+// BuzzwordRatioScore scores description by how many concrete requirements
+// it names relative to how many vague buzzwords it uses: a description with
+// no buzzwords and no concrete requirements is treated as neutral (50)
+// rather than penalized for being short on both.
+func BuzzwordRatioScore(description string) float64 {
+	buzzwords := len(buzzwordPattern.FindAllString(description, -1))
+	concrete := len(concreteRequirementPattern.FindAllString(description, -1))
+	if buzzwords == 0 && concrete == 0 {
+		return 50
+	}
+	return 100 * float64(concrete) / float64(concrete+buzzwords)
+}
+
+// salaryRangeSane bounds what counts as a plausible max/min ratio — a
+// range wider than this reads as a placeholder (e.g. "$40k-$400k") rather
+// than a genuine band for one role.
+const salaryRangeSane = 5.0
+
+// SalarySpecificityScore scores how specific a posted salary is: no salary
+// at all scores lowest; only one bound, a non-positive or inverted bound, or
+// an implausibly wide range scores middling; and any other range scores
+// highest. Round figures are not detected, so a single figure given as
+// min == max scores highest.
+func SalarySpecificityScore(min, max *float64) float64 {
+	if min == nil && max == nil {
+		return 0
+	}
+	if min == nil || max == nil || *min <= 0 || *max <= 0 {
+		return 40
+	}
+	if *max < *min {
+		return 40
+	}
+	if *max/(*min) > salaryRangeSane {
+		return 40
+	}
+	return 100
+}
+
+// suspiciousTLDs are top-level domains disproportionately used for
+// throwaway or spam job-board mirrors, per common anti-spam domain
+// reputation lists.
+var suspiciousTLDs = map[string]bool{
+	"xyz": true, "top": true, "click": true, "work": true,
+	"loan": true, "men": true, "gq": true, "tk": true,
+}
+
+// trustedTLDs get a small boost over the neutral default — established,
+// costlier-to-abuse TLDs a legitimate employer or ATS is more likely to use.
+var trustedTLDs = map[string]bool{
+	"com": true, "org": true, "io": true, "co": true, "jobs": true,
+}
+
+// domainReputationCache memoizes DomainReputationScore per domain. The
+// lookup itself is cheap today (a TLD table), but keeping it behind a cache
+// keyed the same way lets a future real domain-age/WHOIS lookup (the
+// originally-envisioned version of this signal — no such data source is
+// wired into any of QuietHire's sidecars yet) slot in without changing any
+// caller.
+var (
+	domainReputationCache sync.Map // domain string -> float64
+)
+
+// DomainReputationScore scores domain by its TLD's reputation, caching the
+// result per domain. domain is typically a source_platform hostname (e.g.
+// "boards.greenhouse.io"); an empty domain scores neutral.
+func DomainReputationScore(domain string) float64 {
+	if domain == "" {
+		return 50
+	}
+	if cached, ok := domainReputationCache.Load(domain); ok {
+		return cached.(float64)
+	}
+
+	tld := domain
+	if i := strings.LastIndex(domain, "."); i >= 0 {
+		tld = domain[i+1:]
+	}
+	tld = strings.ToLower(tld)
+
+	var score float64
+	switch {
+	case suspiciousTLDs[tld]:
+		score = 20
+	case trustedTLDs[tld]:
+		score = 80
+	default:
+		score = 50
+	}
+
+	domainReputationCache.Store(domain, score)
+	return score
+}
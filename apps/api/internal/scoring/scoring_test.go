@@ -0,0 +1,150 @@
+package scoring
+
+import "testing"
+
+func TestCombineWeightsFeatures(t *testing.T) {
+	f := Features{
+		JSONLD:            100,
+		DomainReputation:  80,
+		BuzzwordRatio:     60,
+		SalarySpecificity: 100,
+		DescriptionLength: 50,
+		DuplicateTitle:    0,
+		OSINTHits:         50,
+	}
+	w := DefaultWeights()
+
+	got := Combine(f, w)
+	if got < 0 || got > 100 {
+		t.Fatalf("Combine returned out-of-range score: %d", got)
+	}
+	if got <= 0 {
+		t.Errorf("Combine(%+v, DefaultWeights()) = %d, want > 0 given mostly-strong features", f, got)
+	}
+}
+
+func TestCombineZeroWeightFallsBackToUnweightedAverage(t *testing.T) {
+	f := Features{JSONLD: 100, DomainReputation: 100, BuzzwordRatio: 100, SalarySpecificity: 100, DescriptionLength: 100, DuplicateTitle: 100, OSINTHits: 100}
+	got := Combine(f, Weights{})
+	if got != 100 {
+		t.Errorf("Combine with zero weights = %d, want 100 (all-features-max average)", got)
+	}
+}
+
+func TestCombineClampsToRange(t *testing.T) {
+	// Negative weights aren't a supported configuration, but Combine should
+	// still clamp rather than return an out-of-range score. A single
+	// negative weight alone makes the total weight <= 0 and triggers the
+	// unweighted-average fallback instead, so each case below mixes in a
+	// second, larger weight to keep the total positive and actually drive
+	// the weighted sum out of [0, 100].
+	tests := []struct {
+		name string
+		f    Features
+		w    Weights
+		want int
+	}{
+		{
+			name: "negative weighted sum clamps to 0",
+			f:    Features{JSONLD: 100, DomainReputation: 0},
+			w:    Weights{JSONLD: -1, DomainReputation: 2},
+			want: 0,
+		},
+		{
+			name: "weighted sum over 100 clamps to 100",
+			f:    Features{JSONLD: 100, DomainReputation: 0},
+			w:    Weights{JSONLD: 100, DomainReputation: -1},
+			want: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Combine(tt.f, tt.w); got != tt.want {
+				t.Errorf("Combine(%+v, %+v) = %d, want %d", tt.f, tt.w, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLDScore(t *testing.T) {
+	if got := JSONLDScore(true); got != 100 {
+		t.Errorf("JSONLDScore(true) = %v, want 100", got)
+	}
+	if got := JSONLDScore(false); got != 0 {
+		t.Errorf("JSONLDScore(false) = %v, want 0", got)
+	}
+}
+
+func TestBuzzwordRatioScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        float64
+	}{
+		{"neutral when neither signal present", "We build software for customers.", 50},
+		{"all concrete, no buzzwords scores 100", "Requires 5+ years experience and a bachelor's degree.", 100},
+		{"all buzzwords, no concrete scores 0", "Looking for a rockstar ninja who can move fast and break things.", 0},
+		{"mixed scores between 0 and 100", "Seeking a self-starter with 3+ years experience.", 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuzzwordRatioScore(tt.description); got != tt.want {
+				t.Errorf("BuzzwordRatioScore(%q) = %v, want %v", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSalarySpecificityScore(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		min, max *float64
+		want     float64
+	}{
+		{"no salary at all scores lowest", nil, nil, 0},
+		{"min without max scores middling", f(50000), nil, 40},
+		{"max without min scores middling", nil, f(50000), 40},
+		{"max below min scores middling", f(80000), f(50000), 40},
+		{"implausibly wide range scores middling", f(10000), f(500000), 40},
+		{"concrete sane range scores highest", f(80000), f(120000), 100},
+		{"non-positive min scores middling", f(0), f(50000), 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SalarySpecificityScore(tt.min, tt.max); got != tt.want {
+				t.Errorf("SalarySpecificityScore(%v, %v) = %v, want %v", tt.min, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainReputationScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		want   float64
+	}{
+		{"empty domain is neutral", "", 50},
+		{"suspicious TLD scores low", "boards.freejobs.xyz", 20},
+		{"trusted TLD scores high", "boards.greenhouse.io", 80},
+		{"unknown TLD is neutral", "careers.example.net", 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DomainReputationScore(tt.domain); got != tt.want {
+				t.Errorf("DomainReputationScore(%q) = %v, want %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainReputationScoreIsCachedPerDomain(t *testing.T) {
+	const domain = "cache-test.example.io"
+	first := DomainReputationScore(domain)
+	second := DomainReputationScore(domain)
+	if first != second {
+		t.Errorf("DomainReputationScore(%q) gave inconsistent results across calls: %v then %v", domain, first, second)
+	}
+}
@@ -0,0 +1,148 @@
+package searchindex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifierPattern is what FilterParams validates enum-ish values against
+// (experience_level, job_type, source_platform, and each tag) before they're
+// interpolated into a Typesense filter_by expression — rejecting anything
+// outside it keeps a caller from smuggling Typesense filter syntax (say,
+// `senior || 1:=1`) in through a query parameter.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FilterParams are the structured filter query parameters /api/v1/search
+// accepts, each corresponding to one facet or range condition on the jobs
+// schema. An empty field means "don't filter on this."
+type FilterParams struct {
+	Remote          string // "true" or "false"
+	ExperienceLevel string
+	JobType         string
+	SourcePlatform  string
+	Tags            string // comma-separated, e.g. "go,rust"
+	SalaryMin       string // minimum salary_min, e.g. "120000"
+	PostedAfter     string // unix timestamp jobs must be posted after
+}
+
+// BuildFilterBy validates p's fields and joins them into a Typesense
+// filter_by expression (e.g. "remote:=true && salary_min:>=120000"), or
+// returns an error naming the first field that failed validation.
+func BuildFilterBy(p FilterParams) (string, error) {
+	var clauses []string
+
+	if p.Remote != "" {
+		if p.Remote != "true" && p.Remote != "false" {
+			return "", fmt.Errorf("remote must be \"true\" or \"false\"")
+		}
+		clauses = append(clauses, "remote:="+p.Remote)
+	}
+
+	if p.ExperienceLevel != "" {
+		if !identifierPattern.MatchString(p.ExperienceLevel) {
+			return "", fmt.Errorf("invalid experience_level %q", p.ExperienceLevel)
+		}
+		clauses = append(clauses, "experience_level:="+p.ExperienceLevel)
+	}
+
+	if p.JobType != "" {
+		if !identifierPattern.MatchString(p.JobType) {
+			return "", fmt.Errorf("invalid job_type %q", p.JobType)
+		}
+		clauses = append(clauses, "job_type:="+p.JobType)
+	}
+
+	if p.SourcePlatform != "" {
+		if !identifierPattern.MatchString(p.SourcePlatform) {
+			return "", fmt.Errorf("invalid source_platform %q", p.SourcePlatform)
+		}
+		clauses = append(clauses, "source_platform:="+p.SourcePlatform)
+	}
+
+	if p.Tags != "" {
+		tags := strings.Split(p.Tags, ",")
+		for _, tag := range tags {
+			if !identifierPattern.MatchString(tag) {
+				return "", fmt.Errorf("invalid tag %q", tag)
+			}
+		}
+		clauses = append(clauses, "tags:=["+strings.Join(tags, ",")+"]")
+	}
+
+	if p.SalaryMin != "" {
+		if _, err := strconv.Atoi(p.SalaryMin); err != nil {
+			return "", fmt.Errorf("invalid salary_min %q", p.SalaryMin)
+		}
+		clauses = append(clauses, "salary_min:>="+p.SalaryMin)
+	}
+
+	if p.PostedAfter != "" {
+		if _, err := strconv.ParseInt(p.PostedAfter, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid posted_after %q", p.PostedAfter)
+		}
+		clauses = append(clauses, "posted_at:>"+p.PostedAfter)
+	}
+
+	return strings.Join(clauses, " && "), nil
+}
+
+// facetableFields are the JobsSchema fields marked Facet: true — the only
+// ones Typesense will accept in a facet_by request.
+var facetableFields = map[string]bool{
+	"company":          true,
+	"location":         true,
+	"remote":           true,
+	"currency":         true,
+	"job_type":         true,
+	"experience_level": true,
+	"source_platform":  true,
+	"tags":             true,
+}
+
+// sortableFields are the JobsSchema fields usable in a sort_by request.
+var sortableFields = map[string]bool{
+	"posted_at":  true,
+	"updated_at": true,
+	"real_score": true,
+	"salary_min": true,
+	"salary_max": true,
+}
+
+// BuildFacetBy validates a comma-separated facet_by query parameter against
+// facetableFields and returns it unchanged if every field is valid.
+func BuildFacetBy(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	for _, field := range strings.Split(raw, ",") {
+		if !facetableFields[field] {
+			return "", fmt.Errorf("field %q is not facetable", field)
+		}
+	}
+	return raw, nil
+}
+
+// BuildSortBy validates a comma-separated "field:asc|desc" sort_by query
+// parameter against sortableFields and returns it unchanged if every clause
+// is valid. Typesense itself caps this at 3 fields.
+func BuildSortBy(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	clauses := strings.Split(raw, ",")
+	if len(clauses) > 3 {
+		return "", fmt.Errorf("sort_by accepts at most 3 fields")
+	}
+	for _, clause := range clauses {
+		field, direction, ok := strings.Cut(clause, ":")
+		if !ok || (direction != "asc" && direction != "desc") {
+			return "", fmt.Errorf("invalid sort_by clause %q, want field:asc|desc", clause)
+		}
+		if !sortableFields[field] {
+			return "", fmt.Errorf("field %q is not sortable", field)
+		}
+	}
+	return raw, nil
+}
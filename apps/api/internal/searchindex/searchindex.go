@@ -0,0 +1,59 @@
+// Package searchindex holds the Typesense jobs schema and the
+// collection-alias convention the indexer and the API server share: search
+// traffic always goes through the JobsAlias name, which points at whichever
+// timestamped "jobs_<ts>" collection the most recent reindex built.
+package searchindex
+
+import (
+	"time"
+
+	"github.com/typesense/typesense-go/typesense/api"
+	"github.com/typesense/typesense-go/typesense/api/pointer"
+)
+
+// JobsAlias is the Typesense alias search queries and the indexer's alias
+// swap both target. It's never itself a collection that gets written to
+// directly.
+const JobsAlias = "jobs"
+
+// CollectionTimestampFormat is the layout NewCollectionName formats the
+// current time with, lexicographically sortable so newest-first/oldest-first
+// collection listing is a plain string sort.
+const CollectionTimestampFormat = "20060102T150405"
+
+// NewCollectionName returns a timestamped collection name for a fresh
+// reindex, e.g. "jobs_20240115T101500".
+func NewCollectionName(now time.Time) string {
+	return JobsAlias + "_" + now.UTC().Format(CollectionTimestampFormat)
+}
+
+// JobsSchema returns the jobs collection schema, under the given concrete
+// collection name (never JobsAlias itself — aliases aren't created with a
+// schema, they're pointed at a collection that already has one).
+func JobsSchema(collectionName string) *api.CollectionSchema {
+	return &api.CollectionSchema{
+		Name: collectionName,
+		Fields: []api.Field{
+			{Name: "id", Type: "string"},
+			{Name: "title", Type: "string"},
+			{Name: "company", Type: "string", Facet: pointer.True()},
+			{Name: "description", Type: "string"},
+			{Name: "location", Type: "string", Facet: pointer.True()},
+			{Name: "remote", Type: "bool", Facet: pointer.True()},
+			{Name: "salary_min", Type: "int32", Optional: pointer.True()},
+			{Name: "salary_max", Type: "int32", Optional: pointer.True()},
+			{Name: "currency", Type: "string", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "job_type", Type: "string", Facet: pointer.True()},
+			{Name: "experience_level", Type: "string", Facet: pointer.True(), Optional: pointer.True()},
+			{Name: "real_score", Type: "int32"},
+			{Name: "hiring_manager_name", Type: "string", Optional: pointer.True()},
+			{Name: "hiring_manager_email", Type: "string", Optional: pointer.True()},
+			{Name: "posted_at", Type: "int64"},
+			{Name: "updated_at", Type: "int64"},
+			{Name: "source_url", Type: "string"},
+			{Name: "source_platform", Type: "string", Facet: pointer.True()},
+			{Name: "tags", Type: "string[]", Facet: pointer.True(), Optional: pointer.True()},
+		},
+		DefaultSortingField: pointer.String("posted_at"),
+	}
+}
@@ -0,0 +1,263 @@
+package workflows
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/workflows/concurrency"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// careerSiteDefaultMaxDepth bounds BFS depth when
+	// CareerSiteCrawlInput.MaxDepth is unset.
+	careerSiteDefaultMaxDepth = 3
+	// careerSiteContinueAsNewThreshold caps frontier+visited size before the
+	// workflow calls workflow.ContinueAsNew, keeping workflow history small
+	// on a site-wide sweep whose visited set would otherwise grow unbounded.
+	careerSiteContinueAsNewThreshold = 500
+)
+
+// knownATSHosts are off-domain hosts allowed through SameHostOnly filtering
+// because career sites commonly link out to them for the actual job
+// listings.
+var knownATSHosts = []string{"greenhouse.io", "lever.co", "workday.com", "myworkdayjobs.com", "ashbyhq.com"}
+
+// careerSiteCrawlItem is one BFS frontier entry.
+type careerSiteCrawlItem struct {
+	URL   string
+	Depth int
+}
+
+// CareerSiteCrawlInput defines input for a breadth-first crawl of a career
+// site. Frontier and Visited let a run resume where a prior
+// workflow.ContinueAsNew left off; a fresh crawl leaves them empty.
+type CareerSiteCrawlInput struct {
+	StartURL    string
+	CompanyName string
+	CompanyID   int
+
+	// MaxDepth bounds BFS depth; <= 0 falls back to careerSiteDefaultMaxDepth.
+	MaxDepth int
+	// SameHostOnly rejects links whose host differs from StartURL's host,
+	// except for known ATS hosts (see knownATSHosts), which are always allowed.
+	SameHostOnly bool
+	// URLSuffixes, if non-empty, only allows links whose path ends in one of
+	// these suffixes (e.g. "/careers", "/jobs").
+	URLSuffixes []string
+
+	Frontier []careerSiteCrawlItem
+	Visited  []string
+}
+
+// CareerSiteCrawlResult defines the result of a CareerSiteCrawlWorkflow run.
+type CareerSiteCrawlResult struct {
+	PagesVisited int
+	PagesQueued  int
+}
+
+// CareerSiteCrawlWorkflow performs a breadth-first crawl of a company's
+// career site: each level fetches and extracts links for every frontier URL
+// in parallel (bounded by defaultFanOutConcurrency), filters the results by
+// host/suffix, and queues the pages it visited via QueueURLsForCrawling.
+// Because a site-wide sweep's frontier and visited set can grow unbounded,
+// the workflow calls workflow.ContinueAsNew once their combined size passes
+// careerSiteContinueAsNewThreshold, carrying both forward to the next run.
+func CareerSiteCrawlWorkflow(ctx workflow.Context, input CareerSiteCrawlInput) (*CareerSiteCrawlResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting CareerSiteCrawlWorkflow", "start_url", input.StartURL, "company", input.CompanyName)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 2 * time.Minute,
+		// FetchAndExtractLinks only heartbeats before and after its single
+		// blocking HTTP call (crawlSingleJob has no natural point to
+		// heartbeat mid-fetch), so this must stay comfortably above the
+		// worker's own HTTPClient.Timeout (60s, see cmd/worker/main.go) or
+		// a merely-slow-but-still-working fetch would get killed as stuck.
+		// A worker that dies mid-fetch without heartbeating for this long
+		// still lets Temporal fail the activity and retry it elsewhere,
+		// rather than leaving the crawl hung until StartToCloseTimeout.
+		HeartbeatTimeout: 90 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    2 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	maxDepth := input.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = careerSiteDefaultMaxDepth
+	}
+
+	startHost, err := hostOf(input.StartURL)
+	if err != nil {
+		logger.Error("Invalid start URL", "url", input.StartURL, "error", err)
+		return nil, err
+	}
+
+	visited := make(map[string]bool, len(input.Visited))
+	for _, u := range input.Visited {
+		visited[u] = true
+	}
+
+	frontier := input.Frontier
+	if len(frontier) == 0 && !visited[input.StartURL] {
+		frontier = []careerSiteCrawlItem{{URL: input.StartURL, Depth: 0}}
+	}
+
+	result := &CareerSiteCrawlResult{}
+	var discoveredPages []CareerPageInfo
+
+	for len(frontier) > 0 {
+		level := frontier
+		frontier = nil
+
+		// Mark this level visited up front so links two pages on the same
+		// level both point to are only fetched once, at the next level.
+		for _, item := range level {
+			visited[item.URL] = true
+		}
+
+		linkResults, linkErrs := concurrency.ForEachJob[careerSiteCrawlItem, []string](ctx, level, defaultFanOutConcurrency,
+			func(ctx workflow.Context, _ int, item careerSiteCrawlItem) workflow.Future {
+				return workflow.ExecuteActivity(ctx, "FetchAndExtractLinks", item.URL)
+			})
+
+		result.PagesVisited += len(level)
+
+		for i, item := range level {
+			discoveredPages = append(discoveredPages, CareerPageInfo{
+				URL:    item.URL,
+				Domain: startHost,
+				Source: "career_site_crawl",
+			})
+
+			if linkErrs[i] != nil {
+				logger.Warn("Failed to fetch/extract links", "url", item.URL, "error", linkErrs[i])
+				continue
+			}
+			if item.Depth >= maxDepth {
+				continue
+			}
+
+			for _, link := range linkResults[i] {
+				if visited[link] {
+					continue
+				}
+				if !careerSiteLinkAllowed(link, startHost, input.SameHostOnly, input.URLSuffixes) {
+					continue
+				}
+				visited[link] = true
+				frontier = append(frontier, careerSiteCrawlItem{URL: link, Depth: item.Depth + 1})
+			}
+		}
+
+		if len(frontier)+len(visited) > careerSiteContinueAsNewThreshold {
+			logger.Info("Frontier plus visited set exceeds threshold, continuing as new",
+				"frontier", len(frontier), "visited", len(visited))
+
+			if err := queueCareerSitePages(ctx, discoveredPages, result); err != nil {
+				logger.Error("Failed to queue discovered pages before continue-as-new", "error", err)
+			}
+
+			nextInput := input
+			nextInput.Frontier = frontier
+			nextInput.Visited = visitedSlice(visited)
+			return result, workflow.NewContinueAsNewError(ctx, CareerSiteCrawlWorkflow, nextInput)
+		}
+	}
+
+	if err := queueCareerSitePages(ctx, discoveredPages, result); err != nil {
+		logger.Error("Failed to queue discovered pages", "error", err)
+	}
+
+	logger.Info("CareerSiteCrawlWorkflow completed",
+		"pages_visited", result.PagesVisited, "pages_queued", result.PagesQueued)
+	return result, nil
+}
+
+// queueCareerSitePages ranks/dedupes discovered pages and queues them via
+// the same RankCareerPages + QueueURLsForCrawling activities
+// CompanyDiscoveryWorkflow uses.
+func queueCareerSitePages(ctx workflow.Context, pages []CareerPageInfo, result *CareerSiteCrawlResult) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	var rankedPages []CareerPageInfo
+	if err := workflow.ExecuteActivity(ctx, "RankCareerPages", pages).Get(ctx, &rankedPages); err != nil {
+		rankedPages = pages
+	}
+
+	var queueStats QueueStats
+	if err := workflow.ExecuteActivity(ctx, "QueueURLsForCrawling", rankedPages).Get(ctx, &queueStats); err != nil {
+		return err
+	}
+	result.PagesQueued += queueStats.Inserted + queueStats.Updated
+	return nil
+}
+
+// hostOf returns the lowercased host component of rawURL.
+func hostOf(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(u.Host), nil
+}
+
+// careerSiteLinkAllowed reports whether link should be added to the crawl
+// frontier: it must parse as http(s), match startHost (unless it's a known
+// ATS host) when sameHostOnly is set, and match at least one suffix in
+// suffixes when suffixes is non-empty.
+func careerSiteLinkAllowed(link, startHost string, sameHostOnly bool, suffixes []string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	if sameHostOnly {
+		host := strings.ToLower(u.Host)
+		if host != startHost && !isKnownATSHost(host) {
+			return false
+		}
+	}
+
+	if len(suffixes) == 0 {
+		return true
+	}
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(u.Path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownATSHost reports whether host is, or is a subdomain of, a well-known
+// ATS platform.
+func isKnownATSHost(host string) bool {
+	for _, ats := range knownATSHosts {
+		if host == ats || strings.HasSuffix(host, "."+ats) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitedSlice converts a visited set to a sorted slice so the
+// ContinueAsNew input it feeds is stable across replays of this run.
+func visitedSlice(visited map[string]bool) []string {
+	out := make([]string, 0, len(visited))
+	for u := range visited {
+		out = append(out, u)
+	}
+	sort.Strings(out)
+	return out
+}
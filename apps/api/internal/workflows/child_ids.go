@@ -0,0 +1,49 @@
+package workflows
+
+import (
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/google/uuid"
+)
+
+// childIDVersionChangeID identifies the workflow.GetVersion call a workflow
+// makes before its first use of childID, so an execution already in flight
+// when childID was introduced keeps generating child workflow IDs the old
+// way on replay, instead of producing a different history and failing
+// Temporal's non-determinism check.
+const childIDVersionChangeID = "ChildWorkflowIDScheme"
+
+// childID builds a child workflow ID of the form
+// "<prefix>-<domain>-<timestamp>-<uuid>", snapshotted with workflow.SideEffect
+// so replay always reproduces the exact ID a workflow history already
+// recorded. The UUID suffix is what lets re-running the same prefix/domain
+// combination within the same second start a new execution instead of
+// colliding with a still-running one (WorkflowExecutionAlreadyStarted);
+// domain may be empty when a call site has no natural second ID segment.
+func childID(ctx workflow.Context, prefix, domain string) string {
+	var id string
+	_ = workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+		base := prefix
+		if domain != "" {
+			base += "-" + domain
+		}
+		return base + "-" + workflow.Now(ctx).Format("20060102-150405") + "-" + uuid.New().String()
+	}).Get(&id)
+	return id
+}
+
+// childWorkflowOptions returns the ChildWorkflowOptions a versioned call site
+// should pass to workflow.WithChildOptions: a childID-generated WorkflowID,
+// plus ParentClosePolicy ABANDON when abandonOnCancel is set, so a cancelled
+// parent lets a still-running crawl child keep going instead of also
+// terminating it.
+func childWorkflowOptions(ctx workflow.Context, prefix, domain string, abandonOnCancel bool) workflow.ChildWorkflowOptions {
+	opts := workflow.ChildWorkflowOptions{
+		WorkflowID: childID(ctx, prefix, domain),
+	}
+	if abandonOnCancel {
+		opts.ParentClosePolicy = enumspb.PARENT_CLOSE_POLICY_ABANDON
+	}
+	return opts
+}
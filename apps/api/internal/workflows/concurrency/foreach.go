@@ -0,0 +1,61 @@
+// Package concurrency provides bounded-concurrency fan-out helpers for
+// Temporal workflows, mirroring the dskit concurrency.ForEachJob pattern.
+package concurrency
+
+import "go.temporal.io/sdk/workflow"
+
+// JobFunc starts one unit of work for item i (an activity call or a child
+// workflow) and returns the Future to wait on.
+type JobFunc[T any] func(ctx workflow.Context, i int, item T) workflow.Future
+
+// ForEachJob runs fn once per item with at most concurrency Futures in
+// flight at a time, instead of firing every activity call at once and
+// letting Temporal or the downstream service queue them. It keeps a bounded
+// window open via workflow.Selector, starting the next job as soon as one
+// completes, and returns results and errors in the same order as items —
+// results[i]/errs[i] correspond to items[i]. A failed job only sets errs[i];
+// it never aborts the rest of the batch.
+func ForEachJob[T any, R any](ctx workflow.Context, items []T, concurrency int, fn JobFunc[T]) ([]R, []error) {
+	n := len(items)
+	results := make([]R, n)
+	errs := make([]error, n)
+	if n == 0 {
+		return results, errs
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	selector := workflow.NewSelector(ctx)
+	next := 0
+	inFlight := 0
+
+	startNext := func() {
+		i := next
+		next++
+		inFlight++
+
+		future := fn(ctx, i, items[i])
+		selector.AddFuture(future, func(f workflow.Future) {
+			inFlight--
+			var r R
+			if err := f.Get(ctx, &r); err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = r
+		})
+	}
+
+	for next < n && inFlight < concurrency {
+		startNext()
+	}
+	for inFlight > 0 {
+		selector.Select(ctx)
+		for next < n && inFlight < concurrency {
+			startNext()
+		}
+	}
+
+	return results, errs
+}
@@ -0,0 +1,43 @@
+package concurrency
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// RateLimiter throttles per-source call rate ("github", "google", "dns",
+// "http", ...) inside a workflow. It uses workflow.Now/workflow.Sleep rather
+// than wall-clock timers so throttling stays deterministic and replay-safe.
+type RateLimiter struct {
+	qps      map[string]float64
+	lastCall map[string]time.Time
+}
+
+// NewRateLimiter builds a RateLimiter with a QPS limit per source name.
+// Sources absent from qps (or with qps <= 0) are never throttled.
+func NewRateLimiter(qps map[string]float64) *RateLimiter {
+	return &RateLimiter{
+		qps:      qps,
+		lastCall: make(map[string]time.Time),
+	}
+}
+
+// Wait blocks, via workflow.Sleep, until calling source again respects its
+// configured QPS limit, then records this call's time.
+func (r *RateLimiter) Wait(ctx workflow.Context, source string) {
+	qps, ok := r.qps[source]
+	if !ok || qps <= 0 {
+		return
+	}
+	minInterval := time.Duration(float64(time.Second) / qps)
+
+	now := workflow.Now(ctx)
+	if last, seen := r.lastCall[source]; seen {
+		if wait := minInterval - now.Sub(last); wait > 0 {
+			_ = workflow.Sleep(ctx, wait)
+			now = workflow.Now(ctx)
+		}
+	}
+	r.lastCall[source] = now
+}
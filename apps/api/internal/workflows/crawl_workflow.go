@@ -5,10 +5,31 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/itisrohit/quiethire/apps/api/internal/eventbus"
+	"go.temporal.io/sdk/log"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
+// publishCrawlEvent runs a PublishCrawlEvent activity for topic (e.g.
+// "crawl.started") and waits for it to finish, but never fails the caller's
+// own flow on it: a failed publish is logged and otherwise ignored, since a crawl shouldn't fail
+// just because nothing downstream is listening. WorkflowID/RunID come from
+// workflow.GetInfo, which replays deterministically.
+func publishCrawlEvent(ctx workflow.Context, logger log.Logger, topic, url string, companyID int, payload map[string]interface{}) {
+	info := workflow.GetInfo(ctx)
+	envelope := eventbus.Envelope{
+		WorkflowID: info.WorkflowExecution.ID,
+		RunID:      info.WorkflowExecution.RunID,
+		URL:        url,
+		CompanyID:  companyID,
+		Payload:    payload,
+	}
+	if err := workflow.ExecuteActivity(ctx, "PublishCrawlEvent", topic, envelope).Get(ctx, nil); err != nil {
+		logger.Warn("Failed to publish crawl event", "topic", topic, "url", url, "error", err)
+	}
+}
+
 // JobCrawlInput defines the input for a job crawl workflow
 type JobCrawlInput struct {
 	Platform string   // "indeed", "linkedin", etc.
@@ -45,11 +66,14 @@ func CrawlCoordinatorWorkflow(ctx workflow.Context, input JobCrawlInput) (*JobCr
 	startTime := workflow.Now(ctx)
 	result := &JobCrawlResult{}
 
+	publishCrawlEvent(ctx, logger, "crawl.started", "", 0, map[string]interface{}{"platform": input.Platform, "seed_urls": input.URLs})
+
 	// Activity 1: Discover job URLs from the platform
 	var urls []string
 	err := workflow.ExecuteActivity(ctx, "DiscoverJobURLs", input.Platform, input.URLs).Get(ctx, &urls)
 	if err != nil {
 		logger.Error("Failed to discover URLs", "error", err)
+		publishCrawlEvent(ctx, logger, "crawl.failed", "", 0, map[string]interface{}{"platform": input.Platform, "error": err.Error()})
 		return nil, err
 	}
 
@@ -102,12 +126,31 @@ func CrawlCoordinatorWorkflow(ctx workflow.Context, input JobCrawlInput) (*JobCr
 	return result, nil
 }
 
+// ScheduledCrawlInput defines input for ScheduledCrawlWorkflow.
+type ScheduledCrawlInput struct {
+	// AbandonChildrenOnCancel, when true, sets ParentClosePolicy ABANDON on
+	// every platform crawl child, so cancelling this workflow doesn't also
+	// terminate a crawl still in flight.
+	AbandonChildrenOnCancel bool
+}
+
 // ScheduledCrawlWorkflow runs on a schedule (e.g., every 6 hours)
 // to continuously crawl all configured platforms
-func ScheduledCrawlWorkflow(ctx workflow.Context) error {
+//
+// Deprecated: its platform/URL list is hardcoded, so adding a platform
+// means a redeploy. SchedulerWorkflow reads the same information from the
+// crawl_schedules table instead, editable at runtime. This is kept only so
+// a Temporal Schedule already pointing at it by name keeps working; point
+// new schedules at SchedulerWorkflow.
+func ScheduledCrawlWorkflow(ctx workflow.Context, input ScheduledCrawlInput) error {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Starting ScheduledCrawlWorkflow")
 
+	// childID didn't exist when earlier executions of this workflow started;
+	// version 0 reproduces their exact pre-childID WorkflowID on replay, and
+	// only new executions (version 1) use it.
+	idVersion := workflow.GetVersion(ctx, childIDVersionChangeID, workflow.DefaultVersion, 1)
+
 	// List of platforms to crawl
 	platforms := []struct {
 		Name string
@@ -120,19 +163,24 @@ func ScheduledCrawlWorkflow(ctx workflow.Context) error {
 
 	// Start a separate workflow for each platform
 	for _, platform := range platforms {
-		input := JobCrawlInput{
+		crawlInput := JobCrawlInput{
 			Platform: platform.Name,
 			URLs:     platform.URLs,
 			MaxJobs:  1000,
 		}
 
-		// Start child workflow for each platform
-		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-			WorkflowID: "crawl-" + platform.Name + "-" + workflow.Now(ctx).Format("20060102-150405"),
-		})
+		var childOpts workflow.ChildWorkflowOptions
+		if idVersion == workflow.DefaultVersion {
+			childOpts = workflow.ChildWorkflowOptions{
+				WorkflowID: "crawl-" + platform.Name + "-" + workflow.Now(ctx).Format("20060102-150405"),
+			}
+		} else {
+			childOpts = childWorkflowOptions(ctx, "crawl", platform.Name, input.AbandonChildrenOnCancel)
+		}
+		childCtx := workflow.WithChildOptions(ctx, childOpts)
 
 		var result JobCrawlResult
-		err := workflow.ExecuteChildWorkflow(childCtx, CrawlCoordinatorWorkflow, input).Get(childCtx, &result)
+		err := workflow.ExecuteChildWorkflow(childCtx, CrawlCoordinatorWorkflow, crawlInput).Get(childCtx, &result)
 		if err != nil {
 			logger.Error("Platform crawl failed", "platform", platform.Name, "error", err)
 		} else {
@@ -175,9 +223,25 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 		Success: false,
 	}
 
-	// Set activity options with retries
+	// Set activity options with retries.
+	//
+	// HeartbeatTimeout is set here so that, once CrawlCareerPage and
+	// ParseJobPage exist as real activities, Temporal's own stuck-activity
+	// detection has a signal to act on (ReaperWorkflow goes by start time
+	// alone). As of this writing, "CrawlCareerPage", "ExtractJobLinks",
+	// "ParseJobPage", and "StoreJobsInClickHouse" below are referenced only
+	// by their string names — no activity is registered under any of them
+	// anywhere in this repository, so CareerPageCrawlWorkflow cannot
+	// currently complete past Step 1. That gap predates this change and is
+	// out of scope here; ReaperWorkflow's sweep terminates any execution
+	// still running past its staleness threshold either way.
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 5 * time.Minute,
+		// See career_site_crawl_workflow.go's identical HeartbeatTimeout
+		// comment: the crawlSingleJob-style fetch these activities would
+		// use only heartbeats before/after its blocking HTTP call, so this
+		// stays above the worker's 60s HTTPClient.Timeout.
+		HeartbeatTimeout: 90 * time.Second,
 		RetryPolicy: &temporal.RetryPolicy{
 			InitialInterval:    2 * time.Second,
 			BackoffCoefficient: 2.0,
@@ -187,6 +251,8 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
+	publishCrawlEvent(ctx, logger, "crawl.started", input.URL, input.CompanyID, map[string]interface{}{"company": input.CompanyName})
+
 	// Step 1: Crawl the career page to get HTML
 	logger.Info("Step 1: Crawling career page", "url", input.URL)
 	var crawlResult struct {
@@ -205,6 +271,7 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to crawl page: %v", err)
 		logger.Error("Crawl failed", "error", err)
+		publishCrawlEvent(ctx, logger, "crawl.failed", input.URL, input.CompanyID, map[string]interface{}{"error": result.ErrorMessage})
 		result.Duration = workflow.Now(ctx).Sub(startTime)
 		return result, nil // Return result instead of error to mark workflow complete
 	}
@@ -212,11 +279,13 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	if !crawlResult.Success {
 		result.ErrorMessage = fmt.Sprintf("crawl unsuccessful: %s", crawlResult.Error)
 		logger.Warn("Crawl unsuccessful", "error", crawlResult.Error)
+		publishCrawlEvent(ctx, logger, "crawl.failed", input.URL, input.CompanyID, map[string]interface{}{"error": result.ErrorMessage})
 		result.Duration = workflow.Now(ctx).Sub(startTime)
 		return result, nil
 	}
 
 	logger.Info("Successfully crawled page", "html_size", len(crawlResult.HTML))
+	publishCrawlEvent(ctx, logger, "crawl.page_fetched", input.URL, input.CompanyID, map[string]interface{}{"html_size": len(crawlResult.HTML)})
 
 	// Step 2: Extract job links from the career page HTML
 	logger.Info("Step 2: Extracting job links from HTML")
@@ -228,6 +297,7 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to extract job links: %v", err)
 		logger.Error("Extract job links failed", "error", err)
+		publishCrawlEvent(ctx, logger, "crawl.failed", input.URL, input.CompanyID, map[string]interface{}{"error": result.ErrorMessage})
 		result.Duration = workflow.Now(ctx).Sub(startTime)
 		return result, nil
 	}
@@ -302,6 +372,7 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 
 		jobs = append(jobs, parsedJob)
 		logger.Info("Successfully parsed job", "title", parsedJob["title"], "company", parsedJob["company"])
+		publishCrawlEvent(ctx, logger, "crawl.job_extracted", link.URL, input.CompanyID, map[string]interface{}{"title": parsedJob["title"]})
 	}
 
 	logger.Info("Parsed jobs from individual pages", "count", len(jobs))
@@ -320,6 +391,7 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to store jobs: %v", err)
 		logger.Error("Storage failed", "error", err)
+		publishCrawlEvent(ctx, logger, "crawl.failed", input.URL, input.CompanyID, map[string]interface{}{"error": result.ErrorMessage})
 		result.Duration = workflow.Now(ctx).Sub(startTime)
 		return result, nil
 	}
@@ -328,6 +400,8 @@ func CareerPageCrawlWorkflow(ctx workflow.Context, input CareerPageCrawlInput) (
 	result.Success = true
 	result.Duration = workflow.Now(ctx).Sub(startTime)
 
+	publishCrawlEvent(ctx, logger, "crawl.job_stored", input.URL, input.CompanyID, map[string]interface{}{"jobs_found": result.JobsFound, "jobs_stored": result.JobsStored})
+
 	logger.Info("CareerPageCrawlWorkflow completed",
 		"jobs_found", result.JobsFound,
 		"jobs_stored", result.JobsStored,
@@ -2,17 +2,45 @@
 package workflows
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/itisrohit/quiethire/apps/api/internal/workflows/concurrency"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
+// defaultFanOutConcurrency bounds how many Futures a fan-out stage keeps in
+// flight at once when DiscoveryInput.Concurrency is unset.
+const defaultFanOutConcurrency = 10
+
 // DiscoveryInput defines the input for discovery workflows
 type DiscoveryInput struct {
 	Query      string   // Search query or company name
 	Sources    []string // Sources to use: github, google_dork, subdomains, etc.
 	MaxResults int      // Maximum results to process
+	// Concurrency caps how many Futures each fan-out stage (career-page
+	// discovery, subdomain enumeration, ATS detection, crawl triggering)
+	// keeps in flight at once. <= 0 falls back to defaultFanOutConcurrency.
+	Concurrency int
+	// RateLimits caps queries-per-second per external source: "github",
+	// "google", "dns", "http". A source absent from the map is unthrottled.
+	RateLimits map[string]float64
+}
+
+// rateLimitKeyForSource maps a discovery provider name to the RateLimiter
+// source key its traffic counts against.
+func rateLimitKeyForSource(provider string) string {
+	switch provider {
+	case "github":
+		return "github"
+	case "google_dork":
+		return "google"
+	case "subdomains":
+		return "dns"
+	default:
+		return "http"
+	}
 }
 
 // DiscoveryResult defines the result of a discovery workflow
@@ -49,158 +77,145 @@ func CompanyDiscoveryWorkflow(ctx workflow.Context, input DiscoveryInput) (*Disc
 		ATSPlatforms: make(map[string]int),
 	}
 
-	// Step 1: Discover companies from all sources in parallel
-	var futures []workflow.Future
-
-	for _, source := range input.Sources {
-		switch source {
-		case "github":
-			future := workflow.ExecuteActivity(ctx, "DiscoverCompaniesFromGitHub", input.Query, input.MaxResults)
-			futures = append(futures, future)
-		case "google_dork":
-			future := workflow.ExecuteActivity(ctx, "DiscoverCompaniesFromGoogleDorks", input.Query, input.MaxResults)
-			futures = append(futures, future)
-		case "manual":
-			future := workflow.ExecuteActivity(ctx, "AddCompanyManually", input.Query)
-			futures = append(futures, future)
-		}
+	conc := input.Concurrency
+	if conc <= 0 {
+		conc = defaultFanOutConcurrency
 	}
+	limiter := concurrency.NewRateLimiter(input.RateLimits)
+
+	// Step 1: Discover companies from every registered provider in input.Sources,
+	// bounded to conc in-flight calls at a time. Sources map 1:1 to provider
+	// names in the activity-side ProvidersMap, so adding a new source (e.g.
+	// crt.sh) never touches this loop.
+	sourceResults, sourceErrs := concurrency.ForEachJob[string, DiscoveryProviderResult](ctx, input.Sources, conc,
+		func(ctx workflow.Context, _ int, source string) workflow.Future {
+			limiter.Wait(ctx, rateLimitKeyForSource(source))
+			req := DiscoveryProviderRequest{Query: input.Query, MaxResults: input.MaxResults}
+			return workflow.ExecuteActivity(ctx, "DiscoverFromProvider", source, req)
+		})
 
-	// Collect all discovered companies
+	// Collect all discovered companies (a provider may also return career
+	// pages directly, e.g. a future crt.sh provider; this workflow only acts
+	// on companies from this step, so any such pages are discarded here)
 	var allCompanies []CompanyInfo
-	for _, future := range futures {
-		var companies []CompanyInfo
-		err := future.Get(ctx, &companies)
-		if err != nil {
-			logger.Error("Failed to discover companies from source", "error", err)
+	for i, res := range sourceResults {
+		if sourceErrs[i] != nil {
+			logger.Error("Failed to discover companies from source", "error", sourceErrs[i])
 			continue
 		}
-		allCompanies = append(allCompanies, companies...)
+		allCompanies = append(allCompanies, res.Companies...)
 	}
 
 	result.CompaniesFound = len(allCompanies)
 	logger.Info("Total companies discovered", "count", result.CompaniesFound)
 
-	// Step 2: For each company, discover career pages (parallel processing)
-	careerPageFutures := make([]workflow.Future, 0, len(allCompanies)*2)
-	for _, company := range allCompanies {
-		future := workflow.ExecuteActivity(ctx, "DiscoverCareerPages", company.Domain, company.Name)
-		careerPageFutures = append(careerPageFutures, future)
+	// Step 2: For each company, discover career pages and enumerate
+	// subdomains (via the "subdomains" provider), each bounded to conc
+	// in-flight calls and rate-limited per source ("http" for career pages,
+	// "dns" for subdomains)
+	careerPageResults, careerPageErrs := concurrency.ForEachJob[CompanyInfo, []CareerPageInfo](ctx, allCompanies, conc,
+		func(ctx workflow.Context, _ int, company CompanyInfo) workflow.Future {
+			limiter.Wait(ctx, "http")
+			return workflow.ExecuteActivity(ctx, "DiscoverCareerPages", company.Domain, company.Name, company.Source)
+		})
 
-		// Also enumerate subdomains
-		future = workflow.ExecuteActivity(ctx, "EnumerateSubdomains", company.Domain)
-		careerPageFutures = append(careerPageFutures, future)
-	}
+	subdomainResults, subdomainErrs := concurrency.ForEachJob[CompanyInfo, DiscoveryProviderResult](ctx, allCompanies, conc,
+		func(ctx workflow.Context, _ int, company CompanyInfo) workflow.Future {
+			limiter.Wait(ctx, "dns")
+			req := DiscoveryProviderRequest{Domain: company.Domain}
+			return workflow.ExecuteActivity(ctx, "DiscoverFromProvider", "subdomains", req)
+		})
 
 	// Collect all discovered career pages
 	var allCareerPages []CareerPageInfo
-	for _, future := range careerPageFutures {
-		var pages []CareerPageInfo
-		err := future.Get(ctx, &pages)
-		if err != nil {
-			logger.Error("Failed to discover career pages", "error", err)
+	for i, pages := range careerPageResults {
+		if careerPageErrs[i] != nil {
+			logger.Error("Failed to discover career pages", "error", careerPageErrs[i])
 			continue
 		}
 		allCareerPages = append(allCareerPages, pages...)
 	}
+	for i, res := range subdomainResults {
+		if subdomainErrs[i] != nil {
+			logger.Error("Failed to enumerate subdomains", "error", subdomainErrs[i])
+			continue
+		}
+		allCareerPages = append(allCareerPages, res.CareerPages...)
+	}
 
 	result.CareerPagesFound = len(allCareerPages)
 	logger.Info("Total career pages discovered", "count", result.CareerPagesFound)
 
-	// Step 3: Detect ATS platforms for each career page (parallel)
-	atsDetectionFutures := make([]workflow.Future, 0, len(allCareerPages))
-	for _, page := range allCareerPages {
-		future := workflow.ExecuteActivity(ctx, "DetectATS", page.URL)
-		atsDetectionFutures = append(atsDetectionFutures, future)
-	}
+	// Step 3: Detect ATS platforms for each career page, bounded to conc
+	// in-flight calls and rate-limited under "http"
+	atsResults, atsErrs := concurrency.ForEachJob[CareerPageInfo, ATSInfo](ctx, allCareerPages, conc,
+		func(ctx workflow.Context, _ int, page CareerPageInfo) workflow.Future {
+			limiter.Wait(ctx, "http")
+			return workflow.ExecuteActivity(ctx, "DetectATS", page.URL)
+		})
 
-	// Collect ATS detection results
-	for _, future := range atsDetectionFutures {
-		var atsInfo ATSInfo
-		err := future.Get(ctx, &atsInfo)
-		if err != nil {
-			logger.Error("Failed to detect ATS", "error", err)
+	for i, atsInfo := range atsResults {
+		if atsErrs[i] != nil {
+			logger.Error("Failed to detect ATS", "error", atsErrs[i])
 			continue
 		}
-
 		if atsInfo.IsATS && atsInfo.Platform != "" {
 			result.ATSPlatforms[atsInfo.Platform]++
 		}
 	}
 
-	// Step 4: Queue all discovered URLs for crawling (store in DB)
+	// Step 4: Rank and dedup career pages (by canonical URL) before queueing,
+	// so the highest-scoring variant of any duplicate wins and Priority
+	// reflects ATS platform, path signals, confidence, and source trust
+	var rankedPages []CareerPageInfo
+	if err := workflow.ExecuteActivity(ctx, "RankCareerPages", allCareerPages).Get(ctx, &rankedPages); err != nil {
+		logger.Error("Failed to rank career pages, falling back to unranked list", "error", err)
+		rankedPages = allCareerPages
+	}
+	allCareerPages = rankedPages
+	result.CareerPagesFound = len(allCareerPages)
+
+	// Step 5: Queue all discovered URLs for crawling (store in DB)
 	queueFuture := workflow.ExecuteActivity(ctx, "QueueURLsForCrawling", allCareerPages)
 
-	var queued int
-	err := queueFuture.Get(ctx, &queued)
+	var queueStats QueueStats
+	err := queueFuture.Get(ctx, &queueStats)
 	if err != nil {
 		logger.Error("Failed to queue URLs", "error", err)
 	}
-	result.TotalURLsQueued = queued
-
-	// Step 5: Trigger CareerPageCrawlWorkflow for each discovered career page
-	logger.Info("Triggering crawl workflows for career pages", "count", len(allCareerPages))
-
-	// Create a map to group pages by company
-	pagesByCompany := make(map[string][]CareerPageInfo)
-	for _, page := range allCareerPages {
-		pagesByCompany[page.Domain] = append(pagesByCompany[page.Domain], page)
-	}
-
-	var crawlFutures []workflow.ChildWorkflowFuture
-	for domain, pages := range pagesByCompany {
-		// Find the company name for this domain
-		companyName := domain
-		for _, company := range allCompanies {
-			if company.Domain == domain {
-				companyName = company.Name
-				break
-			}
-		}
-
-		// Start a crawl workflow for each career page
-		for _, page := range pages {
-			crawlInput := CareerPageCrawlInput{
-				URL:         page.URL,
-				CompanyName: companyName,
-			}
-
-			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-				WorkflowID: "career-crawl-" + workflow.Now(ctx).Format("20060102150405") + "-" + page.Domain,
-			})
-
-			future := workflow.ExecuteChildWorkflow(childCtx, CareerPageCrawlWorkflow, crawlInput)
-			crawlFutures = append(crawlFutures, future)
-		}
-	}
-
-	// Wait for all crawl workflows to complete (don't block the main workflow)
-	// We'll just count successes
-	crawlSuccesses := 0
-	for _, future := range crawlFutures {
-		var crawlResult CareerPageCrawlResult
-		if getErr := future.Get(ctx, &crawlResult); getErr != nil {
-			logger.Error("Crawl workflow failed", "error", getErr)
-		} else if crawlResult.Success {
-			crawlSuccesses++
-		}
-	}
-
-	logger.Info("Crawl workflows completed", "success", crawlSuccesses, "total", len(crawlFutures))
-
+	logger.Info("Queued URLs for crawling",
+		"attempted", queueStats.Attempted, "inserted", queueStats.Inserted,
+		"updated", queueStats.Updated, "skipped", queueStats.Skipped)
+	result.TotalURLsQueued = queueStats.Inserted + queueStats.Updated
+
+	// QueueURLsForCrawling's INSERT already woke any crawlqueue.Acquirer
+	// listening for these rows (see its pg_notify call), and whichever crawl
+	// workers are online will claim and run CareerPageCrawlWorkflow for each
+	// one at their own pace. This workflow's job is done as soon as the URLs
+	// are queued, so it returns here instead of waiting on every crawl to
+	// finish — that decouples discovery throughput from crawl worker
+	// capacity, and lets a crawl survive this workflow's worker restarting.
 	result.Duration = workflow.Now(ctx).Sub(startTime)
 
 	logger.Info("CompanyDiscoveryWorkflow completed",
 		"companies", result.CompaniesFound,
 		"career_pages", result.CareerPagesFound,
 		"urls_queued", result.TotalURLsQueued,
-		"crawls_triggered", len(crawlFutures),
-		"crawls_successful", crawlSuccesses,
 		"duration", result.Duration)
 
 	return result, nil
 }
 
+// continuousDiscoveryDefaultInterval is how long ContinuousDiscoveryWorkflow
+// waits between runs when ContinuousDiscoveryInput.RunInterval is unset.
+const continuousDiscoveryDefaultInterval = 6 * time.Hour
+
+// continuousDiscoveryContinueAsNewAfter bounds how many runs
+// ContinuousDiscoveryWorkflow executes before calling
+// workflow.ContinueAsNew, keeping history from growing unbounded across
+// what is otherwise an indefinitely long-running loop.
+const continuousDiscoveryContinueAsNewAfter = 20
+
 // ContinuousDiscoveryInput defines input for continuous discovery
 type ContinuousDiscoveryInput struct {
 	GitHubQuery        string // Query for GitHub discovery
@@ -209,14 +224,124 @@ type ContinuousDiscoveryInput struct {
 	MaxNewCompanies    int    // Max new companies to discover per run
 	RunGitHubDiscovery bool   // Whether to run GitHub discovery
 	RunDorkDiscovery   bool   // Whether to run Google Dork discovery
+
+	// RunInterval is how long the workflow waits between runs; <= 0 falls
+	// back to continuousDiscoveryDefaultInterval.
+	RunInterval time.Duration
+
+	// PendingSeeds and PendingDorkQueries carry signal-pushed work forward
+	// across workflow.ContinueAsNew; a fresh start leaves them empty.
+	PendingSeeds       []CompanyInfo
+	PendingDorkQueries []string
+	// Paused carries pause state forward across ContinueAsNew.
+	Paused bool
+	// LastRunStats carries the previous iteration's stats forward so
+	// GetLastRunStats keeps answering correctly right after a
+	// ContinueAsNew.
+	LastRunStats ContinuousDiscoveryRunStats
+
+	// AbandonChildrenOnCancel, when true, sets ParentClosePolicy ABANDON on
+	// every discovery child this workflow spawns, so cancelling it doesn't
+	// also terminate a discovery run still in flight.
+	AbandonChildrenOnCancel bool
+}
+
+// ContinuousDiscoveryRunStats summarizes one ContinuousDiscoveryWorkflow iteration.
+type ContinuousDiscoveryRunStats struct {
+	RanAt                   time.Time
+	StaleCompaniesProcessed int
+	SeedCompaniesProcessed  int
+	DorkQueriesProcessed    int
+	NewCompaniesFound       int
+	TotalURLsQueued         int
+}
+
+// applyContinuousDiscoveryConfig copies the config portion of newConfig onto
+// config, leaving the carry-forward fields (PendingSeeds,
+// PendingDorkQueries, Paused, LastRunStats) untouched so an UpdateConfig
+// signal can't drop work queued by other signals.
+func applyContinuousDiscoveryConfig(config *ContinuousDiscoveryInput, newConfig ContinuousDiscoveryInput) {
+	config.GitHubQuery = newConfig.GitHubQuery
+	config.DorkQuery = newConfig.DorkQuery
+	config.StaleThresholdDays = newConfig.StaleThresholdDays
+	config.MaxNewCompanies = newConfig.MaxNewCompanies
+	config.RunGitHubDiscovery = newConfig.RunGitHubDiscovery
+	config.RunDorkDiscovery = newConfig.RunDorkDiscovery
+	config.RunInterval = newConfig.RunInterval
 }
 
-// ContinuousDiscoveryWorkflow runs on a cron schedule to continuously discover companies and jobs
-// This workflow is meant to be scheduled and run periodically
+// ContinuousDiscoveryWorkflow is a long-running workflow that repeatedly
+// re-crawls stale companies and runs configured discovery strategies,
+// sleeping between runs via workflow.NewTimer. Operators can redirect it
+// without redeploying or killing the schedule via signals:
+//
+//   - AddSeedCompany(CompanyInfo): queue a company for the next run
+//   - AddDorkQuery(string): queue an extra Google dork keyword for the next run
+//   - UpdateConfig(ContinuousDiscoveryInput): replace the run configuration
+//   - PauseDiscovery() / ResumeDiscovery(): suspend/resume the run loop
+//
+// and can be inspected via queries: GetPendingSeeds() and GetLastRunStats().
+//
+//nolint:gocyclo // signal/query wiring plus the run loop is inherently branchy
 func ContinuousDiscoveryWorkflow(ctx workflow.Context, input ContinuousDiscoveryInput) error {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Starting ContinuousDiscoveryWorkflow", "days_threshold", input.StaleThresholdDays)
 
+	config := input
+	pendingSeeds := input.PendingSeeds
+	pendingDorkQueries := input.PendingDorkQueries
+	paused := input.Paused
+	lastStats := input.LastRunStats
+
+	addSeedCh := workflow.GetSignalChannel(ctx, "AddSeedCompany")
+	addDorkCh := workflow.GetSignalChannel(ctx, "AddDorkQuery")
+	updateConfigCh := workflow.GetSignalChannel(ctx, "UpdateConfig")
+	pauseCh := workflow.GetSignalChannel(ctx, "PauseDiscovery")
+	resumeCh := workflow.GetSignalChannel(ctx, "ResumeDiscovery")
+
+	if err := workflow.SetQueryHandler(ctx, "GetPendingSeeds", func() ([]CompanyInfo, error) {
+		return pendingSeeds, nil
+	}); err != nil {
+		return fmt.Errorf("failed to register GetPendingSeeds query handler: %w", err)
+	}
+	if err := workflow.SetQueryHandler(ctx, "GetLastRunStats", func() (ContinuousDiscoveryRunStats, error) {
+		return lastStats, nil
+	}); err != nil {
+		return fmt.Errorf("failed to register GetLastRunStats query handler: %w", err)
+	}
+
+	// applySignals drains every signal channel's buffered messages, without
+	// blocking, into the loop's state, so seeds/queries/config/pause changes
+	// pushed at any point are picked up at the next opportunity.
+	applySignals := func() {
+		for {
+			var company CompanyInfo
+			if addSeedCh.ReceiveAsync(&company) {
+				pendingSeeds = append(pendingSeeds, company)
+				continue
+			}
+			var query string
+			if addDorkCh.ReceiveAsync(&query) {
+				pendingDorkQueries = append(pendingDorkQueries, query)
+				continue
+			}
+			var newConfig ContinuousDiscoveryInput
+			if updateConfigCh.ReceiveAsync(&newConfig) {
+				applyContinuousDiscoveryConfig(&config, newConfig)
+				continue
+			}
+			if pauseCh.ReceiveAsync(nil) {
+				paused = true
+				continue
+			}
+			if resumeCh.ReceiveAsync(nil) {
+				paused = false
+				continue
+			}
+			break
+		}
+	}
+
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Minute,
 		RetryPolicy: &temporal.RetryPolicy{
@@ -228,28 +353,132 @@ func ContinuousDiscoveryWorkflow(ctx workflow.Context, input ContinuousDiscovery
 	}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
-	// Step 1: Find stale companies that need re-crawling
+	for iteration := 0; iteration < continuousDiscoveryContinueAsNewAfter; iteration++ {
+		applySignals()
+
+		if paused {
+			logger.Info("Discovery paused, waiting for a ResumeDiscovery signal")
+			resumeCh.Receive(ctx, nil)
+			paused = false
+			applySignals()
+			continue
+		}
+
+		seeds := pendingSeeds
+		dorkQueries := pendingDorkQueries
+		pendingSeeds = nil
+		pendingDorkQueries = nil
+
+		stats, err := runContinuousDiscoveryIteration(ctx, config, seeds, dorkQueries)
+		if err != nil {
+			logger.Error("Discovery iteration failed", "error", err)
+		}
+		lastStats = stats
+
+		applySignals()
+		if paused {
+			continue
+		}
+
+		interval := config.RunInterval
+		if interval <= 0 {
+			interval = continuousDiscoveryDefaultInterval
+		}
+
+		timer := workflow.NewTimer(ctx, interval)
+		timerFired := false
+		selector := workflow.NewSelector(ctx)
+		selector.AddFuture(timer, func(workflow.Future) { timerFired = true })
+		selector.AddReceive(addSeedCh, func(c workflow.ReceiveChannel, _ bool) {
+			var company CompanyInfo
+			c.Receive(ctx, &company)
+			pendingSeeds = append(pendingSeeds, company)
+		})
+		selector.AddReceive(addDorkCh, func(c workflow.ReceiveChannel, _ bool) {
+			var query string
+			c.Receive(ctx, &query)
+			pendingDorkQueries = append(pendingDorkQueries, query)
+		})
+		selector.AddReceive(updateConfigCh, func(c workflow.ReceiveChannel, _ bool) {
+			var newConfig ContinuousDiscoveryInput
+			c.Receive(ctx, &newConfig)
+			applyContinuousDiscoveryConfig(&config, newConfig)
+		})
+		selector.AddReceive(pauseCh, func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, nil)
+			paused = true
+		})
+		for !timerFired && !paused {
+			selector.Select(ctx)
+		}
+	}
+
+	logger.Info("ContinuousDiscoveryWorkflow continuing as new", "iterations", continuousDiscoveryContinueAsNewAfter)
+
+	nextInput := config
+	nextInput.PendingSeeds = pendingSeeds
+	nextInput.PendingDorkQueries = pendingDorkQueries
+	nextInput.Paused = paused
+	nextInput.LastRunStats = lastStats
+	return workflow.NewContinueAsNewError(ctx, ContinuousDiscoveryWorkflow, nextInput)
+}
+
+// runContinuousDiscoveryIteration runs one pass of re-crawling stale
+// companies (merged with any signal-pushed seeds), plus the configured
+// GitHub/Dork discovery strategies and any signal-pushed extra dork
+// queries, and waits for every spawned child workflow to finish.
+func runContinuousDiscoveryIteration(
+	ctx workflow.Context,
+	config ContinuousDiscoveryInput,
+	seeds []CompanyInfo,
+	dorkQueries []string,
+) (ContinuousDiscoveryRunStats, error) {
+	logger := workflow.GetLogger(ctx)
+	stats := ContinuousDiscoveryRunStats{RanAt: workflow.Now(ctx)}
+
+	// childID didn't exist when earlier executions of this workflow started;
+	// version 0 reproduces each call site's exact pre-childID WorkflowID on
+	// replay (legacyID below), and only new executions (version 1) switch to
+	// childWorkflowOptions.
+	idVersion := workflow.GetVersion(ctx, childIDVersionChangeID, workflow.DefaultVersion, 1)
+	legacyID := func(raw string) workflow.ChildWorkflowOptions {
+		return workflow.ChildWorkflowOptions{WorkflowID: raw}
+	}
+	childOpts := func(prefix, domain string) workflow.ChildWorkflowOptions {
+		return childWorkflowOptions(ctx, prefix, domain, config.AbandonChildrenOnCancel)
+	}
+
 	var staleCompanies []CompanyInfo
-	err := workflow.ExecuteActivity(ctx, "GetStaleCompanies", input.StaleThresholdDays).Get(ctx, &staleCompanies)
-	if err != nil {
+	if err := workflow.ExecuteActivity(ctx, "GetStaleCompanies", config.StaleThresholdDays).Get(ctx, &staleCompanies); err != nil {
 		logger.Error("Failed to get stale companies", "error", err)
-		return err
+		return stats, err
 	}
+	stats.StaleCompaniesProcessed = len(staleCompanies)
+	stats.SeedCompaniesProcessed = len(seeds)
+	stats.DorkQueriesProcessed = len(dorkQueries)
+
+	logger.Info("Running discovery iteration",
+		"stale_companies", len(staleCompanies), "seed_companies", len(seeds), "pending_dork_queries", len(dorkQueries))
 
-	logger.Info("Found stale companies to re-crawl", "count", len(staleCompanies))
+	companies := make([]CompanyInfo, 0, len(staleCompanies)+len(seeds))
+	companies = append(companies, staleCompanies...)
+	companies = append(companies, seeds...)
 
-	// Step 2: Trigger discovery workflow for each stale company
-	discoveryFutures := make([]workflow.ChildWorkflowFuture, 0, len(staleCompanies)+2)
-	for _, company := range staleCompanies {
+	discoveryFutures := make([]workflow.ChildWorkflowFuture, 0, len(companies)+2)
+	for _, company := range companies {
 		discoveryInput := DiscoveryInput{
 			Query:      company.Domain,
 			Sources:    []string{"manual"},
 			MaxResults: 10,
 		}
 
-		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-			WorkflowID: "stale-company-" + company.Domain + "-" + workflow.Now(ctx).Format("20060102-150405"),
-		})
+		var opts workflow.ChildWorkflowOptions
+		if idVersion == workflow.DefaultVersion {
+			opts = legacyID("stale-company-" + company.Domain + "-" + workflow.Now(ctx).Format("20060102-150405"))
+		} else {
+			opts = childOpts("stale-company", company.Domain)
+		}
+		childCtx := workflow.WithChildOptions(ctx, opts)
 
 		future := workflow.ExecuteChildWorkflow(childCtx, CompanyDiscoveryWorkflow, discoveryInput)
 		discoveryFutures = append(discoveryFutures, future)
@@ -258,58 +487,74 @@ func ContinuousDiscoveryWorkflow(ctx workflow.Context, input ContinuousDiscovery
 		_ = workflow.ExecuteActivity(ctx, "UpdateCompanyLastCrawled", company.Domain)
 	}
 
-	// Step 3: Run discovery strategies based on configuration
-	if input.RunGitHubDiscovery {
+	// Run discovery strategies based on configuration
+	if config.RunGitHubDiscovery {
 		logger.Info("Running GitHub discovery strategy")
 		githubInput := DiscoveryInput{
-			Query:      input.GitHubQuery,
+			Query:      config.GitHubQuery,
 			Sources:    []string{"github"},
-			MaxResults: input.MaxNewCompanies,
+			MaxResults: config.MaxNewCompanies,
 		}
 
-		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-			WorkflowID: "github-discovery-" + workflow.Now(ctx).Format("20060102-150405"),
-		})
+		var opts workflow.ChildWorkflowOptions
+		if idVersion == workflow.DefaultVersion {
+			opts = legacyID("github-discovery-" + workflow.Now(ctx).Format("20060102-150405"))
+		} else {
+			opts = childOpts("github-discovery", "")
+		}
+		childCtx := workflow.WithChildOptions(ctx, opts)
 
 		future := workflow.ExecuteChildWorkflow(childCtx, CompanyDiscoveryWorkflow, githubInput)
 		discoveryFutures = append(discoveryFutures, future)
 	}
 
-	if input.RunDorkDiscovery {
+	if config.RunDorkDiscovery {
 		logger.Info("Running Google Dork discovery strategy")
-		dorkInput := DiscoveryInput{
-			Query:      input.DorkQuery,
-			Sources:    []string{"google_dork"},
-			MaxResults: input.MaxNewCompanies,
+		var opts workflow.ChildWorkflowOptions
+		if idVersion == workflow.DefaultVersion {
+			opts = legacyID("dork-discovery-" + workflow.Now(ctx).Format("20060102-150405"))
+		} else {
+			opts = childOpts("dork-discovery", "")
 		}
+		childCtx := workflow.WithChildOptions(ctx, opts)
 
-		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-			WorkflowID: "dork-discovery-" + workflow.Now(ctx).Format("20060102-150405"),
-		})
+		future := workflow.ExecuteChildWorkflow(childCtx, GoogleDorkDiscoveryWorkflow, config.DorkQuery)
+		discoveryFutures = append(discoveryFutures, future)
+	}
 
-		future := workflow.ExecuteChildWorkflow(childCtx, CompanyDiscoveryWorkflow, dorkInput)
+	for i, query := range dorkQueries {
+		logger.Info("Running signal-pushed dork query", "query", query)
+		var opts workflow.ChildWorkflowOptions
+		if idVersion == workflow.DefaultVersion {
+			opts = legacyID(fmt.Sprintf("dork-signal-%d-%s", i, workflow.Now(ctx).Format("20060102-150405")))
+		} else {
+			opts = childOpts("dork-signal", fmt.Sprintf("%d", i))
+		}
+		childCtx := workflow.WithChildOptions(ctx, opts)
+
+		future := workflow.ExecuteChildWorkflow(childCtx, GoogleDorkDiscoveryWorkflow, query)
 		discoveryFutures = append(discoveryFutures, future)
 	}
 
 	// Wait for all discovery workflows to complete
-	totalCompanies := 0
-	totalURLs := 0
 	for _, future := range discoveryFutures {
 		var result DiscoveryResult
 		if err := future.Get(ctx, &result); err != nil {
 			logger.Error("Discovery workflow failed", "error", err)
 			continue
 		}
-		totalCompanies += result.CompaniesFound
-		totalURLs += result.TotalURLsQueued
+		stats.NewCompaniesFound += result.CompaniesFound
+		stats.TotalURLsQueued += result.TotalURLsQueued
 	}
 
-	logger.Info("ContinuousDiscoveryWorkflow completed",
-		"stale_companies_processed", len(staleCompanies),
-		"new_companies_found", totalCompanies,
-		"total_urls_queued", totalURLs)
+	logger.Info("Discovery iteration completed",
+		"stale_companies_processed", stats.StaleCompaniesProcessed,
+		"seed_companies_processed", stats.SeedCompaniesProcessed,
+		"dork_queries_processed", stats.DorkQueriesProcessed,
+		"new_companies_found", stats.NewCompaniesFound,
+		"total_urls_queued", stats.TotalURLsQueued)
 
-	return nil
+	return stats, nil
 }
 
 // GoogleDorkDiscoveryWorkflow specifically for Google dork-based discovery
@@ -344,19 +589,22 @@ func GoogleDorkDiscoveryWorkflow(ctx workflow.Context, keyword string) (*Discove
 
 	logger.Info("Generated dork queries", "count", len(dorkQueries))
 
-	// Step 2: Execute each dork query in parallel
-	futures := make([]workflow.Future, 0, len(dorkQueries))
-	for _, query := range dorkQueries {
-		future := workflow.ExecuteActivity(ctx, "ExecuteDorkQuery", query, 100)
-		futures = append(futures, future)
-	}
+	limiter := concurrency.NewRateLimiter(nil)
+
+	// Step 2: Execute each dork query, bounded to defaultFanOutConcurrency
+	// in-flight calls. limiter has no QPS configured for any source here, so
+	// its "google" and "http" Wait calls below never throttle.
+	urlResults, urlErrs := concurrency.ForEachJob[string, []string](ctx, dorkQueries, defaultFanOutConcurrency,
+		func(ctx workflow.Context, _ int, query string) workflow.Future {
+			limiter.Wait(ctx, "google")
+			return workflow.ExecuteActivity(ctx, "ExecuteDorkQuery", query, 100)
+		})
 
 	// Step 3: Collect all results
 	var allURLs []string
-	for _, future := range futures {
-		var urls []string
-		if getErr := future.Get(ctx, &urls); getErr != nil {
-			logger.Error("Dork query failed", "error", getErr)
+	for i, urls := range urlResults {
+		if urlErrs[i] != nil {
+			logger.Error("Dork query failed", "error", urlErrs[i])
 			continue
 		}
 		allURLs = append(allURLs, urls...)
@@ -364,36 +612,49 @@ func GoogleDorkDiscoveryWorkflow(ctx workflow.Context, keyword string) (*Discove
 
 	logger.Info("Total URLs found from dorks", "count", len(allURLs))
 
-	// Step 4: Detect ATS and extract domains
-	detectionFutures := make([]workflow.Future, 0, len(allURLs))
-	for _, url := range allURLs {
-		future := workflow.ExecuteActivity(ctx, "DetectATSAndExtractDomain", url)
-		detectionFutures = append(detectionFutures, future)
-	}
+	// Step 4: Detect ATS and extract domains via the "ats" provider, bounded
+	// to defaultFanOutConcurrency in-flight calls
+	detectionResults, detectionErrs := concurrency.ForEachJob[string, DiscoveryProviderResult](ctx, allURLs, defaultFanOutConcurrency,
+		func(ctx workflow.Context, _ int, url string) workflow.Future {
+			limiter.Wait(ctx, "http")
+			req := DiscoveryProviderRequest{URL: url}
+			return workflow.ExecuteActivity(ctx, "DiscoverFromProvider", "ats", req)
+		})
 
 	// Step 5: Collect detection results
 	discoveredPages := make([]CareerPageInfo, 0, len(allURLs))
-	for _, future := range detectionFutures {
-		var pageInfo CareerPageInfo
-		if getErr := future.Get(ctx, &pageInfo); getErr != nil {
+	for i, res := range detectionResults {
+		if detectionErrs[i] != nil {
 			continue
 		}
-		discoveredPages = append(discoveredPages, pageInfo)
+		discoveredPages = append(discoveredPages, res.CareerPages...)
 
-		if pageInfo.ATSPlatform != "" {
-			result.ATSPlatforms[pageInfo.ATSPlatform]++
+		for _, page := range res.CareerPages {
+			if page.ATSPlatform != "" {
+				result.ATSPlatforms[page.ATSPlatform]++
+			}
 		}
 	}
 
+	// Step 6: Rank and dedup career pages before queueing
+	var rankedPages []CareerPageInfo
+	if err := workflow.ExecuteActivity(ctx, "RankCareerPages", discoveredPages).Get(ctx, &rankedPages); err != nil {
+		logger.Error("Failed to rank career pages, falling back to unranked list", "error", err)
+		rankedPages = discoveredPages
+	}
+	discoveredPages = rankedPages
 	result.CareerPagesFound = len(discoveredPages)
 
-	// Step 6: Queue for crawling
-	var queued int
-	err = workflow.ExecuteActivity(ctx, "QueueURLsForCrawling", discoveredPages).Get(ctx, &queued)
+	// Step 7: Queue for crawling
+	var queueStats QueueStats
+	err = workflow.ExecuteActivity(ctx, "QueueURLsForCrawling", discoveredPages).Get(ctx, &queueStats)
 	if err != nil {
 		logger.Error("Failed to queue URLs", "error", err)
 	}
-	result.TotalURLsQueued = queued
+	logger.Info("Queued URLs for crawling",
+		"attempted", queueStats.Attempted, "inserted", queueStats.Inserted,
+		"updated", queueStats.Updated, "skipped", queueStats.Skipped)
+	result.TotalURLsQueued = queueStats.Inserted + queueStats.Updated
 
 	result.Duration = workflow.Now(ctx).Sub(startTime)
 
@@ -422,6 +683,10 @@ type CareerPageInfo struct {
 	ATSPlatform string
 	Confidence  float64
 	Priority    int
+	Source      string
+	// Skills carries the skill tags DetectATS's underlying parse found, if
+	// any; see ATSInfo.Skills.
+	Skills []string
 }
 
 // ATSInfo contains information about a detected ATS platform.
@@ -430,4 +695,34 @@ type ATSInfo struct {
 	Platform   string
 	Confidence float64
 	IsATS      bool
+	// Skills holds keyword tags the OSINT service's parser extracted from
+	// the job listing at URL, empty when it didn't run a skills pass.
+	Skills []string
+}
+
+// DiscoveryProviderRequest mirrors activities.DiscoveryRequest, the uniform
+// input to the "DiscoverFromProvider" activity. Which fields matter depends
+// on the provider: company-discovery sources read Query, subdomain
+// enumeration reads Domain, ATS detection reads URL.
+type DiscoveryProviderRequest struct {
+	Query      string
+	Domain     string
+	URL        string
+	MaxResults int
+}
+
+// DiscoveryProviderResult mirrors activities.DiscoveryProviderResult, the
+// result of the "DiscoverFromProvider" activity.
+type DiscoveryProviderResult struct {
+	Companies   []CompanyInfo
+	CareerPages []CareerPageInfo
+}
+
+// QueueStats mirrors activities.QueueStats, the result of the
+// "QueueURLsForCrawling" activity.
+type QueueStats struct {
+	Attempted int
+	Inserted  int
+	Updated   int
+	Skipped   int
 }
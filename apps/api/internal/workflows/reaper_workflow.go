@@ -0,0 +1,95 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// reaperDefaultTickInterval is how often ReaperWorkflow sweeps for stuck
+// CareerPageCrawlWorkflow executions when ReaperInput.TickInterval is unset.
+const reaperDefaultTickInterval = 5 * time.Minute
+
+// reaperDefaultStaleAfter is how long a CareerPageCrawlWorkflow can run
+// before ReaperWorkflow considers it stuck, when ReaperInput.StaleAfter is
+// unset. This must stay comfortably above CareerPageCrawlWorkflow's own
+// activity timeouts plus retries, or the reaper would cancel crawls that are
+// merely slow rather than actually stuck.
+const reaperDefaultStaleAfter = 30 * time.Minute
+
+// reaperContinueAsNewAfter bounds how many sweeps ReaperWorkflow runs before
+// calling workflow.ContinueAsNew, the same history-growth guard
+// SchedulerWorkflow and ContinuousDiscoveryWorkflow use.
+const reaperContinueAsNewAfter = 500
+
+// ReaperInput defines input for ReaperWorkflow.
+type ReaperInput struct {
+	// TickInterval is how often to sweep for stuck crawls. <= 0 falls back
+	// to reaperDefaultTickInterval.
+	TickInterval time.Duration
+
+	// StaleAfter is how long a CareerPageCrawlWorkflow must have been
+	// running before it's cancelled; heartbeat progress is not checked.
+	// <= 0 falls back to reaperDefaultStaleAfter.
+	StaleAfter time.Duration
+}
+
+// ReaperWorkflow is the long-running sweep that recovers
+// CareerPageCrawlWorkflow executions a worker died or hung mid-crawl on,
+// the same role Coder's provisioner daemon plays reclaiming abandoned build
+// jobs. Each tick it lists executions older than StaleAfter via
+// FindStuckCareerPageCrawls and terminates each one via CancelStuckWorkflow,
+// then sleeps until the next tick. It runs indefinitely via
+// workflow.ContinueAsNew, the same pattern SchedulerWorkflow uses.
+func ReaperWorkflow(ctx workflow.Context, input ReaperInput) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting ReaperWorkflow")
+
+	tickInterval := input.TickInterval
+	if tickInterval <= 0 {
+		tickInterval = reaperDefaultTickInterval
+	}
+	staleAfter := input.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = reaperDefaultStaleAfter
+	}
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	for tick := 0; tick < reaperContinueAsNewAfter; tick++ {
+		olderThan := workflow.Now(ctx).Add(-staleAfter)
+
+		var stuck []activities.StuckWorkflow
+		if err := workflow.ExecuteActivity(ctx, "FindStuckCareerPageCrawls", olderThan).Get(ctx, &stuck); err != nil {
+			logger.Error("Failed to list stuck career page crawls", "error", err)
+		} else {
+			for _, wf := range stuck {
+				reason := "reaped by ReaperWorkflow: running since " + wf.StartedAt.Format(time.RFC3339)
+				err := workflow.ExecuteActivity(ctx, "CancelStuckWorkflow", wf.WorkflowID, wf.RunID, reason).Get(ctx, nil)
+				if err != nil {
+					logger.Error("Failed to cancel stuck workflow", "workflow_id", wf.WorkflowID, "run_id", wf.RunID, "error", err)
+				} else {
+					logger.Warn("Reaped stuck CareerPageCrawlWorkflow", "workflow_id", wf.WorkflowID, "run_id", wf.RunID, "started_at", wf.StartedAt)
+				}
+			}
+		}
+
+		if err := workflow.Sleep(ctx, tickInterval); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("ReaperWorkflow continuing as new", "ticks", reaperContinueAsNewAfter)
+	return workflow.NewContinueAsNewError(ctx, ReaperWorkflow, input)
+}
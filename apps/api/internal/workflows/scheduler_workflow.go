@@ -0,0 +1,108 @@
+package workflows
+
+import (
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/api/internal/activities"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// schedulerDefaultTickInterval is how often SchedulerWorkflow checks
+// crawl_schedules for due rows when SchedulerInput.TickInterval is unset.
+const schedulerDefaultTickInterval = time.Minute
+
+// schedulerContinueAsNewAfter bounds how many ticks SchedulerWorkflow runs
+// before calling workflow.ContinueAsNew, the same history-growth guard
+// ContinuousDiscoveryWorkflow uses.
+const schedulerContinueAsNewAfter = 500
+
+// SchedulerInput defines input for SchedulerWorkflow.
+type SchedulerInput struct {
+	// TickInterval is how often to check crawl_schedules for due rows.
+	// <= 0 falls back to schedulerDefaultTickInterval.
+	TickInterval time.Duration
+
+	// AbandonChildrenOnCancel, when true, sets ParentClosePolicy ABANDON on
+	// every CrawlCoordinatorWorkflow child this workflow starts, so
+	// cancelling the scheduler doesn't also terminate a crawl still in
+	// flight.
+	AbandonChildrenOnCancel bool
+}
+
+// SchedulerWorkflow is the long-running loop that replaced
+// ScheduledCrawlWorkflow's hardcoded platform list: on every tick it loads
+// due rows from crawl_schedules (via the LoadDueSchedules activity),
+// starts CrawlCoordinatorWorkflow as a child per row, and advances each
+// row's next_run_at (via MarkScheduleRun). Operators add, edit, pause, and
+// resume schedules at runtime through the API's CRUD endpoints rather than
+// redeploying this workflow. It runs indefinitely via
+// workflow.ContinueAsNew, the same pattern ContinuousDiscoveryWorkflow
+// uses for its own run loop.
+func SchedulerWorkflow(ctx workflow.Context, input SchedulerInput) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting SchedulerWorkflow")
+
+	tickInterval := input.TickInterval
+	if tickInterval <= 0 {
+		tickInterval = schedulerDefaultTickInterval
+	}
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	for tick := 0; tick < schedulerContinueAsNewAfter; tick++ {
+		var due []activities.CrawlSchedule
+		if err := workflow.ExecuteActivity(ctx, "LoadDueSchedules").Get(ctx, &due); err != nil {
+			logger.Error("Failed to load due schedules", "error", err)
+		} else if len(due) > 0 {
+			runSchedulerTick(ctx, logger, due, input.AbandonChildrenOnCancel)
+		}
+
+		if err := workflow.Sleep(ctx, tickInterval); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("SchedulerWorkflow continuing as new", "ticks", schedulerContinueAsNewAfter)
+	return workflow.NewContinueAsNewError(ctx, SchedulerWorkflow, input)
+}
+
+// runSchedulerTick starts a CrawlCoordinatorWorkflow child for every due
+// schedule and marks each one run, logging (rather than failing the tick)
+// any individual schedule's error so one bad row can't stall the rest.
+func runSchedulerTick(ctx workflow.Context, logger log.Logger, due []activities.CrawlSchedule, abandonOnCancel bool) {
+	ranAt := workflow.Now(ctx)
+
+	for _, schedule := range due {
+		crawlInput := JobCrawlInput{
+			Platform: schedule.Platform,
+			URLs:     schedule.SeedURLs,
+			MaxJobs:  schedule.MaxJobs,
+		}
+
+		childOpts := childWorkflowOptions(ctx, "scheduled-crawl", schedule.Platform, abandonOnCancel)
+		childCtx := workflow.WithChildOptions(ctx, childOpts)
+
+		var result JobCrawlResult
+		err := workflow.ExecuteChildWorkflow(childCtx, CrawlCoordinatorWorkflow, crawlInput).Get(childCtx, &result)
+		if err != nil {
+			logger.Error("Scheduled crawl failed", "schedule_id", schedule.ID, "platform", schedule.Platform, "error", err)
+		} else {
+			logger.Info("Scheduled crawl completed", "schedule_id", schedule.ID, "platform", schedule.Platform, "result", result)
+		}
+
+		if err := workflow.ExecuteActivity(ctx, "MarkScheduleRun", schedule.ID, ranAt).Get(ctx, nil); err != nil {
+			logger.Error("Failed to mark schedule run", "schedule_id", schedule.ID, "error", err)
+		}
+	}
+}
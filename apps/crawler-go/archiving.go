@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/archiver"
+)
+
+// ArchivingCrawler wraps another Crawler, archiving every successful fetch
+// to a WARC volume via Writer before returning CrawlURL's result
+// unchanged. Archiving is opt-in: wrap a backend in ArchivingCrawler only
+// when ArchiverConfig is configured (see main()), so a deployment without
+// it pays no extra cost.
+type ArchivingCrawler struct {
+	Crawler
+	Writer *archiver.WARCWriter
+}
+
+// NewArchivingCrawler wraps next so every fetch it performs is also
+// archived via writer.
+func NewArchivingCrawler(next Crawler, writer *archiver.WARCWriter) *ArchivingCrawler {
+	return &ArchivingCrawler{Crawler: next, Writer: writer}
+}
+
+func (c *ArchivingCrawler) CrawlURL(ctx context.Context, url string, opts CrawlOptions) (*CrawlResult, error) {
+	result, err := c.Crawler.CrawlURL(ctx, url, opts)
+	if result == nil || result.StatusCode == 0 {
+		// No real HTTP exchange to archive — StatusCode stays 0 when the
+		// fetch never got a response at all (DNS/connection failure, a
+		// canceled navigation, ...).
+		return result, err
+	}
+
+	archiveErr := c.Writer.Archive(archiver.FetchRecord{
+		JobID:       GenerateJobID(result.URL),
+		URL:         result.URL,
+		StatusCode:  result.StatusCode,
+		Headers:     result.Headers,
+		ContentType: result.ContentType,
+		Body:        []byte(result.HTML),
+		CrawledAt:   result.CrawledAt,
+	})
+	if archiveErr != nil {
+		// Archiving is best-effort provenance, not part of the crawl's
+		// success contract: a WARC write failure shouldn't fail (or
+		// retry) a crawl that otherwise succeeded.
+		result.Error = appendArchiveWarning(result.Error, archiveErr)
+	}
+
+	return result, err
+}
+
+func appendArchiveWarning(existing string, archiveErr error) string {
+	warning := "archiving: " + archiveErr.Error()
+	if existing == "" {
+		return warning
+	}
+	return existing + "; " + warning
+}
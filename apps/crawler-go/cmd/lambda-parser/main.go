@@ -0,0 +1,191 @@
+// Package main provides an AWS Lambda entry point that parses a single ATS
+// job URL into a JobListing, so URLs discovered by the main pipeline can be
+// fanned out via SQS into per-invocation parse jobs instead of running the
+// full Playwright-based crawler.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/itisrohit/quiethire/apps/crawler-go/parsers"
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/logging"
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fetchMode selects how a job page's HTML is obtained. Vanilla Lambda can't
+// launch a browser, so "headless" (plain net/http + goquery) is the default;
+// "playwright" delegates to an external browser container and must be
+// explicitly configured via PLAYWRIGHT_SERVICE_URL.
+type fetchMode string
+
+const (
+	fetchModeHeadless   fetchMode = "headless"
+	fetchModePlaywright fetchMode = "playwright"
+)
+
+// parseRequest is the shape of a single SQS message body, or the direct
+// invocation payload when not triggered via SQS.
+type parseRequest struct {
+	URL string `json:"url"`
+}
+
+func currentFetchMode() fetchMode {
+	if os.Getenv("PARSE_MODE") == string(fetchModePlaywright) {
+		return fetchModePlaywright
+	}
+	return fetchModeHeadless
+}
+
+// parseJob resolves and runs a single URL, returning its JobListing.
+func parseJob(ctx context.Context, url string) (*parsers.JobListing, error) {
+	if currentFetchMode() == fetchModePlaywright {
+		return parsePlaywrightContainer(ctx, url)
+	}
+	return parseHeadless(ctx, url)
+}
+
+// parseHeadless fetches url with plain net/http and parses it with whatever
+// registered parser matches, the only path available in vanilla Lambda.
+func parseHeadless(ctx context.Context, url string) (*parsers.JobListing, error) {
+	html, err := parsers.FetchHTML(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+
+	parser, err := parsers.Resolve(url)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Tracer.Start(ctx, "parser.Parse", trace.WithAttributes(attribute.String("parser.url", url)))
+	defer span.End()
+
+	job, err := parser.Parse(ctx, parsers.HTMLSource(url, html))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return job, err
+}
+
+// parsePlaywrightContainer delegates to an external Playwright-capable
+// service for URLs that need JS rendering; only reachable when
+// PLAYWRIGHT_SERVICE_URL is set, since Lambda itself can't run a browser.
+func parsePlaywrightContainer(ctx context.Context, url string) (*parsers.JobListing, error) {
+	serviceURL := os.Getenv("PLAYWRIGHT_SERVICE_URL")
+	if serviceURL == "" {
+		return nil, fmt.Errorf("PARSE_MODE=playwright requires PLAYWRIGHT_SERVICE_URL to be set")
+	}
+
+	payload, err := json.Marshal(parseRequest{URL: url})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serviceURL+"/parse", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling playwright service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("playwright service returned status %d", resp.StatusCode)
+	}
+
+	var job parsers.JobListing
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("decoding playwright service response: %w", err)
+	}
+
+	return &job, nil
+}
+
+// skillsExtractor tags every parsed JobListing with the built-in skill
+// vocabulary before it's returned to the caller.
+var skillsExtractor = parsers.NewSkillsExtractor(nil)
+
+// handleDirect runs a single direct-invocation payload (URL in, JobListing out).
+func handleDirect(ctx context.Context, req parseRequest) (*parsers.JobListing, error) {
+	if req.URL == "" {
+		return nil, fmt.Errorf("missing required field: url")
+	}
+
+	job, err := parseJob(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	skillsExtractor.Populate(job)
+	return job, nil
+}
+
+// handleSQS fans an SQS batch out into one parse job per message. A single
+// bad message is logged and skipped rather than failing the whole batch.
+func handleSQS(ctx context.Context, event events.SQSEvent) error {
+	for _, record := range event.Records {
+		var req parseRequest
+		if err := json.Unmarshal([]byte(record.Body), &req); err != nil {
+			slog.Error("skipping message: invalid body", "message_id", record.MessageId, "error", err)
+			continue
+		}
+
+		job, err := handleDirect(ctx, req)
+		if err != nil {
+			slog.Error("failed to parse url", "url", req.URL, "error", err)
+			continue
+		}
+
+		slog.Info("parsed job", "url", req.URL, "title", job.Title, "company", job.Company)
+	}
+
+	return nil
+}
+
+// handler dispatches between an SQS-triggered invocation and a direct
+// (URL in, JobListing out) invocation, based on the raw event shape.
+func handler(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var sqsEvent events.SQSEvent
+	if err := json.Unmarshal(raw, &sqsEvent); err == nil && len(sqsEvent.Records) > 0 {
+		return nil, handleSQS(ctx, sqsEvent)
+	}
+
+	var req parseRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("invalid invocation payload: %w", err)
+	}
+
+	return handleDirect(ctx, req)
+}
+
+func main() {
+	logging.Init(os.Getenv("LOG_PRETTY") == "true", os.Stderr)
+
+	shutdownTracing, err := tracing.Init(context.Background(), "quiethire-lambda-parser", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	if err != nil {
+		slog.Error("failed to configure tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if closeErr := shutdownTracing(context.Background()); closeErr != nil {
+			slog.Error("error shutting down tracing", "error", closeErr)
+		}
+	}()
+
+	lambda.Start(handler)
+}
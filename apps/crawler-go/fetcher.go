@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/proxy"
+)
+
+// directUserAgent is sent by HTTPCrawler and TorCrawler, the two backends
+// that issue a plain HTTP request rather than driving a real browser.
+const directUserAgent = "QuietHireCrawler/1.0 (+https://quiethire.example/bot)"
+
+// Crawler fetches a single URL and returns a CrawlResult. CrawlBatch picks
+// an implementation per URL via CrawlerForURL: PlaywrightCrawler for pages
+// that need JS rendering, HTTPCrawler for a fast plain-HTTP fetch, and
+// TorCrawler for .onion addresses or sites that block direct crawler
+// traffic.
+type Crawler interface {
+	CrawlURL(ctx context.Context, url string, opts CrawlOptions) (*CrawlResult, error)
+	Close() error
+}
+
+// CrawlerConfig selects which Crawler backend a given URL should use.
+type CrawlerConfig struct {
+	// ForceTorHosts routes these hosts (in addition to every .onion host)
+	// through torCrawler, for sites that block direct crawler traffic.
+	ForceTorHosts map[string]bool
+	// ForceHTTPHosts routes these hosts through httpCrawler instead of
+	// Playwright, for sites that don't need JS rendering — skipping
+	// Playwright's per-page overhead for plain HTML.
+	ForceHTTPHosts map[string]bool
+}
+
+// CrawlerForURL picks which of playwrightCrawler/httpCrawler/torCrawler
+// should handle rawURL, given cfg. torCrawler may be nil (Tor not
+// configured); an onion or force-Tor host then falls through to
+// playwrightCrawler rather than failing outright. An unparseable rawURL
+// also falls back to playwrightCrawler, the backend CrawlBatch used
+// unconditionally before this became pluggable.
+func CrawlerForURL(rawURL string, cfg CrawlerConfig, playwrightCrawler, httpCrawler, torCrawler Crawler) Crawler {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return playwrightCrawler
+	}
+	host := u.Hostname()
+
+	if torCrawler != nil && (strings.HasSuffix(host, ".onion") || cfg.ForceTorHosts[host]) {
+		return torCrawler
+	}
+	if httpCrawler != nil && cfg.ForceHTTPHosts[host] {
+		return httpCrawler
+	}
+	return playwrightCrawler
+}
+
+// HTTPCrawler fetches a URL with a plain net/http client — no JS
+// rendering, far cheaper than Playwright for pages that don't need it.
+type HTTPCrawler struct {
+	client *http.Client
+}
+
+// NewHTTPCrawler creates an HTTPCrawler bounding every fetch to timeout.
+func NewHTTPCrawler(timeout time.Duration) *HTTPCrawler {
+	return &HTTPCrawler{client: &http.Client{Timeout: timeout}}
+}
+
+func (c *HTTPCrawler) CrawlURL(ctx context.Context, rawURL string, _ CrawlOptions) (*CrawlResult, error) {
+	return fetchDirect(ctx, c.client, rawURL, "http")
+}
+
+func (c *HTTPCrawler) Close() error { return nil }
+
+// TorCrawler fetches a URL through a SOCKS5 proxy (normally a local Tor
+// daemon), for .onion addresses and sites that block direct crawler
+// traffic.
+type TorCrawler struct {
+	client *http.Client
+}
+
+// NewTorCrawler dials through the SOCKS5 proxy at proxyAddr (host:port,
+// e.g. a local tor daemon's "127.0.0.1:9050").
+func NewTorCrawler(proxyAddr string, timeout time.Duration) (*TorCrawler, error) {
+	dialer, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("building SOCKS5 dialer for %s: %w", proxyAddr, err)
+	}
+
+	transport := &http.Transport{}
+	if ctxDialer, ok := dialer.(interface {
+		DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+	}); ok {
+		transport.DialContext = ctxDialer.DialContext
+	} else {
+		transport.Dial = dialer.Dial
+	}
+
+	return &TorCrawler{client: &http.Client{Transport: transport, Timeout: timeout}}, nil
+}
+
+func (c *TorCrawler) CrawlURL(ctx context.Context, rawURL string, _ CrawlOptions) (*CrawlResult, error) {
+	return fetchDirect(ctx, c.client, rawURL, "tor")
+}
+
+func (c *TorCrawler) Close() error { return nil }
+
+// fetchDirect performs an HTTP GET through client and fills in a
+// CrawlResult. Shared by HTTPCrawler and TorCrawler, which only differ in
+// client.Transport; backend names which one for the span's attributes.
+func fetchDirect(ctx context.Context, client *http.Client, rawURL, backend string) (*CrawlResult, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "crawler.CrawlURL", trace.WithAttributes(
+		attribute.String("crawl.backend", backend),
+		attribute.String("crawl.url", rawURL),
+	))
+	defer span.End()
+
+	result := &CrawlResult{URL: rawURL, CrawledAt: time.Now()}
+	setTraceIDs(result, span)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("building request: %v", err)
+		span.RecordError(err)
+		return result, err
+	}
+	req.Header.Set("User-Agent", directUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("fetching: %v", err)
+		span.RecordError(err)
+		return result, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Error("closing response body", "url", rawURL, "error", closeErr)
+		}
+	}()
+
+	result.StatusCode = resp.StatusCode
+	if resp.Request != nil && resp.Request.URL != nil {
+		result.FinalURL = resp.Request.URL.String()
+	}
+	result.Headers = resp.Header
+	result.ContentType = resp.Header.Get("Content-Type")
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("reading body: %v", err)
+		span.RecordError(err)
+		return result, err
+	}
+	result.HTML = string(body)
+	result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	span.SetAttributes(attribute.Int("crawl.status_code", result.StatusCode), attribute.Bool("crawl.success", result.Success))
+	return result, nil
+}
@@ -6,29 +6,98 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/archiver"
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/bus"
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/logging"
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/tracing"
 	"github.com/joho/godotenv"
 	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type Crawler struct {
+// PlaywrightCrawler drives a real headless browser, for pages that need JS
+// rendering. It's the default Crawler CrawlerForURL falls back to.
+type PlaywrightCrawler struct {
 	pw      *playwright.Playwright
 	browser playwright.Browser
+
+	// artifacts persists debugging artifacts (currently: failure
+	// screenshots) via the same Storage backend ArchiverConfig configured
+	// for WARC volumes. Nil when archiving isn't configured, in which case
+	// CrawlURL just skips the screenshot.
+	artifacts *archiver.WARCWriter
 }
 
+// pendingRequestTrackerScript is injected into every page before
+// navigation so the network-idle heuristic in waitForNetworkIdle can read
+// window.__qhPendingRequests: a live count of in-flight fetch()/XHR calls,
+// incremented on start and decremented on completion.
+const pendingRequestTrackerScript = `(function() {
+  window.__qhPendingRequests = 0;
+  var origFetch = window.fetch;
+  if (origFetch) {
+    window.fetch = function() {
+      window.__qhPendingRequests++;
+      return origFetch.apply(this, arguments).finally(function() {
+        window.__qhPendingRequests--;
+      });
+    };
+  }
+  var OrigXHR = window.XMLHttpRequest;
+  function TrackedXHR() {
+    var xhr = new OrigXHR();
+    window.__qhPendingRequests++;
+    xhr.addEventListener('loadend', function() {
+      window.__qhPendingRequests--;
+    });
+    return xhr;
+  }
+  window.XMLHttpRequest = TrackedXHR;
+})();`
+
 type CrawlResult struct {
-	CrawledAt time.Time
-	URL       string
-	HTML      string
-	Title     string
-	Error     string
-	Success   bool
+	CrawledAt   time.Time
+	URL         string
+	FinalURL    string
+	StatusCode  int
+	Headers     http.Header
+	ContentType string
+	HTML        string
+	Title       string
+	Error       string
+	Success     bool
+	// TraceID and SpanID identify the CrawlURL span that produced this
+	// result, empty when tracing isn't configured (see tracing.Init), so
+	// downstream storage can correlate a job's crawl with its other
+	// lifecycle events (parse, archive, ...) in ClickHouse.
+	TraceID string
+	SpanID  string
+}
+
+// setTraceIDs copies span's trace/span IDs into result, leaving both fields
+// empty when tracing isn't configured — span.SpanContext() on a no-op
+// tracer's span is a zero-value SpanContext, and TraceID().String() on that
+// would otherwise hex-encode to a non-empty run of zeros rather than "".
+func setTraceIDs(result *CrawlResult, span trace.Span) {
+	if !span.SpanContext().IsValid() {
+		return
+	}
+	result.TraceID = span.SpanContext().TraceID().String()
+	result.SpanID = span.SpanContext().SpanID().String()
 }
 
-func NewCrawler() (*Crawler, error) {
+// NewPlaywrightCrawler installs (if needed) and launches a headless
+// Chromium instance.
+func NewPlaywrightCrawler() (*PlaywrightCrawler, error) {
 	// Install playwright browsers if not already installed
 	err := playwright.Install(&playwright.RunOptions{
 		Verbose: false,
@@ -52,18 +121,26 @@ func NewCrawler() (*Crawler, error) {
 	})
 	if err != nil {
 		if stopErr := pw.Stop(); stopErr != nil {
-			log.Printf("Error stopping playwright: %v", stopErr)
+			slog.Error("error stopping playwright", "error", stopErr)
 		}
 		return nil, fmt.Errorf("could not launch browser: %w", err)
 	}
 
-	return &Crawler{
+	return &PlaywrightCrawler{
 		pw:      pw,
 		browser: browser,
 	}, nil
 }
 
-func (c *Crawler) Close() error {
+// SetArtifactWriter configures writer as where CrawlURL persists a
+// screenshot when a crawl fails after the page has been created, for
+// debugging (see captureFailureScreenshot). Pass nil (the default) to skip
+// screenshots entirely.
+func (c *PlaywrightCrawler) SetArtifactWriter(writer *archiver.WARCWriter) {
+	c.artifacts = writer
+}
+
+func (c *PlaywrightCrawler) Close() error {
 	if c.browser != nil {
 		if err := c.browser.Close(); err != nil {
 			return err
@@ -77,21 +154,29 @@ func (c *Crawler) Close() error {
 	return nil
 }
 
-func (c *Crawler) CrawlURL(_ context.Context, url string) (*CrawlResult, error) {
+func (c *PlaywrightCrawler) CrawlURL(ctx context.Context, url string, opts CrawlOptions) (*CrawlResult, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "crawler.CrawlURL", trace.WithAttributes(
+		attribute.String("crawl.backend", "playwright"),
+		attribute.String("crawl.url", url),
+	))
+	defer span.End()
+
 	result := &CrawlResult{
 		URL:       url,
 		CrawledAt: time.Now(),
 	}
+	setTraceIDs(result, span)
 
 	// Create a new page
 	page, err := c.browser.NewPage()
 	if err != nil {
 		result.Error = fmt.Sprintf("could not create page: %v", err)
+		span.RecordError(err)
 		return result, err
 	}
 	defer func() {
 		if closeErr := page.Close(); closeErr != nil {
-			log.Printf("Error closing page: %v", closeErr)
+			slog.Error("error closing page", "error", closeErr)
 		}
 	}()
 
@@ -101,21 +186,49 @@ func (c *Crawler) CrawlURL(_ context.Context, url string) (*CrawlResult, error)
 		"Accept":     "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
 	}); headersErr != nil {
 		result.Error = fmt.Sprintf("could not set headers: %v", headersErr)
+		span.RecordError(headersErr)
 		return result, headersErr
 	}
 
+	// Installed before Goto so it's present from the first script the page
+	// runs; waitForNetworkIdle reads the counter it maintains.
+	if scriptErr := page.AddInitScript(playwright.Script{Content: playwright.String(pendingRequestTrackerScript)}); scriptErr != nil {
+		slog.Warn("could not install pending-request tracker, falling back to readyState only", "url", url, "error", scriptErr)
+	}
+
 	// Navigate to the URL
-	_, err = page.Goto(url, playwright.PageGotoOptions{
+	resp, err := page.Goto(url, playwright.PageGotoOptions{
 		WaitUntil: playwright.WaitUntilStateNetworkidle,
 		Timeout:   playwright.Float(30000),
 	})
 	if err != nil {
 		result.Error = fmt.Sprintf("could not navigate: %v", err)
+		span.RecordError(err)
+		c.captureFailureScreenshot(page, url)
 		return result, err
 	}
+	if resp != nil {
+		result.StatusCode = resp.Status()
+		result.FinalURL = resp.URL()
+		if headers, headersErr := resp.AllHeaders(); headersErr == nil {
+			h := make(http.Header, len(headers))
+			for k, v := range headers {
+				h.Set(k, v)
+			}
+			result.Headers = h
+			result.ContentType = h.Get("Content-Type")
+		}
+	}
 
-	// Wait for the page to load
-	time.Sleep(2 * time.Second)
+	// Wait for the page to finish rendering: try the ATS-specific
+	// selectors first (fast, precise), falling back to polling
+	// readyState/pending-request-count when they're absent or don't
+	// appear in time.
+	waitForRender(page, opts)
+
+	if opts.ScrollToBottom {
+		scrollToBottom(page)
+	}
 
 	// Get the page title
 	title, err := page.Title()
@@ -127,43 +240,161 @@ func (c *Crawler) CrawlURL(_ context.Context, url string) (*CrawlResult, error)
 	html, err := page.Content()
 	if err != nil {
 		result.Error = fmt.Sprintf("could not get content: %v", err)
+		span.RecordError(err)
+		c.captureFailureScreenshot(page, url)
 		return result, err
 	}
 
 	result.HTML = html
-	result.Success = true
+	result.Success = result.StatusCode == 0 || (result.StatusCode >= 200 && result.StatusCode < 300)
+	span.SetAttributes(attribute.Int("crawl.status_code", result.StatusCode), attribute.Bool("crawl.success", result.Success))
 
-	log.Printf("Successfully crawled: %s (title: %s)", url, title)
+	if !result.Success {
+		c.captureFailureScreenshot(page, url)
+	}
+
+	slog.Info("successfully crawled", "url", url, "title", title)
 
 	return result, nil
 }
 
-// CrawlBatch crawls multiple URLs sequentially
-func (c *Crawler) CrawlBatch(ctx context.Context, urls []string, delayMs int) []*CrawlResult {
-	results := make([]*CrawlResult, 0, len(urls))
+// captureFailureScreenshot takes a screenshot of page and persists it via
+// c.artifacts (when configured) under a name derived from rawURL, for
+// debugging a failed or unsuccessful crawl. Best-effort: a screenshot or
+// persist failure is logged, not returned, since it must never mask the
+// crawl error it was taken to help diagnose.
+func (c *PlaywrightCrawler) captureFailureScreenshot(page playwright.Page, rawURL string) {
+	if c.artifacts == nil {
+		return
+	}
+
+	shot, err := page.Screenshot(playwright.PageScreenshotOptions{FullPage: playwright.Bool(true)})
+	if err != nil {
+		slog.Warn("could not capture failure screenshot", "url", rawURL, "error", err)
+		return
+	}
+
+	name := fmt.Sprintf("failures/%s-%d.png", GenerateJobID(rawURL), time.Now().UnixNano())
+	if _, persistErr := c.artifacts.PersistArtifact(name, shot); persistErr != nil {
+		slog.Warn("could not persist failure screenshot", "url", rawURL, "error", persistErr)
+		return
+	}
+	slog.Info("persisted failure screenshot", "url", rawURL, "artifact", name)
+}
+
+// maxConcurrentCrawls bounds how many CrawlURL calls CrawlBatch ever has
+// in flight at once, across every host goroutine combined — without it, a
+// batch spanning hundreds of distinct hosts would open that many
+// Playwright pages on the single shared browser simultaneously.
+const maxConcurrentCrawls = 8
+
+// CrawlBatch crawls urls concurrently, one goroutine per distinct host so
+// that cross-host crawling parallelizes while same-host requests are
+// still issued one at a time (politeness manages per-host pacing, not
+// this function); a shared semaphore caps how many CrawlURL calls run at
+// once regardless of how many hosts are involved. selectCrawler picks the
+// backend for each URL (see CrawlerForURL); politeness may be nil to skip
+// robots/rate-limit/dedup checks entirely. The result slice always has
+// one entry per input URL, in input order, even for a URL skipped by
+// politeness (marked as a non-Success CrawlResult) or canceled via ctx.
+func CrawlBatch(ctx context.Context, urls []string, selectCrawler func(rawURL string) Crawler, politeness *PolitenessManager) []*CrawlResult {
+	byHost := make(map[string][]string)
+	var order []string
+	for _, u := range urls {
+		host := hostOf(u)
+		if _, ok := byHost[host]; !ok {
+			order = append(order, host)
+		}
+		byHost[host] = append(byHost[host], u)
+	}
+
+	resultsByURL := make(map[string]*CrawlResult, len(urls))
+	var mu sync.Mutex
+	semaphore := make(chan struct{}, maxConcurrentCrawls)
+
+	var wg sync.WaitGroup
+	for _, host := range order {
+		hostURLs := byHost[host]
+		wg.Add(1)
+		go func(hostURLs []string) {
+			defer wg.Done()
+			crawlHostSequentially(ctx, hostURLs, selectCrawler, politeness, semaphore, &mu, resultsByURL)
+		}(hostURLs)
+	}
+	wg.Wait()
+
+	results := make([]*CrawlResult, len(urls))
+	for i, u := range urls {
+		results[i] = resultsByURL[u]
+	}
+	return results
+}
+
+// crawlHostSequentially crawls one host's URLs one at a time, honoring
+// politeness (robots.txt, rate limit, in-flight dedup) between each, and
+// acquiring semaphore around the actual CrawlURL call so this host's
+// goroutine doesn't add to the batch's total concurrent crawl count
+// beyond maxConcurrentCrawls. Every url in urls gets an entry in results
+// before this returns, even one skipped or canceled.
+func crawlHostSequentially(ctx context.Context, urls []string, selectCrawler func(rawURL string) Crawler, politeness *PolitenessManager, semaphore chan struct{}, mu *sync.Mutex, results map[string]*CrawlResult) {
+	record := func(result *CrawlResult) {
+		mu.Lock()
+		results[result.URL] = result
+		mu.Unlock()
+	}
+	skipped := func(rawURL, reason string) *CrawlResult {
+		return &CrawlResult{URL: rawURL, CrawledAt: time.Now(), Error: reason}
+	}
 
-	for _, url := range urls {
-		// Check if context is canceled
+	for _, rawURL := range urls {
 		select {
 		case <-ctx.Done():
-			log.Println("Context canceled, stopping batch crawl")
-			return results
+			slog.Warn("context canceled, stopping batch crawl")
+			record(skipped(rawURL, "context canceled"))
+			continue
 		default:
 		}
 
-		result, err := c.CrawlURL(ctx, url)
+		if politeness != nil {
+			if !politeness.Claim(rawURL) {
+				record(skipped(rawURL, "already in flight"))
+				continue
+			}
+			if !politeness.Allowed(ctx, rawURL) {
+				slog.Info("skipping url: disallowed by robots.txt", "url", rawURL)
+				record(skipped(rawURL, "disallowed by robots.txt"))
+				politeness.Release(rawURL)
+				continue
+			}
+			if err := politeness.Wait(ctx, rawURL); err != nil {
+				record(skipped(rawURL, fmt.Sprintf("waiting for politeness: %v", err)))
+				politeness.Release(rawURL)
+				continue
+			}
+		}
+
+		semaphore <- struct{}{}
+		result, err := selectCrawler(rawURL).CrawlURL(ctx, rawURL, optionsForURL(rawURL))
+		<-semaphore
 		if err != nil {
-			log.Printf("Error crawling %s: %v", url, err)
+			slog.Error("error crawling url", "url", rawURL, "error", err)
 		}
-		results = append(results, result)
 
-		// Rate limiting delay between requests
-		if delayMs > 0 {
-			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		if politeness != nil {
+			politeness.Release(rawURL)
 		}
+		record(result)
 	}
+}
 
-	return results
+// hostOf returns rawURL's host, or rawURL itself if it can't be parsed, so
+// CrawlBatch still groups (and crawls) an unparseable URL on its own.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
 }
 
 // GenerateJobID generates a unique ID for a job based on URL using SHA256
@@ -173,25 +404,131 @@ func GenerateJobID(url string) string {
 }
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	// Load environment variables first so LOG_PRETTY/OTEL_EXPORTER_OTLP_ENDPOINT
+	// set only in .env (the same way TOR_PROXY_ADDR/BUS_KIND/etc. below are
+	// configured) are picked up by logging.Init/tracing.Init.
+	envErr := godotenv.Load()
+
+	// LOG_PRETTY=true switches from JSON log lines (the production default)
+	// to tinted, human-readable output for local development.
+	logging.Init(os.Getenv("LOG_PRETTY") == "true", os.Stderr)
+
+	if envErr != nil {
+		slog.Info("no .env file found")
+	}
+
+	ctx := context.Background()
+	shutdownTracing, err := tracing.Init(ctx, "quiethire-crawler", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	if err != nil {
+		slog.Error("failed to configure tracing", "error", err)
+		os.Exit(1)
 	}
+	defer func() {
+		if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+			slog.Error("error shutting down tracing", "error", shutdownErr)
+		}
+	}()
 
-	log.Println("🕷️  QuietHire Go Crawler starting...")
+	slog.Info("QuietHire Go Crawler starting")
 
-	// Create crawler
-	crawler, err := NewCrawler()
+	// Create the Playwright-backed crawler, the default backend
+	playwrightCrawler, err := NewPlaywrightCrawler()
 	if err != nil {
-		log.Fatalf("Failed to create crawler: %v", err)
+		slog.Error("failed to create crawler", "error", err)
+		os.Exit(1)
 	}
 	defer func() {
-		if closeErr := crawler.Close(); closeErr != nil {
-			log.Printf("Error closing crawler: %v", closeErr)
+		if closeErr := playwrightCrawler.Close(); closeErr != nil {
+			slog.Error("error closing crawler", "error", closeErr)
 		}
 	}()
 
-	log.Println("✅ Crawler initialized with Playwright")
+	slog.Info("crawler initialized with playwright")
+
+	httpCrawler := NewHTTPCrawler(30 * time.Second)
+
+	var torCrawler *TorCrawler
+	if torProxyAddr := os.Getenv("TOR_PROXY_ADDR"); torProxyAddr != "" {
+		torCrawler, err = NewTorCrawler(torProxyAddr, 30*time.Second)
+		if err != nil {
+			slog.Warn("tor crawler disabled, could not configure", "error", err)
+		} else {
+			slog.Info("tor crawler configured", "proxy_addr", torProxyAddr)
+		}
+	}
+
+	// ARCHIVER_KIND (and ARCHIVER_ROOT / ARCHIVER_BUCKET / ARCHIVER_PREFIX /
+	// ARCHIVER_REGION) opt every backend into writing a WARC record of each
+	// fetch for provenance and reparse-without-re-crawl. Archiving is
+	// skipped entirely when unset, so it costs nothing by default.
+	var playwrightC, httpC, tor Crawler = playwrightCrawler, httpCrawler, nil
+	if torCrawler != nil {
+		tor = torCrawler
+	}
+	if archiverKind := os.Getenv("ARCHIVER_KIND"); archiverKind != "" {
+		warcWriter, archiverErr := archiver.New(archiver.ArchiverConfig{
+			Kind:   archiverKind,
+			Root:   os.Getenv("ARCHIVER_ROOT"),
+			Bucket: os.Getenv("ARCHIVER_BUCKET"),
+			Prefix: os.Getenv("ARCHIVER_PREFIX"),
+			Region: os.Getenv("ARCHIVER_REGION"),
+		})
+		if archiverErr != nil {
+			slog.Error("failed to configure archiver", "error", archiverErr)
+			os.Exit(1)
+		}
+		defer func() {
+			if closeErr := warcWriter.Close(); closeErr != nil {
+				slog.Error("error flushing archiver", "error", closeErr)
+			}
+		}()
+
+		slog.Info("archiving fetches", "archiver_kind", archiverKind)
+		playwrightCrawler.SetArtifactWriter(warcWriter)
+		playwrightC = NewArchivingCrawler(playwrightCrawler, warcWriter)
+		httpC = NewArchivingCrawler(httpCrawler, warcWriter)
+		if torCrawler != nil {
+			tor = NewArchivingCrawler(torCrawler, warcWriter)
+		}
+	}
+
+	cfg := CrawlerConfig{ForceTorHosts: map[string]bool{}, ForceHTTPHosts: map[string]bool{}}
+	selectCrawler := func(rawURL string) Crawler {
+		return CrawlerForURL(rawURL, cfg, playwrightC, httpC, tor)
+	}
+
+	minDelay := defaultCrawlDelay
+	if ms := os.Getenv("CRAWL_MIN_DELAY_MS"); ms != "" {
+		if parsed, parseErr := strconv.Atoi(ms); parseErr == nil {
+			minDelay = time.Duration(parsed) * time.Millisecond
+		}
+	}
+	politeness := NewPolitenessManager(directUserAgent, minDelay, 0, 0)
+
+	// BUS_KIND (and BUS_ADDR) switch the crawler from the one-shot test
+	// crawl below into a long-running queue consumer: it subscribes to
+	// CrawlingQueueName and publishes each result to CrawledQueueName
+	// instead of crawling a single TEST_CRAWL_URL and exiting.
+	if busKind := os.Getenv("BUS_KIND"); busKind != "" {
+		messageBus, err := bus.New(bus.Config{Kind: busKind, Addr: os.Getenv("BUS_ADDR")}, bus.DefaultRetryConfig())
+		if err != nil {
+			slog.Error("failed to configure message bus", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if closeErr := messageBus.Close(); closeErr != nil {
+				slog.Error("error closing message bus", "error", closeErr)
+			}
+		}()
+
+		slog.Info("consuming queue", "queue", CrawlingQueueName, "bus_kind", busKind)
+		pipeline := NewPipeline(messageBus, selectCrawler, politeness)
+		if err := pipeline.Run(ctx); err != nil && err != context.Canceled {
+			slog.Error("crawl pipeline stopped", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Example: Crawl a test URL
 	testURL := os.Getenv("TEST_CRAWL_URL")
@@ -199,17 +536,21 @@ func main() {
 		testURL = "https://example.com"
 	}
 
-	ctx := context.Background()
-	result, err := crawler.CrawlURL(ctx, testURL)
-	if err != nil {
-		log.Printf("Crawl failed: %v", err)
+	results := CrawlBatch(ctx, []string{testURL}, selectCrawler, politeness)
+	if len(results) == 0 {
+		slog.Error("crawl failed: no result returned")
+	} else if result := results[0]; !result.Success {
+		slog.Error("crawl failed", "error", result.Error)
 	} else {
-		log.Printf("Crawl successful!")
-		log.Printf("  URL: %s", result.URL)
-		log.Printf("  Title: %s", result.Title)
-		log.Printf("  HTML length: %d bytes", len(result.HTML))
-		log.Printf("  Job ID: %s", GenerateJobID(result.URL))
+		slog.Info("crawl successful",
+			"url", result.URL,
+			"title", result.Title,
+			"status_code", result.StatusCode,
+			"html_bytes", len(result.HTML),
+			"job_id", GenerateJobID(result.URL),
+			"trace_id", result.TraceID,
+		)
 	}
 
-	log.Println("✅ Crawler test complete")
+	slog.Info("crawler test complete")
 }
@@ -1,87 +1,126 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
-	"github.com/playwright-community/playwright-go"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Selectors used to pull job fields out of an Ashby page.
+const (
+	ashbyTitleSelector        = "h1._jd_title_, h1[class*='title'], h1"
+	ashbyCompanySelector      = "div._jd_company_, [class*='company'], meta[property='og:site_name']"
+	ashbyLocationSelector     = "div._jd_location_, [class*='location'], span[class*='location']"
+	ashbyDescriptionSelector  = "div._jd_description_, div[class*='description'], div[class*='content']"
+	ashbySalarySelector       = "div._jd_salary_, [class*='salary'], [class*='compensation']"
+	ashbyJobTypeSelector      = "div._jd_type_, [class*='employment-type'], [class*='job-type']"
+	ashbyPostedSelector       = "time, [datetime], [class*='posted']"
+	ashbyRequirementsSelector = "div._jd_requirements_, [class*='requirements'], ul li"
 )
 
 // AshbyParser handles job parsing from Ashby ATS
 type AshbyParser struct{}
 
+// NewAshbyParser builds an AshbyParser. Wrap it with NewCachingParser to add
+// a short-TTL result cache in front of the parse.
+func NewAshbyParser() *AshbyParser {
+	return &AshbyParser{}
+}
+
 // CanParse checks if URL is an Ashby job page
 func (p *AshbyParser) CanParse(url string) bool {
 	return strings.Contains(url, "jobs.ashbyhq.com") ||
 		strings.Contains(url, ".ashbyhq.com/")
 }
 
-// Parse extracts job data from Ashby page
-func (p *AshbyParser) Parse(page playwright.Page, url string) (*JobData, error) {
-	job := &JobData{
-		URL:    url,
-		Source: "ashby",
+// Parse extracts job data from an Ashby page. src may wrap a live Playwright
+// page or already-fetched HTML: either way, Ashby renders these fields into
+// the DOM, so both reduce to an HTML string that's walked with goquery.
+func (p *AshbyParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	job := &JobListing{
+		URL:      src.URL,
+		Platform: "ashby",
+	}
+
+	// JSON-LD first: most Ashby pages embed a schema.org JobPosting block,
+	// which survives Ashby class name changes that would break the
+	// selector fallback below.
+	if ld, err := extractJSONLDFromHTML(htmlContent); err == nil {
+		mergeJobListing(job, ld)
 	}
 
 	// Extract job title
-	title, err := ExtractText(page, "h1._jd_title_, h1[class*='title'], h1")
-	if err == nil {
-		job.Title = CleanText(title)
+	if job.Title == "" {
+		if title, err := extractTextGoquery(doc, ashbyTitleSelector); err == nil {
+			job.Title = CleanText(title)
+		}
 	}
 
 	// Extract company name
-	company, err := ExtractText(page, "div._jd_company_, [class*='company'], meta[property='og:site_name']")
-	if err == nil {
-		job.Company = CleanText(company)
+	if job.Company == "" {
+		if company, err := extractTextGoquery(doc, ashbyCompanySelector); err == nil {
+			job.Company = CleanText(company)
+		}
 	}
 
 	// Extract location
-	location, err := ExtractText(page, "div._jd_location_, [class*='location'], span[class*='location']")
-	if err == nil {
-		job.Location = CleanText(location)
-
-		// Check if remote
-		locationLower := strings.ToLower(location)
-		if strings.Contains(locationLower, "remote") {
-			job.Remote = true
+	if job.Location == "" {
+		if location, err := extractTextGoquery(doc, ashbyLocationSelector); err == nil {
+			job.Location = CleanText(location)
 		}
 	}
+	if !job.Remote && strings.Contains(strings.ToLower(job.Location), "remote") {
+		job.Remote = true
+	}
 
 	// Extract job description
-	desc, err := ExtractText(page, "div._jd_description_, div[class*='description'], div[class*='content']")
-	if err == nil {
-		job.Description = CleanText(desc)
+	if job.Description == "" {
+		if desc, err := extractTextGoquery(doc, ashbyDescriptionSelector); err == nil {
+			job.Description = CleanText(desc)
+		}
 	}
 
 	// Extract salary range
-	salary, err := ExtractText(page, "div._jd_salary_, [class*='salary'], [class*='compensation']")
-	if err == nil && salary != "" {
-		job.SalaryRange = CleanText(salary)
+	if job.SalaryRange == "" {
+		if salary, err := extractTextGoquery(doc, ashbySalarySelector); err == nil && salary != "" {
+			job.SalaryRange = CleanText(salary)
+		}
 	}
 
 	// Extract job type
-	jobType, err := ExtractText(page, "div._jd_type_, [class*='employment-type'], [class*='job-type']")
-	if err == nil {
-		job.JobType = CleanText(jobType)
+	if job.JobType == "" {
+		if jobType, err := extractTextGoquery(doc, ashbyJobTypeSelector); err == nil {
+			job.JobType = CleanText(jobType)
+		}
 	}
 
 	// Extract posted date
-	posted, err := ExtractText(page, "time, [datetime], [class*='posted']")
-	if err == nil {
-		job.PostedDate = CleanText(posted)
+	if job.PostedDate == "" {
+		if posted, err := extractTextGoquery(doc, ashbyPostedSelector); err == nil {
+			job.PostedDate = CleanText(posted)
+		}
 	}
 
 	// Extract requirements
-	requirements, err := ExtractText(page, "div._jd_requirements_, [class*='requirements'], ul li")
-	if err == nil && requirements != "" {
-		job.Requirements = strings.Split(requirements, "\n")
+	if len(job.Requirements) == 0 {
+		if requirements, err := extractTextGoquery(doc, ashbyRequirementsSelector); err == nil && requirements != "" {
+			job.Requirements = strings.Split(requirements, "\n")
+		}
 	}
 
-	// Extract HTML content
-	html, err := page.Content()
-	if err == nil {
-		job.RawHTML = html
-	}
+	job.RawHTML = htmlContent
 
 	// Validate required fields
 	if job.Title == "" || job.Company == "" {
@@ -97,3 +136,9 @@ func (p *AshbyParser) GetSearchURLs(keywords, location string) []string {
 	// Return empty as we don't have a central search URL
 	return []string{}
 }
+
+func init() {
+	Register("ashby", 10, []string{"ashbyhq.com"}, func() Parser {
+		return NewAshbyParser()
+	})
+}
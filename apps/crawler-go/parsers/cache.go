@@ -0,0 +1,200 @@
+package parsers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultCacheTTL is how long a successful parse is cached for.
+const DefaultCacheTTL = 5 * time.Minute
+
+// DefaultNegativeCacheTTL is how long a failed parse is cached for, so a
+// broken page doesn't keep getting re-fetched on every discovery pass.
+const DefaultNegativeCacheTTL = 30 * time.Second
+
+// CacheEntry is what a Cache stores per URL: either a successfully parsed
+// JobListing or the error from a failed parse.
+type CacheEntry struct {
+	Job *JobListing
+	Err string
+}
+
+// Cache stores parse results keyed by canonical URL for a bounded TTL.
+type Cache interface {
+	Get(ctx context.Context, url string) (*CacheEntry, bool)
+	Set(ctx context.Context, url string, entry *CacheEntry, ttl time.Duration)
+	Delete(ctx context.Context, url string)
+}
+
+// CacheStats tracks cache hit/miss counts for observability.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Snapshot returns the current hit/miss counts.
+func (s *CacheStats) Snapshot() (hits, misses int64) {
+	return atomic.LoadInt64(&s.Hits), atomic.LoadInt64(&s.Misses)
+}
+
+type memoryCacheItem struct {
+	entry     *CacheEntry
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process TTL cache backed by sync.Map, suitable for
+// single-node runs of the crawler.
+type MemoryCache struct {
+	items sync.Map // url -> *memoryCacheItem
+}
+
+// NewMemoryCache creates an empty in-process cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{}
+}
+
+// Get returns the cached entry for url, or false if it's missing or expired.
+func (c *MemoryCache) Get(_ context.Context, url string) (*CacheEntry, bool) {
+	raw, ok := c.items.Load(url)
+	if !ok {
+		return nil, false
+	}
+
+	item := raw.(*memoryCacheItem)
+	if time.Now().After(item.expiresAt) {
+		c.items.Delete(url)
+		return nil, false
+	}
+
+	return item.entry, true
+}
+
+// Set stores entry for url with the given ttl.
+func (c *MemoryCache) Set(_ context.Context, url string, entry *CacheEntry, ttl time.Duration) {
+	c.items.Store(url, &memoryCacheItem{
+		entry:     entry,
+		expiresAt: time.Now().Add(ttl),
+	})
+}
+
+// Delete evicts url from the cache, used to bust stale results on demand.
+func (c *MemoryCache) Delete(_ context.Context, url string) {
+	c.items.Delete(url)
+}
+
+// RedisCache is a Redis-backed Cache for multi-worker crawler deployments
+// where results need to be shared across processes.
+type RedisCache struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisCache wraps an existing Redis client. keyPrefix namespaces cache
+// keys (e.g. "parsers:cache:") so they don't collide with other Redis users.
+func NewRedisCache(client *redis.Client, keyPrefix string) *RedisCache {
+	return &RedisCache{client: client, keyPrefix: keyPrefix}
+}
+
+func (c *RedisCache) key(url string) string {
+	return c.keyPrefix + url
+}
+
+// Get returns the cached entry for url, or false if it's missing, expired,
+// or the Redis call failed.
+func (c *RedisCache) Get(ctx context.Context, url string) (*CacheEntry, bool) {
+	data, err := c.client.Get(ctx, c.key(url)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Set stores entry for url with the given ttl.
+func (c *RedisCache) Set(ctx context.Context, url string, entry *CacheEntry, ttl time.Duration) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	_ = c.client.Set(ctx, c.key(url), data, ttl).Err()
+}
+
+// Delete evicts url from the cache.
+func (c *RedisCache) Delete(ctx context.Context, url string) {
+	_ = c.client.Del(ctx, c.key(url)).Err()
+}
+
+// CachingParser wraps a Parser with a Cache so repeat visits to the same URL
+// within the TTL skip re-running the parse (and, for a Playwright-backed
+// Parser, re-launching the browser) entirely. It implements Parser itself,
+// so it can be registered and resolved like any other parser.
+type CachingParser struct {
+	inner       Parser
+	cache       Cache
+	Stats       CacheStats
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+// NewCachingParser wraps inner with cache using the default TTLs.
+func NewCachingParser(inner Parser, cache Cache) *CachingParser {
+	return &CachingParser{
+		inner:       inner,
+		cache:       cache,
+		TTL:         DefaultCacheTTL,
+		NegativeTTL: DefaultNegativeCacheTTL,
+	}
+}
+
+// CanParse delegates to the wrapped parser.
+func (c *CachingParser) CanParse(url string) bool {
+	return c.inner.CanParse(url)
+}
+
+// GetSearchURLs delegates to the wrapped parser.
+func (c *CachingParser) GetSearchURLs(query, location string) []string {
+	return c.inner.GetSearchURLs(query, location)
+}
+
+// Parse returns the cached result for src.URL if present, otherwise
+// delegates to the wrapped parser and caches the outcome (including
+// failures).
+func (c *CachingParser) Parse(ctx context.Context, src Source) (*JobListing, error) {
+	return c.ParseWithOptions(ctx, src, false)
+}
+
+// ParseWithOptions behaves like Parse, but bust forces a fresh parse and
+// overwrites whatever is currently cached for src.URL.
+func (c *CachingParser) ParseWithOptions(ctx context.Context, src Source, bust bool) (*JobListing, error) {
+	if !bust {
+		if entry, ok := c.cache.Get(ctx, src.URL); ok {
+			atomic.AddInt64(&c.Stats.Hits, 1)
+			if entry.Err != "" {
+				return nil, fmt.Errorf("%s", entry.Err)
+			}
+			return entry.Job, nil
+		}
+	}
+	atomic.AddInt64(&c.Stats.Misses, 1)
+
+	job, err := c.inner.Parse(ctx, src)
+	if err != nil {
+		c.cache.Set(ctx, src.URL, &CacheEntry{Err: err.Error()}, c.NegativeTTL)
+		return nil, err
+	}
+
+	c.cache.Set(ctx, src.URL, &CacheEntry{Job: job}, c.TTL)
+	return job, nil
+}
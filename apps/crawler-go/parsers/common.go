@@ -1,13 +1,19 @@
 package parsers
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
+	"github.com/playwright-community/playwright-go"
 	"golang.org/x/net/html"
 )
 
-// JobListing represents a parsed job listing
+// JobListing is a parsed job posting. Static-HTML parsers (Indeed, LinkedIn,
+// Greenhouse) and ATS parsers that need JS rendering (Ashby, Notion,
+// Workday) both populate this same struct; the fields below Platform are
+// typically only filled in by the latter, via JSON-LD or ATS-specific
+// selectors.
 type JobListing struct {
 	Title       string
 	Company     string
@@ -15,15 +21,84 @@ type JobListing struct {
 	Description string
 	URL         string
 	Platform    string
+
+	ExternalID   string
+	SalaryRange  string
+	JobType      string
+	PostedDate   string
+	RawHTML      string
+	Requirements []string
+	Remote       bool
+
+	// Skills holds keyword tags found by a SkillsExtractor run over this
+	// listing. Empty unless a caller explicitly extracts skills.
+	Skills []string
+}
+
+// SourceKind distinguishes how a Source's content was obtained.
+type SourceKind int
+
+const (
+	// SourceHTML holds already-fetched, static HTML.
+	SourceHTML SourceKind = iota
+	// SourcePage holds a live, possibly JS-rendered Playwright page.
+	SourcePage
+)
+
+// Source is a job page to parse: either raw HTML fetched over plain HTTP,
+// or a live Playwright page for sites that render client-side. It lets a
+// single Parser implementation serve both static ATS pages (Indeed,
+// LinkedIn, Greenhouse) and client-rendered ones (Ashby, Notion, Workday)
+// without duplicating extraction logic per fetch method.
+type Source struct {
+	Kind SourceKind
+	URL  string
+	HTML string
+	Page playwright.Page
+}
+
+// HTMLSource builds a Source from already-fetched HTML.
+func HTMLSource(url, html string) Source {
+	return Source{Kind: SourceHTML, URL: url, HTML: html}
 }
 
-// Parser interface for all job board parsers
+// PageSource builds a Source from a live Playwright page.
+func PageSource(url string, page playwright.Page) Source {
+	return Source{Kind: SourcePage, URL: url, Page: page}
+}
+
+// IsLive reports whether src wraps a live Playwright page rather than
+// already-fetched HTML.
+func (s Source) IsLive() bool {
+	return s.Kind == SourcePage
+}
+
+// Content returns src's HTML: page.Content() for a live page, or the
+// already-fetched HTML string otherwise.
+func (s Source) Content() (string, error) {
+	if s.Kind == SourcePage {
+		return s.Page.Content()
+	}
+	return s.HTML, nil
+}
+
+// Parser is the interface every ATS/job-board parser implements, whether it
+// works from already-fetched HTML or a live, JS-rendered page.
 type Parser interface {
 	CanParse(url string) bool
-	Parse(htmlContent string, url string) (*JobListing, error)
+	Parse(ctx context.Context, src Source) (*JobListing, error)
 	GetSearchURLs(query string, location string) []string
 }
 
+// SelectorHints is implemented by a Parser that knows which CSS selector
+// reliably appears once its ATS has finished rendering job content, so a
+// JS-rendering crawler can wait for that selector specifically instead of
+// falling back to a generic network-idle heuristic. Optional: a Parser that
+// doesn't implement it just gets the generic heuristic.
+type SelectorHints interface {
+	WaitSelectors() []string
+}
+
 // ExtractText extracts all text from an HTML node
 func ExtractText(n *html.Node) string {
 	if n.Type == html.TextNode {
@@ -1,6 +1,7 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -24,14 +25,19 @@ func (p *GreenhouseParser) CanParse(urlStr string) bool {
 		strings.Contains(parsedURL.Host, "boards.greenhouse.io")
 }
 
-func (p *GreenhouseParser) Parse(htmlContent string, urlStr string) (*JobListing, error) {
+func (p *GreenhouseParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	listing := &JobListing{
-		URL:      urlStr,
+		URL:      src.URL,
 		Platform: "greenhouse",
 	}
 
@@ -58,9 +64,21 @@ func (p *GreenhouseParser) Parse(htmlContent string, urlStr string) (*JobListing
 	return listing, nil
 }
 
+// WaitSelectors identifies ".app-title" as the element that appears once a
+// Greenhouse job page has finished rendering (see parsers.SelectorHints).
+func (p *GreenhouseParser) WaitSelectors() []string {
+	return []string{".app-title"}
+}
+
 func (p *GreenhouseParser) GetSearchURLs(query, location string) []string {
 	// Greenhouse doesn't have a universal search URL
 	// Jobs are typically on company-specific boards
 	// This would need to be customized per company
 	return []string{}
 }
+
+func init() {
+	Register("greenhouse", 10, []string{"greenhouse.io", "boards.greenhouse.io"}, func() Parser {
+		return NewGreenhouseParser()
+	})
+}
@@ -0,0 +1,48 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultFetchTimeout bounds a headless (non-Playwright) HTML fetch.
+const DefaultFetchTimeout = 15 * time.Second
+
+// FetchHTML retrieves url over plain net/http, for pages that render
+// server-side and don't need a browser to produce their final HTML. The
+// result can be wrapped in an HTMLSource and handed to any Parser, live
+// Playwright page or not.
+func FetchHTML(url string) (string, error) {
+	client := &http.Client{Timeout: DefaultFetchTimeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response from %s: %w", url, err)
+	}
+
+	return string(body), nil
+}
+
+// extractTextGoquery returns the cleaned text of the first element in doc
+// matching selector.
+func extractTextGoquery(doc *goquery.Document, selector string) (string, error) {
+	sel := doc.Find(selector).First()
+	if sel.Length() == 0 {
+		return "", fmt.Errorf("no element matched selector: %s", selector)
+	}
+	return sel.Text(), nil
+}
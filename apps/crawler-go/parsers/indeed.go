@@ -1,6 +1,7 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -23,14 +24,19 @@ func (p *IndeedParser) CanParse(urlStr string) bool {
 	return strings.Contains(parsedURL.Host, "indeed.com")
 }
 
-func (p *IndeedParser) Parse(htmlContent string, urlStr string) (*JobListing, error) {
+func (p *IndeedParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	listing := &JobListing{
-		URL:      urlStr,
+		URL:      src.URL,
 		Platform: "indeed",
 	}
 
@@ -74,3 +80,9 @@ func (p *IndeedParser) GetSearchURLs(query, location string) []string {
 
 	return urls
 }
+
+func init() {
+	Register("indeed", 10, []string{"indeed.com"}, func() Parser {
+		return NewIndeedParser()
+	})
+}
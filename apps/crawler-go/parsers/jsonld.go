@@ -0,0 +1,253 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// extractJSONLDFromHTML finds every <script type="application/ld+json">
+// block in content, decodes the first one whose "@type" is "JobPosting",
+// and maps its schema.org fields into a JobListing. ATS parsers should call
+// this first and fall back to selector scraping only for whatever comes
+// back empty, since most modern ATS pages embed this structured data and
+// it's far more resilient to markup changes than class names.
+func extractJSONLDFromHTML(content string) (*JobListing, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing page HTML: %w", err)
+	}
+
+	for _, block := range findJSONLDScripts(doc) {
+		if posting, ok := decodeJobPosting(block); ok {
+			return jobPostingToJobListing(posting), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no schema.org JobPosting JSON-LD found")
+}
+
+// findJSONLDScripts returns the text content of every
+// <script type="application/ld+json"> element in the document.
+func findJSONLDScripts(n *html.Node) []string {
+	var scripts []string
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "script" {
+			for _, attr := range n.Attr {
+				if attr.Key == "type" && attr.Val == "application/ld+json" {
+					scripts = append(scripts, ExtractText(n))
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return scripts
+}
+
+// decodeJobPosting parses block, which may be a single object, an array of
+// objects, or an "@graph"-wrapped list, and returns the first entry whose
+// "@type" is "JobPosting".
+func decodeJobPosting(block string) (map[string]interface{}, bool) {
+	var single map[string]interface{}
+	if err := json.Unmarshal([]byte(block), &single); err == nil {
+		if ldType(single) == "JobPosting" {
+			return single, true
+		}
+		if graph, ok := single["@graph"].([]interface{}); ok {
+			return firstJobPosting(graph)
+		}
+		return nil, false
+	}
+
+	var list []interface{}
+	if err := json.Unmarshal([]byte(block), &list); err == nil {
+		return firstJobPosting(list)
+	}
+
+	return nil, false
+}
+
+func firstJobPosting(items []interface{}) (map[string]interface{}, bool) {
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok && ldType(obj) == "JobPosting" {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+func ldType(obj map[string]interface{}) string {
+	t, _ := obj["@type"].(string)
+	return t
+}
+
+// jobPostingToJobListing maps a decoded schema.org JobPosting object into a
+// JobListing, leaving fields it can't find blank for the caller's
+// selector-based fallback to fill in.
+func jobPostingToJobListing(posting map[string]interface{}) *JobListing {
+	job := &JobListing{
+		Title:       ldString(posting, "title"),
+		Description: ldString(posting, "description"),
+		PostedDate:  ldString(posting, "datePosted"),
+		JobType:     ldString(posting, "employmentType"),
+	}
+
+	if org, ok := posting["hiringOrganization"].(map[string]interface{}); ok {
+		job.Company = ldString(org, "name")
+	}
+
+	job.Location = ldJobLocation(posting["jobLocation"])
+	job.SalaryRange = ldBaseSalary(posting["baseSalary"])
+
+	locationType, _ := posting["jobLocationType"].(string)
+	if strings.EqualFold(locationType, "TELECOMMUTE") {
+		job.Remote = true
+	} else if _, ok := posting["applicantLocationRequirements"]; ok && job.Location == "" {
+		// A posting with location requirements but no jobLocation is
+		// usually fully remote within those regions.
+		job.Remote = true
+	}
+
+	return job
+}
+
+// mergeJobListing copies every non-empty field from src into dst without
+// overwriting a field dst already has. Parsers run this after the JSON-LD
+// pass so selector-based scraping only needs to fill in what's still
+// missing.
+func mergeJobListing(dst, src *JobListing) {
+	if dst.Title == "" {
+		dst.Title = src.Title
+	}
+	if dst.Company == "" {
+		dst.Company = src.Company
+	}
+	if dst.Location == "" {
+		dst.Location = src.Location
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if dst.SalaryRange == "" {
+		dst.SalaryRange = src.SalaryRange
+	}
+	if dst.JobType == "" {
+		dst.JobType = src.JobType
+	}
+	if dst.PostedDate == "" {
+		dst.PostedDate = src.PostedDate
+	}
+	if len(dst.Requirements) == 0 {
+		dst.Requirements = src.Requirements
+	}
+	if src.Remote {
+		dst.Remote = true
+	}
+}
+
+func ldString(obj map[string]interface{}, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return ""
+	}
+}
+
+// ldJobLocation formats a schema.org jobLocation (a single Place, or an
+// array of Places) into a human-readable address string.
+func ldJobLocation(v interface{}) string {
+	switch loc := v.(type) {
+	case map[string]interface{}:
+		return ldPlaceAddress(loc)
+	case []interface{}:
+		parts := make([]string, 0, len(loc))
+		for _, item := range loc {
+			if place, ok := item.(map[string]interface{}); ok {
+				if addr := ldPlaceAddress(place); addr != "" {
+					parts = append(parts, addr)
+				}
+			}
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return ""
+	}
+}
+
+func ldPlaceAddress(place map[string]interface{}) string {
+	addr, ok := place["address"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	var parts []string
+	for _, field := range []string{"addressLocality", "addressRegion", "addressCountry"} {
+		if s := ldString(addr, field); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// ldBaseSalary formats a schema.org MonetaryAmount's nested value into a
+// "min-max unit currency" string, e.g. "120000-150000 YEAR USD".
+func ldBaseSalary(v interface{}) string {
+	salary, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	value, ok := salary["value"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	amount := ""
+	if min, max := ldNumber(value["minValue"]), ldNumber(value["maxValue"]); min != "" && max != "" {
+		amount = min + "-" + max
+	} else if single := ldNumber(value["value"]); single != "" {
+		amount = single
+	} else {
+		return ""
+	}
+
+	if unit := ldString(value, "unitText"); unit != "" {
+		amount += " " + unit
+	}
+	if currency := ldString(salary, "currency"); currency != "" {
+		amount += " " + currency
+	}
+
+	return amount
+}
+
+func ldNumber(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		return n
+	default:
+		return ""
+	}
+}
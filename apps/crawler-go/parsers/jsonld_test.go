@@ -0,0 +1,89 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func TestExtractJSONLDFromHTMLGreenhouse(t *testing.T) {
+	listing, err := extractJSONLDFromHTML(readFixture(t, "greenhouse_jobposting.html"))
+	if err != nil {
+		t.Fatalf("extractJSONLDFromHTML: %v", err)
+	}
+
+	if listing.Title != "Senior Backend Engineer" {
+		t.Errorf("Title = %q, want %q", listing.Title, "Senior Backend Engineer")
+	}
+	if listing.Company != "Acme Corp" {
+		t.Errorf("Company = %q, want %q", listing.Company, "Acme Corp")
+	}
+	if listing.Location != "Austin, TX, US" {
+		t.Errorf("Location = %q, want %q", listing.Location, "Austin, TX, US")
+	}
+	if listing.SalaryRange != "140000-180000 YEAR USD" {
+		t.Errorf("SalaryRange = %q, want %q", listing.SalaryRange, "140000-180000 YEAR USD")
+	}
+	if listing.Remote {
+		t.Error("Remote = true, want false")
+	}
+}
+
+func TestExtractJSONLDFromHTMLLeverArrayAndRemote(t *testing.T) {
+	listing, err := extractJSONLDFromHTML(readFixture(t, "lever_jobposting.html"))
+	if err != nil {
+		t.Fatalf("extractJSONLDFromHTML: %v", err)
+	}
+
+	if listing.Title != "Product Designer" {
+		t.Errorf("Title = %q, want %q", listing.Title, "Product Designer")
+	}
+	if listing.Company != "Lever Example" {
+		t.Errorf("Company = %q, want %q", listing.Company, "Lever Example")
+	}
+	if !listing.Remote {
+		t.Error("Remote = false, want true (TELECOMMUTE jobLocationType)")
+	}
+}
+
+func TestExtractJSONLDFromHTMLAshbyGraph(t *testing.T) {
+	listing, err := extractJSONLDFromHTML(readFixture(t, "ashby_jobposting.html"))
+	if err != nil {
+		t.Fatalf("extractJSONLDFromHTML: %v", err)
+	}
+
+	if listing.Title != "Staff Site Reliability Engineer" {
+		t.Errorf("Title = %q, want %q", listing.Title, "Staff Site Reliability Engineer")
+	}
+	if listing.Company != "Ashby Example" {
+		t.Errorf("Company = %q, want %q", listing.Company, "Ashby Example")
+	}
+	if listing.Location != "Remote, CA" {
+		t.Errorf("Location = %q, want %q", listing.Location, "Remote, CA")
+	}
+	if listing.SalaryRange != "165000 YEAR CAD" {
+		t.Errorf("SalaryRange = %q, want %q", listing.SalaryRange, "165000 YEAR CAD")
+	}
+}
+
+func TestExtractJSONLDFromHTMLMalformed(t *testing.T) {
+	if _, err := extractJSONLDFromHTML(readFixture(t, "malformed_jsonld.html")); err == nil {
+		t.Error("extractJSONLDFromHTML: expected an error for malformed JSON-LD, got nil")
+	}
+}
+
+func TestExtractJSONLDFromHTMLMissing(t *testing.T) {
+	if _, err := extractJSONLDFromHTML(readFixture(t, "missing_jsonld.html")); err == nil {
+		t.Error("extractJSONLDFromHTML: expected an error when no JSON-LD block is present, got nil")
+	}
+}
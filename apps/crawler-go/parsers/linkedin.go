@@ -1,6 +1,7 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -23,14 +24,19 @@ func (p *LinkedInParser) CanParse(urlStr string) bool {
 	return strings.Contains(parsedURL.Host, "linkedin.com")
 }
 
-func (p *LinkedInParser) Parse(htmlContent string, urlStr string) (*JobListing, error) {
+func (p *LinkedInParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	listing := &JobListing{
-		URL:      urlStr,
+		URL:      src.URL,
 		Platform: "linkedin",
 	}
 
@@ -69,3 +75,9 @@ func (p *LinkedInParser) GetSearchURLs(query, location string) []string {
 
 	return urls
 }
+
+func init() {
+	Register("linkedin", 10, []string{"linkedin.com"}, func() Parser {
+		return NewLinkedInParser()
+	})
+}
@@ -1,94 +1,124 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
-	"github.com/playwright-community/playwright-go"
+	"github.com/PuerkitoBio/goquery"
 )
 
 // NotionParser handles job parsing from Notion pages
 type NotionParser struct{}
 
+// NewNotionParser builds a NotionParser. Wrap it with NewCachingParser to add
+// a short-TTL result cache in front of the parse.
+func NewNotionParser() *NotionParser {
+	return &NotionParser{}
+}
+
 // CanParse checks if URL is a Notion job page
 func (p *NotionParser) CanParse(url string) bool {
 	return strings.Contains(url, "notion.site") ||
 		strings.Contains(url, "notion.so")
 }
 
-// Parse extracts job data from Notion page
-func (p *NotionParser) Parse(page playwright.Page, url string) (*JobData, error) {
-	job := &JobData{
-		URL:    url,
-		Source: "notion",
+// Parse extracts job data from a Notion page. src may wrap a live
+// Playwright page or already-fetched HTML; either way it reduces to an
+// HTML string walked with goquery.
+func (p *NotionParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	job := &JobListing{
+		URL:      src.URL,
+		Platform: "notion",
+	}
+
+	// JSON-LD first: falls back to selector scraping for whatever a page's
+	// schema.org JobPosting block doesn't cover.
+	if ld, err := extractJSONLDFromHTML(htmlContent); err == nil {
+		mergeJobListing(job, ld)
 	}
 
 	// Extract job title (Notion uses h1 for page titles)
-	title, err := ExtractText(page, "h1.notranslate, h1, [data-content-editable-leaf='true']")
-	if err == nil {
-		job.Title = CleanText(title)
+	if job.Title == "" {
+		if title, err := extractTextGoquery(doc, "h1.notranslate, h1, [data-content-editable-leaf='true']"); err == nil {
+			job.Title = CleanText(title)
+		}
 	}
 
 	// Extract company name from metadata or page content
-	company, err := ExtractText(page, "meta[property='og:site_name'], [class*='company'], strong:contains('Company')")
-	if err == nil {
-		job.Company = CleanText(company)
+	if job.Company == "" {
+		if company, err := extractTextGoquery(doc, "meta[property='og:site_name'], [class*='company']"); err == nil {
+			job.Company = CleanText(company)
+		}
 	}
 
 	// Extract location
-	location, err := ExtractText(page, "[class*='location'], strong:contains('Location')")
-	if err == nil {
-		job.Location = CleanText(location)
-
-		// Check if remote
-		locationLower := strings.ToLower(location)
-		if strings.Contains(locationLower, "remote") {
-			job.Remote = true
+	if job.Location == "" {
+		if location, err := extractTextGoquery(doc, "[class*='location']"); err == nil {
+			job.Location = CleanText(location)
 		}
 	}
+	if !job.Remote && strings.Contains(strings.ToLower(job.Location), "remote") {
+		job.Remote = true
+	}
 
 	// Extract job description (Notion pages have content in various blocks)
-	desc, err := ExtractText(page, "article, [class*='notion-page-content'], div[data-block-id]")
-	if err == nil {
-		job.Description = CleanText(desc)
+	if job.Description == "" {
+		if desc, err := extractTextGoquery(doc, "article, [class*='notion-page-content'], div[data-block-id]"); err == nil {
+			job.Description = CleanText(desc)
+		}
 	}
 
 	// Extract salary range
-	salary, err := ExtractText(page, "strong:contains('Salary'), strong:contains('Compensation')")
-	if err == nil && salary != "" {
-		job.SalaryRange = CleanText(salary)
+	if job.SalaryRange == "" {
+		if salary, err := extractTextGoquery(doc, "[class*='salary'], [class*='compensation']"); err == nil && salary != "" {
+			job.SalaryRange = CleanText(salary)
+		}
 	}
 
 	// Extract job type
-	jobType, err := ExtractText(page, "strong:contains('Type'), strong:contains('Employment')")
-	if err == nil {
-		job.JobType = CleanText(jobType)
+	if job.JobType == "" {
+		if jobType, err := extractTextGoquery(doc, "[class*='employment-type'], [class*='job-type']"); err == nil {
+			job.JobType = CleanText(jobType)
+		}
 	}
 
 	// Extract posted date from page metadata
-	posted, err := ExtractText(page, "time, [datetime], meta[property='article:published_time']")
-	if err == nil {
-		job.PostedDate = CleanText(posted)
+	if job.PostedDate == "" {
+		if posted, err := extractTextGoquery(doc, "time, [datetime], meta[property='article:published_time']"); err == nil {
+			job.PostedDate = CleanText(posted)
+		}
 	}
 
 	// Extract requirements (look for bullet points or lists)
-	requirements, err := ExtractText(page, "ul li, ol li")
-	if err == nil && requirements != "" {
-		reqList := strings.Split(requirements, "\n")
-		// Filter out empty requirements
-		filtered := make([]string, 0)
-		for _, req := range reqList {
-			cleaned := CleanText(req)
-			if cleaned != "" {
-				filtered = append(filtered, cleaned)
+	if len(job.Requirements) == 0 {
+		if requirements, err := extractTextGoquery(doc, "ul li, ol li"); err == nil && requirements != "" {
+			reqList := strings.Split(requirements, "\n")
+			// Filter out empty requirements
+			filtered := make([]string, 0)
+			for _, req := range reqList {
+				cleaned := CleanText(req)
+				if cleaned != "" {
+					filtered = append(filtered, cleaned)
+				}
 			}
+			job.Requirements = filtered
 		}
-		job.Requirements = filtered
 	}
 
 	// Extract page ID from URL
-	if strings.Contains(url, "-") {
-		parts := strings.Split(url, "-")
+	if strings.Contains(src.URL, "-") {
+		parts := strings.Split(src.URL, "-")
 		if len(parts) > 0 {
 			jobID := parts[len(parts)-1]
 			// Remove query params if any
@@ -97,11 +127,7 @@ func (p *NotionParser) Parse(page playwright.Page, url string) (*JobData, error)
 		}
 	}
 
-	// Extract HTML content
-	html, err := page.Content()
-	if err == nil {
-		job.RawHTML = html
-	}
+	job.RawHTML = htmlContent
 
 	// Validate required fields
 	if job.Title == "" {
@@ -111,8 +137,8 @@ func (p *NotionParser) Parse(page playwright.Page, url string) (*JobData, error)
 	// Notion pages may not have explicit company field, try to extract from domain
 	if job.Company == "" {
 		// Try to extract from subdomain (e.g., companyname.notion.site)
-		if strings.Contains(url, ".notion.site") {
-			parts := strings.Split(url, ".")
+		if strings.Contains(src.URL, ".notion.site") {
+			parts := strings.Split(src.URL, ".")
 			if len(parts) > 0 {
 				subdomain := strings.Replace(parts[0], "https://", "", 1)
 				subdomain = strings.Replace(subdomain, "http://", "", 1)
@@ -133,3 +159,9 @@ func (p *NotionParser) GetSearchURLs(keywords, location string) []string {
 	// Notion doesn't have a central job search
 	return []string{}
 }
+
+func init() {
+	Register("notion", 10, []string{"notion.site", "notion.so"}, func() Parser {
+		return NewNotionParser()
+	})
+}
@@ -0,0 +1,93 @@
+package parsers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory constructs a new Parser instance. Factories are invoked fresh for
+// each Resolve call so parsers may hold per-parse state without leaking
+// between callers.
+type Factory func() Parser
+
+// Registration describes a registered parser's metadata for ops tooling and
+// precedence resolution.
+type Registration struct {
+	Factory  Factory
+	Name     string
+	Domains  []string
+	Priority int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*Registration{}
+)
+
+// Register adds a parser factory to the registry under name. When multiple
+// registered parsers' CanParse matches the same URL, Resolve prefers the one
+// with the higher Priority. Intended to be called from a parser package's
+// init() so third parties can add a new ATS parser with a blank import and
+// no edits to a dispatch switch.
+func Register(name string, priority int, domains []string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = &Registration{
+		Name:     name,
+		Priority: priority,
+		Domains:  domains,
+		Factory:  factory,
+	}
+}
+
+// Resolve returns the highest-priority registered parser whose CanParse
+// matches url. If several parsers match, precedence is decided by Priority,
+// then by name for determinism among equal priorities.
+func Resolve(url string) (Parser, error) {
+	for _, reg := range sortedRegistrations() {
+		p := reg.Factory()
+		if p.CanParse(url) {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no parser registered for url: %s", url)
+}
+
+// List returns metadata for every registered parser, ordered by precedence
+// (highest priority first). Intended for ops tooling that needs to inspect
+// what parsers are installed and in what order they'll be tried.
+func List() []Registration {
+	return sortedRegistrations()
+}
+
+// Describe returns the metadata for a single registered parser by name.
+func Describe(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[name]
+	if !ok {
+		return Registration{}, false
+	}
+	return *r, true
+}
+
+func sortedRegistrations() []Registration {
+	registryMu.RLock()
+	out := make([]Registration, 0, len(registry))
+	for _, r := range registry {
+		out = append(out, *r)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority > out[j].Priority
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
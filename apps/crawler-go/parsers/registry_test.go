@@ -0,0 +1,87 @@
+package parsers
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeParser is a minimal Parser used to exercise Resolve's precedence
+// rules without depending on any real ATS parser's CanParse logic.
+type fakeParser struct {
+	name string
+}
+
+func (p *fakeParser) CanParse(urlStr string) bool {
+	return urlStr == "https://example.com/jobs/123"
+}
+
+func (p *fakeParser) Parse(_ context.Context, _ Source) (*JobListing, error) {
+	return &JobListing{Platform: p.name}, nil
+}
+
+func (p *fakeParser) GetSearchURLs(_ string, _ string) []string {
+	return nil
+}
+
+// withCleanRegistry registers regs against the global registry for the
+// duration of a test and restores the prior registry afterward, so tests
+// don't leak fake parsers into each other or into the real ATS parsers
+// registered via init().
+func withCleanRegistry(t *testing.T, regs []Registration) {
+	t.Helper()
+
+	registryMu.Lock()
+	prev := registry
+	registry = map[string]*Registration{}
+	registryMu.Unlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = prev
+		registryMu.Unlock()
+	})
+
+	for _, r := range regs {
+		Register(r.Name, r.Priority, r.Domains, r.Factory)
+	}
+}
+
+func TestResolvePrefersHigherPriority(t *testing.T) {
+	withCleanRegistry(t, []Registration{
+		{Name: "low", Priority: 1, Factory: func() Parser { return &fakeParser{name: "low"} }},
+		{Name: "high", Priority: 10, Factory: func() Parser { return &fakeParser{name: "high"} }},
+	})
+
+	got, err := Resolve("https://example.com/jobs/123")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.(*fakeParser).name != "high" {
+		t.Errorf("Resolve picked %q, want %q", got.(*fakeParser).name, "high")
+	}
+}
+
+func TestResolveBreaksTiesByName(t *testing.T) {
+	withCleanRegistry(t, []Registration{
+		{Name: "zebra", Priority: 5, Factory: func() Parser { return &fakeParser{name: "zebra"} }},
+		{Name: "alpha", Priority: 5, Factory: func() Parser { return &fakeParser{name: "alpha"} }},
+	})
+
+	got, err := Resolve("https://example.com/jobs/123")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.(*fakeParser).name != "alpha" {
+		t.Errorf("Resolve picked %q, want %q", got.(*fakeParser).name, "alpha")
+	}
+}
+
+func TestResolveNoMatch(t *testing.T) {
+	withCleanRegistry(t, []Registration{
+		{Name: "only", Priority: 1, Factory: func() Parser { return &fakeParser{name: "only"} }},
+	})
+
+	if _, err := Resolve("https://unmatched.example.com"); err == nil {
+		t.Error("Resolve: expected error for a URL no registered parser can handle")
+	}
+}
@@ -0,0 +1,60 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+)
+
+// schemaOrgPriority is lower than every site-specific parser's Priority, so
+// Resolve only falls back to SchemaOrgParser once none of the ATS-specific
+// parsers' CanParse matches the URL.
+const schemaOrgPriority = -1
+
+// SchemaOrgParser extracts a JobListing from a page's schema.org JobPosting
+// JSON-LD (see extractJSONLDFromHTML), with no host-specific selectors.
+// It's the catch-all Resolve reaches for once every site-specific parser's
+// CanParse has rejected the URL — most modern career sites embed this
+// structured data regardless of which ATS serves the page, so it covers
+// platforms without a dedicated parser (Lever, Ashby variants, self-hosted
+// boards, ...) instead of failing outright.
+type SchemaOrgParser struct{}
+
+// NewSchemaOrgParser returns a ready-to-use SchemaOrgParser.
+func NewSchemaOrgParser() *SchemaOrgParser {
+	return &SchemaOrgParser{}
+}
+
+// CanParse always returns true: SchemaOrgParser is the registry's lowest
+// priority entry, so it's only ever tried once nothing more specific
+// matched.
+func (p *SchemaOrgParser) CanParse(_ string) bool {
+	return true
+}
+
+func (p *SchemaOrgParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
+	listing, err := extractJSONLDFromHTML(htmlContent)
+	if err != nil {
+		return nil, fmt.Errorf("extracting schema.org JobPosting: %w", err)
+	}
+
+	listing.URL = src.URL
+	listing.Platform = "schema.org"
+	return listing, nil
+}
+
+// GetSearchURLs returns no search URLs: SchemaOrgParser only parses a job
+// page it's handed, it has no platform-specific search endpoint to query.
+func (p *SchemaOrgParser) GetSearchURLs(_, _ string) []string {
+	return []string{}
+}
+
+func init() {
+	Register("schema.org", schemaOrgPriority, nil, func() Parser {
+		return NewSchemaOrgParser()
+	})
+}
@@ -0,0 +1,89 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SkillSpec describes one skill a SkillsExtractor looks for: Name is the tag
+// recorded in JobListing.Skills, and Aliases are the words/phrases that
+// count as a match (e.g. "golang", "go lang" both tag "Go").
+type SkillSpec struct {
+	Name    string
+	Aliases []string
+}
+
+// DefaultSkills is the built-in keyword table a zero-value SkillsExtractor
+// falls back to. It's intentionally small; callers with a larger or
+// domain-specific vocabulary should build their own NewSkillsExtractor
+// table instead of editing this one.
+var DefaultSkills = []SkillSpec{
+	{Name: "Go", Aliases: []string{"golang"}},
+	{Name: "Python", Aliases: []string{"python"}},
+	{Name: "JavaScript", Aliases: []string{"javascript", "js"}},
+	{Name: "TypeScript", Aliases: []string{"typescript", "ts"}},
+	{Name: "Java", Aliases: []string{"java"}},
+	{Name: "React", Aliases: []string{"react", "react.js", "reactjs"}},
+	{Name: "Kubernetes", Aliases: []string{"kubernetes", "k8s"}},
+	{Name: "Docker", Aliases: []string{"docker"}},
+	{Name: "AWS", Aliases: []string{"aws", "amazon web services"}},
+	{Name: "SQL", Aliases: []string{"sql", "postgresql", "postgres", "mysql"}},
+	{Name: "GraphQL", Aliases: []string{"graphql"}},
+	{Name: "Terraform", Aliases: []string{"terraform"}},
+}
+
+// SkillsExtractor tags a JobListing's Description/Title with every SkillSpec
+// whose alias appears as a whole word, matched case-insensitively. Matching
+// is table-driven so adding a new skill never requires a code change.
+type SkillsExtractor struct {
+	skills   []SkillSpec
+	patterns []*regexp.Regexp
+	names    []string
+}
+
+// NewSkillsExtractor compiles skills into a ready-to-use SkillsExtractor. An
+// empty or nil skills uses DefaultSkills.
+func NewSkillsExtractor(skills []SkillSpec) *SkillsExtractor {
+	if len(skills) == 0 {
+		skills = DefaultSkills
+	}
+
+	e := &SkillsExtractor{skills: skills}
+	for _, spec := range skills {
+		e.names = append(e.names, spec.Name)
+		e.patterns = append(e.patterns, compileAliasPattern(spec.Aliases))
+	}
+	return e
+}
+
+// compileAliasPattern builds a case-insensitive, word-boundary regexp that
+// matches any of aliases.
+func compileAliasPattern(aliases []string) *regexp.Regexp {
+	escaped := make([]string, len(aliases))
+	for i, alias := range aliases {
+		escaped[i] = regexp.QuoteMeta(alias)
+	}
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(escaped, "|") + `)\b`)
+}
+
+// Extract returns the names of every skill found in listing's Title and
+// Description, in table order.
+func (e *SkillsExtractor) Extract(listing *JobListing) []string {
+	haystack := listing.Title + "\n" + listing.Description
+	if listing.RawHTML != "" {
+		haystack += "\n" + listing.RawHTML
+	}
+
+	var found []string
+	for i, pattern := range e.patterns {
+		if pattern.MatchString(haystack) {
+			found = append(found, e.names[i])
+		}
+	}
+	return found
+}
+
+// Populate sets listing.Skills to the result of Extract.
+func (e *SkillsExtractor) Populate(listing *JobListing) {
+	listing.Skills = e.Extract(listing)
+}
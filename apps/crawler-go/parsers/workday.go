@@ -1,43 +1,70 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
-	"github.com/playwright-community/playwright-go"
+	"github.com/PuerkitoBio/goquery"
 )
 
 // WorkdayParser handles job parsing from Workday
 type WorkdayParser struct{}
 
+// NewWorkdayParser builds a WorkdayParser. Wrap it with NewCachingParser to
+// add a short-TTL result cache in front of the parse.
+func NewWorkdayParser() *WorkdayParser {
+	return &WorkdayParser{}
+}
+
 // CanParse checks if URL is a Workday job page
 func (p *WorkdayParser) CanParse(url string) bool {
 	return strings.Contains(url, ".myworkdayjobs.com") ||
 		strings.Contains(url, "workday.com")
 }
 
-// Parse extracts job data from Workday page
-func (p *WorkdayParser) Parse(page playwright.Page, url string) (*JobData, error) {
-	job := &JobData{
-		URL:    url,
-		Source: "workday",
+// Parse extracts job data from a Workday page. src may wrap a live
+// Playwright page or already-fetched HTML; either way it reduces to an
+// HTML string walked with goquery.
+func (p *WorkdayParser) Parse(_ context.Context, src Source) (*JobListing, error) {
+	htmlContent, err := src.Content()
+	if err != nil {
+		return nil, fmt.Errorf("reading source content: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	job := &JobListing{
+		URL:      src.URL,
+		Platform: "workday",
+	}
+
+	// JSON-LD first: falls back to selector scraping for whatever a page's
+	// schema.org JobPosting block doesn't cover.
+	if ld, err := extractJSONLDFromHTML(htmlContent); err == nil {
+		mergeJobListing(job, ld)
 	}
 
 	// Extract job title
-	title, err := ExtractText(page, "h2[data-automation-id='jobPostingHeader'], h1[class*='title'], h2")
-	if err == nil {
-		job.Title = CleanText(title)
+	if job.Title == "" {
+		if title, err := extractTextGoquery(doc, "h2[data-automation-id='jobPostingHeader'], h1[class*='title'], h2"); err == nil {
+			job.Title = CleanText(title)
+		}
 	}
 
 	// Extract company name (often in URL or site metadata)
-	company, err := ExtractText(page, "[data-automation-id='company'], meta[property='og:site_name']")
-	if err == nil {
-		job.Company = CleanText(company)
+	if job.Company == "" {
+		if company, err := extractTextGoquery(doc, "[data-automation-id='company'], meta[property='og:site_name']"); err == nil {
+			job.Company = CleanText(company)
+		}
 	}
 
 	// Fallback: extract company from URL (e.g., companyname.myworkdayjobs.com)
 	if job.Company == "" {
-		parts := strings.Split(url, ".")
+		parts := strings.Split(src.URL, ".")
 		if len(parts) > 0 {
 			company := strings.Split(parts[0], "//")
 			if len(company) > 1 {
@@ -47,55 +74,53 @@ func (p *WorkdayParser) Parse(page playwright.Page, url string) (*JobData, error
 	}
 
 	// Extract location
-	location, err := ExtractText(page, "[data-automation-id='locations'], [class*='location']")
-	if err == nil {
-		job.Location = CleanText(location)
-
-		// Check if remote
-		locationLower := strings.ToLower(location)
-		if strings.Contains(locationLower, "remote") {
-			job.Remote = true
+	if job.Location == "" {
+		if location, err := extractTextGoquery(doc, "[data-automation-id='locations'], [class*='location']"); err == nil {
+			job.Location = CleanText(location)
 		}
 	}
+	if !job.Remote && strings.Contains(strings.ToLower(job.Location), "remote") {
+		job.Remote = true
+	}
 
 	// Extract job description
-	desc, err := ExtractText(page, "[data-automation-id='jobPostingDescription'], div[class*='description']")
-	if err == nil {
-		job.Description = CleanText(desc)
+	if job.Description == "" {
+		if desc, err := extractTextGoquery(doc, "[data-automation-id='jobPostingDescription'], div[class*='description']"); err == nil {
+			job.Description = CleanText(desc)
+		}
 	}
 
 	// Extract job type
-	jobType, err := ExtractText(page, "[data-automation-id='jobType'], [data-automation-id='time-type']")
-	if err == nil {
-		job.JobType = CleanText(jobType)
+	if job.JobType == "" {
+		if jobType, err := extractTextGoquery(doc, "[data-automation-id='jobType'], [data-automation-id='time-type']"); err == nil {
+			job.JobType = CleanText(jobType)
+		}
 	}
 
 	// Extract posted date
-	posted, err := ExtractText(page, "[data-automation-id='postedOn'], time")
-	if err == nil {
-		job.PostedDate = CleanText(posted)
+	if job.PostedDate == "" {
+		if posted, err := extractTextGoquery(doc, "[data-automation-id='postedOn'], time"); err == nil {
+			job.PostedDate = CleanText(posted)
+		}
 	}
 
 	// Extract requirements
-	requirements, err := ExtractText(page, "[data-automation-id='qualifications'], div[class*='qualifications'] li")
-	if err == nil && requirements != "" {
-		job.Requirements = strings.Split(requirements, "\n")
+	if len(job.Requirements) == 0 {
+		if requirements, err := extractTextGoquery(doc, "[data-automation-id='qualifications'], div[class*='qualifications'] li"); err == nil && requirements != "" {
+			job.Requirements = strings.Split(requirements, "\n")
+		}
 	}
 
-	// Extract job ID from URL or page
-	if strings.Contains(url, "/job/") {
-		parts := strings.Split(url, "/job/")
+	// Extract job ID from URL
+	if strings.Contains(src.URL, "/job/") {
+		parts := strings.Split(src.URL, "/job/")
 		if len(parts) > 1 {
 			jobID := strings.Split(parts[1], "/")[0]
 			job.ExternalID = jobID
 		}
 	}
 
-	// Extract HTML content
-	html, err := page.Content()
-	if err == nil {
-		job.RawHTML = html
-	}
+	job.RawHTML = htmlContent
 
 	// Validate required fields
 	if job.Title == "" || job.Company == "" {
@@ -111,3 +136,9 @@ func (p *WorkdayParser) GetSearchURLs(keywords, location string) []string {
 	// Return empty as we don't have a central search URL
 	return []string{}
 }
+
+func init() {
+	Register("workday", 10, []string{"myworkdayjobs.com", "workday.com"}, func() Parser {
+		return NewWorkdayParser()
+	})
+}
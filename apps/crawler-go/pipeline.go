@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/crawler-go/pkg/bus"
+)
+
+// CrawlingQueueName is the queue a CrawlRequest is published to for a
+// crawler worker to pick up.
+const CrawlingQueueName = "crawlingQueue"
+
+// CrawledQueueName is the queue a CrawlResultMessage is published to once
+// a crawl finishes, for parsers to consume (typically routing by the
+// result's URL hostname to the right ATS parser, e.g. GreenhouseParser).
+const CrawledQueueName = "crawledQueue"
+
+// CrawlRequest is the wire shape of a crawlingQueue message.
+type CrawlRequest struct {
+	JobID string `json:"job_id"`
+	URL   string `json:"url"`
+}
+
+// CrawlResultMessage is the wire shape of a crawledQueue message.
+type CrawlResultMessage struct {
+	JobID  string       `json:"job_id"`
+	Result *CrawlResult `json:"result"`
+}
+
+// idempotencyWindow bounds how long Pipeline remembers a job ID it has
+// already processed, so an at-least-once redelivery (a retry the bus
+// issued, or a duplicate publish) within that window is skipped rather
+// than crawled twice. It's a best-effort, per-process safety net on top
+// of the bus's own ack/nack semantics, not a substitute for them.
+const idempotencyWindow = 10 * time.Minute
+
+// Pipeline replaces the in-process CrawlBatch loop with a queue-consumer
+// model: it subscribes to CrawlingQueueName, crawls each requested URL
+// (honoring Politeness the same as CrawlBatch would), and publishes the
+// CrawlResult to CrawledQueueName.
+type Pipeline struct {
+	Bus           bus.Bus
+	SelectCrawler func(rawURL string) Crawler
+	Politeness    *PolitenessManager
+
+	seenMu sync.Mutex
+	seen   map[string]time.Time
+}
+
+// NewPipeline builds a Pipeline ready to Run.
+func NewPipeline(b bus.Bus, selectCrawler func(rawURL string) Crawler, politeness *PolitenessManager) *Pipeline {
+	return &Pipeline{
+		Bus:           b,
+		SelectCrawler: selectCrawler,
+		Politeness:    politeness,
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// Run subscribes to CrawlingQueueName and blocks until ctx is canceled or
+// the bus subscription fails fatally.
+func (p *Pipeline) Run(ctx context.Context) error {
+	return p.Bus.Subscribe(ctx, CrawlingQueueName, p.handle)
+}
+
+func (p *Pipeline) handle(ctx context.Context, msg bus.Message) error {
+	var req CrawlRequest
+	if err := json.Unmarshal(msg.Body, &req); err != nil {
+		return fmt.Errorf("decoding crawl request (job %s): %w", msg.JobID, err)
+	}
+
+	if p.alreadyProcessed(req.JobID) {
+		slog.Info("pipeline: skipping already-processed job", "job_id", req.JobID, "url", req.URL)
+		return nil
+	}
+
+	if p.Politeness != nil {
+		if !p.Politeness.Claim(req.URL) {
+			return fmt.Errorf("url %s already in flight", req.URL)
+		}
+		defer p.Politeness.Release(req.URL)
+
+		if !p.Politeness.Allowed(ctx, req.URL) {
+			slog.Info("pipeline: skipping url: disallowed by robots.txt", "url", req.URL)
+			if err := p.publishResult(ctx, req.JobID, &CrawlResult{URL: req.URL, CrawledAt: time.Now(), Error: "disallowed by robots.txt"}); err != nil {
+				return err
+			}
+			p.markProcessed(req.JobID)
+			return nil
+		}
+		if err := p.Politeness.Wait(ctx, req.URL); err != nil {
+			return fmt.Errorf("waiting for politeness on %s: %w", req.URL, err)
+		}
+	}
+
+	result, err := p.SelectCrawler(req.URL).CrawlURL(ctx, req.URL, optionsForURL(req.URL))
+	if err != nil {
+		return fmt.Errorf("crawling %s (job %s): %w", req.URL, req.JobID, err)
+	}
+
+	if err := p.publishResult(ctx, req.JobID, result); err != nil {
+		return err
+	}
+
+	p.markProcessed(req.JobID)
+	return nil
+}
+
+func (p *Pipeline) publishResult(ctx context.Context, jobID string, result *CrawlResult) error {
+	out := CrawlResultMessage{JobID: jobID, Result: result}
+	body, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encoding crawl result (job %s): %w", jobID, err)
+	}
+	if err := p.Bus.Publish(ctx, CrawledQueueName, jobID, body); err != nil {
+		return fmt.Errorf("publishing crawl result (job %s): %w", jobID, err)
+	}
+	return nil
+}
+
+func (p *Pipeline) alreadyProcessed(jobID string) bool {
+	p.seenMu.Lock()
+	defer p.seenMu.Unlock()
+	p.evictExpiredLocked()
+	_, ok := p.seen[jobID]
+	return ok
+}
+
+func (p *Pipeline) markProcessed(jobID string) {
+	p.seenMu.Lock()
+	defer p.seenMu.Unlock()
+	p.seen[jobID] = time.Now()
+}
+
+// evictExpiredLocked drops entries older than idempotencyWindow so seen
+// doesn't grow unbounded across a long-running process. Caller must hold
+// seenMu.
+func (p *Pipeline) evictExpiredLocked() {
+	cutoff := time.Now().Add(-idempotencyWindow)
+	for jobID, at := range p.seen {
+		if at.Before(cutoff) {
+			delete(p.seen, jobID)
+		}
+	}
+}
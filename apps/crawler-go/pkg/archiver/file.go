@@ -0,0 +1,32 @@
+package archiver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileStorage persists WARC volumes (and their CDX indexes) as files
+// under a rooted directory, for single-node runs that don't need object
+// storage.
+type fileStorage struct {
+	root string
+}
+
+func newFileStorage(root string) (*fileStorage, error) {
+	if root == "" {
+		root = "./data/warc"
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("creating warc archive root %s: %w", root, err)
+	}
+	return &fileStorage{root: root}, nil
+}
+
+func (s *fileStorage) Persist(name string, data []byte) (string, error) {
+	path := filepath.Join(s.root, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return "file://" + path, nil
+}
@@ -0,0 +1,59 @@
+package archiver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Storage persists WARC volumes (and their CDX indexes) as objects in
+// S3-compatible object storage, the same backend apps/api/internal/archive
+// uses for raw-HTML storage at larger scale than local disk.
+type s3Storage struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3Storage(bucket, prefix, region string) (*s3Storage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 archiver backend requires a bucket")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	return &s3Storage{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+func (s *s3Storage) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+func (s *s3Storage) Persist(name string, data []byte) (string, error) {
+	key := s.key(name)
+
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return "", fmt.Errorf("uploading %s to s3://%s: %w", key, s.bucket, err)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
+}
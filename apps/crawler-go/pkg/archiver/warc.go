@@ -0,0 +1,352 @@
+// Package archiver writes each crawl's raw request/response to a gzipped
+// WARC/1.1 volume plus a companion CDX index, mirroring the
+// archiver-service pattern common to OSINT crawlers: regulators and
+// downstream parsers can both be handed the exact bytes a job listing was
+// scraped from, and a listing can be reparsed later without re-crawling
+// it. Records are deduplicated by the SHA-256 of the response body, the
+// same idea apps/api/internal/archive's Backend.Exists check serves for
+// the jobs table's raw-HTML pointer, just content-addressed instead of
+// job-ID-addressed.
+package archiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiverConfig selects and configures a WARCWriter's Storage backend,
+// decoded from JSON like {"kind":"file","root":"./data/warc"}, the same
+// shape archive.Config and eventbus.Config use for their own pluggable
+// backends.
+type ArchiverConfig struct {
+	Kind   string `json:"kind"`             // "file" or "s3"
+	Root   string `json:"root,omitempty"`   // file
+	Bucket string `json:"bucket,omitempty"` // s3
+	Prefix string `json:"prefix,omitempty"` // s3
+	Region string `json:"region,omitempty"` // s3
+	// MaxVolumeBytes rotates to a new WARC volume once the current one's
+	// buffered, uncompressed size reaches it — gzip is only applied once,
+	// at rotation, to the whole finished volume, so this bounds the
+	// pre-compression size rather than the smaller .warc.gz Storage.Persist
+	// actually receives. <= 0 falls back to DefaultMaxVolumeBytes.
+	MaxVolumeBytes int64 `json:"max_volume_bytes,omitempty"`
+}
+
+// DefaultMaxVolumeBytes is ArchiverConfig.MaxVolumeBytes's default: 500MB,
+// a common WARC volume size that keeps individual files manageable for
+// both local disk and S3 multipart-free PutObject uploads.
+const DefaultMaxVolumeBytes = 500 * 1024 * 1024
+
+// Storage persists one completed WARC volume (and its companion CDX
+// index) under name. What "persisting" means — a local file, an S3
+// object — is owned by the backend; WARCWriter only ever hands it a
+// finished volume's bytes, never partial ones, since neither local files
+// nor S3 objects are append-friendly once rotation is in play.
+type Storage interface {
+	Persist(name string, data []byte) (location string, err error)
+}
+
+// New builds the Storage backend selected by cfg.Kind and wraps it in a
+// WARCWriter.
+func New(cfg ArchiverConfig) (*WARCWriter, error) {
+	var storage Storage
+	var err error
+	switch cfg.Kind {
+	case "file":
+		storage, err = newFileStorage(cfg.Root)
+	case "s3":
+		storage, err = newS3Storage(cfg.Bucket, cfg.Prefix, cfg.Region)
+	default:
+		return nil, fmt.Errorf("unknown archiver backend kind: %q", cfg.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	maxVolumeBytes := cfg.MaxVolumeBytes
+	if maxVolumeBytes <= 0 {
+		maxVolumeBytes = DefaultMaxVolumeBytes
+	}
+
+	return &WARCWriter{
+		storage:        storage,
+		maxVolumeBytes: maxVolumeBytes,
+		volumeIndex:    1,
+		seenDigests:    make(map[string]cdxEntry),
+	}, nil
+}
+
+// currentVolumeName is the name the in-progress volume will be persisted
+// under once it rotates — computed eagerly (rather than only at rotation
+// time) so a cdxEntry recorded while this volume is still open already
+// names the volume it will land in, and a later revisit record can point
+// back at it correctly even after rotation moves on.
+func (w *WARCWriter) currentVolumeName() string {
+	return fmt.Sprintf("archive-%06d.warc.gz", w.volumeIndex)
+}
+
+// FetchRecord is everything WARCWriter needs to archive one crawl: enough
+// of the request to reconstruct it and the response actually received.
+// CrawlResult (apps/crawler-go's own type) maps onto this directly; the
+// WARCWriter package deliberately doesn't import apps/crawler-go's
+// package main to avoid a cyclic dependency, so callers build a
+// FetchRecord from their CrawlResult instead.
+type FetchRecord struct {
+	JobID       string
+	URL         string
+	StatusCode  int
+	Headers     http.Header
+	ContentType string
+	Body        []byte
+	CrawledAt   time.Time
+}
+
+// cdxEntry is one line of a volume's companion CDX index.
+type cdxEntry struct {
+	jobID   string
+	digest  string
+	url     string
+	when    time.Time
+	volume  string
+	offset  int64
+	revisit bool
+}
+
+// WARCWriter buffers WARC records into an in-progress volume, rotating to
+// a new one (via Storage.Persist) once maxVolumeBytes would be exceeded,
+// and maintains an in-process SHA-256 dedup index so a response body
+// already archived becomes a "revisit" record (pointing back at the
+// original) instead of a second full copy. The dedup index is
+// per-process, not shared across restarts or replicas — the same
+// best-effort tradeoff Pipeline's idempotency cache makes, acceptable
+// here since a duplicate isn't incorrect, just a few extra archived
+// bytes.
+type WARCWriter struct {
+	storage        Storage
+	maxVolumeBytes int64
+
+	mu          sync.Mutex
+	volume      bytes.Buffer
+	cdxLines    []string
+	volumeIndex int
+	seenDigests map[string]cdxEntry
+}
+
+// Archive appends rec's request, response, and metadata records to the
+// current WARC volume, rotating first if the volume is already at
+// capacity. A response body whose SHA-256 digest was already archived in
+// this process is written as a "revisit" record referencing the original
+// instead of duplicating the body.
+func (w *WARCWriter) Archive(rec FetchRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	digest := sha256Hex(rec.Body)
+	original, deduped := w.seenDigests[digest]
+
+	recordID := newRecordID()
+	var offset int64
+
+	if deduped {
+		offset = int64(w.volume.Len())
+		w.writeRevisitRecord(rec, recordID, digest, original)
+	} else {
+		requestID := newRecordID()
+		w.writeRequestRecord(rec, requestID)
+		offset = int64(w.volume.Len())
+		w.writeResponseRecord(rec, recordID, digest, requestID)
+	}
+	w.writeMetadataRecord(rec, recordID)
+
+	entry := cdxEntry{jobID: rec.JobID, digest: digest, url: rec.URL, when: rec.CrawledAt, volume: w.currentVolumeName(), offset: offset, revisit: deduped}
+	w.cdxLines = append(w.cdxLines, formatCDXLine(entry))
+	if !deduped {
+		w.seenDigests[digest] = entry
+	}
+
+	if int64(w.volume.Len()) >= w.maxVolumeBytes {
+		return w.rotateLocked()
+	}
+	return nil
+}
+
+// PersistArtifact writes data under name via the same Storage backend a
+// WARC volume rotates to, for debugging artifacts (e.g. a failure
+// screenshot) that don't belong inside the WARC format itself but should
+// still land next to it.
+func (w *WARCWriter) PersistArtifact(name string, data []byte) (string, error) {
+	return w.storage.Persist(name, data)
+}
+
+// Close flushes any buffered, not-yet-rotated volume data to storage.
+func (w *WARCWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.volume.Len() == 0 {
+		return nil
+	}
+	return w.rotateLocked()
+}
+
+// rotateLocked persists the current volume and its CDX index, then resets
+// WARCWriter for a fresh volume. Caller must hold w.mu.
+func (w *WARCWriter) rotateLocked() error {
+	name := w.currentVolumeName()
+
+	compressed, err := gzipCompress(w.volume.Bytes())
+	if err != nil {
+		return fmt.Errorf("compressing warc volume %s: %w", name, err)
+	}
+	if _, err := w.storage.Persist(name, compressed); err != nil {
+		return fmt.Errorf("persisting warc volume %s: %w", name, err)
+	}
+
+	cdxBody := strings.Join(w.cdxLines, "\n")
+	if len(w.cdxLines) > 0 {
+		cdxBody += "\n"
+	}
+	if _, err := w.storage.Persist(name+".cdx", []byte(cdxBody)); err != nil {
+		return fmt.Errorf("persisting cdx index for %s: %w", name, err)
+	}
+
+	w.volume.Reset()
+	w.cdxLines = nil
+	w.volumeIndex++
+	return nil
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sha256Hex(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// newRecordID returns a WARC-Record-ID in the "<urn:uuid:...>" form WARC
+// readers expect, built from crypto/rand bytes formatted as a UUIDv4 —
+// there's no need to pull in a uuid dependency for an identifier that's
+// only ever compared for equality within this archive.
+func newRecordID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (w *WARCWriter) writeRequestRecord(rec FetchRecord, recordID string) {
+	body := []byte(fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUser-Agent: QuietHireCrawler\r\n\r\n", rec.URL, hostOf(rec.URL)))
+	w.writeRecord("request", recordID, "", rec, body, "application/http;msgtype=request", "")
+}
+
+func (w *WARCWriter) writeResponseRecord(rec FetchRecord, recordID, digest, requestID string) {
+	body := httpResponseBytes(rec)
+	w.writeRecord("response", recordID, requestID, rec, body, "application/http;msgtype=response", digest)
+}
+
+func (w *WARCWriter) writeRevisitRecord(rec FetchRecord, recordID, digest string, original cdxEntry) {
+	headers := fmt.Sprintf(
+		"WARC-Refers-To-Target-URI: %s\r\nWARC-Refers-To-Date: %s\r\nWARC-Refers-To-Volume: %s\r\nWARC-Refers-To-Offset: %d\r\n",
+		original.url, original.when.UTC().Format(time.RFC3339), original.volume, original.offset,
+	)
+	w.writeRecordWithExtraHeaders("revisit", recordID, "", rec, nil, "message/http", digest, headers)
+}
+
+func (w *WARCWriter) writeMetadataRecord(rec FetchRecord, concurrentTo string) {
+	meta := map[string]interface{}{
+		"job_id":       rec.JobID,
+		"status_code":  rec.StatusCode,
+		"content_type": rec.ContentType,
+	}
+	body, _ := json.Marshal(meta)
+	w.writeRecord("metadata", newRecordID(), concurrentTo, rec, body, "application/json", "")
+}
+
+func (w *WARCWriter) writeRecord(recordType, recordID, concurrentTo string, rec FetchRecord, body []byte, contentType, blockDigest string) {
+	w.writeRecordWithExtraHeaders(recordType, recordID, concurrentTo, rec, body, contentType, blockDigest, "")
+}
+
+// writeRecordWithExtraHeaders appends one WARC record to the in-progress
+// volume: a "WARC/1.1" header block followed by body. Every WARC file
+// QuietHire produces follows the same "one WARC-Type record after
+// another, no special inter-record compression" layout real WARC readers
+// expect.
+func (w *WARCWriter) writeRecordWithExtraHeaders(recordType, recordID, concurrentTo string, rec FetchRecord, body []byte, contentType, blockDigest, extraHeaders string) {
+	var headers strings.Builder
+	headers.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&headers, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&headers, "WARC-Record-ID: %s\r\n", recordID)
+	fmt.Fprintf(&headers, "WARC-Date: %s\r\n", rec.CrawledAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&headers, "WARC-Target-URI: %s\r\n", rec.URL)
+	if concurrentTo != "" {
+		fmt.Fprintf(&headers, "WARC-Concurrent-To: %s\r\n", concurrentTo)
+	}
+	if blockDigest != "" {
+		fmt.Fprintf(&headers, "WARC-Block-Digest: sha256:%s\r\n", blockDigest)
+	}
+	headers.WriteString(extraHeaders)
+	fmt.Fprintf(&headers, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&headers, "Content-Length: %d\r\n", len(body))
+	headers.WriteString("\r\n")
+
+	w.volume.WriteString(headers.String())
+	w.volume.Write(body)
+	w.volume.WriteString("\r\n\r\n")
+}
+
+// httpResponseBytes renders rec as a raw HTTP/1.1 response: status line,
+// headers, blank line, body — the Content-Type WARC response records
+// expect their block to hold.
+func httpResponseBytes(rec FetchRecord) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", rec.StatusCode, http.StatusText(rec.StatusCode))
+	for key, values := range rec.Headers {
+		for _, v := range values {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, v)
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(rec.Body)
+	return buf.Bytes()
+}
+
+func hostOf(rawURL string) string {
+	without := strings.TrimPrefix(strings.TrimPrefix(rawURL, "https://"), "http://")
+	if idx := strings.IndexAny(without, "/?#"); idx >= 0 {
+		without = without[:idx]
+	}
+	return without
+}
+
+// formatCDXLine renders entry in a simplified CDX format: jobID, SHA-256
+// digest, URL, capture timestamp, which volume and byte offset the record
+// starts at, and whether it's a revisit (vs. the original capture) —
+// enough for a reparse job to locate and verify a specific capture,
+// including following a revisit back to its original volume/offset,
+// without pulling in a full CDX-format parser.
+func formatCDXLine(entry cdxEntry) string {
+	kind := "response"
+	if entry.revisit {
+		kind = "revisit"
+	}
+	return fmt.Sprintf("%s %s %s %s %s %s %d", entry.jobID, entry.digest, entry.url, entry.when.UTC().Format(time.RFC3339), kind, entry.volume, entry.offset)
+}
@@ -0,0 +1,116 @@
+// Package bus is a pluggable message-queue abstraction so the crawler can
+// be driven by a queue consumer instead of an in-process CrawlBatch loop,
+// the same shift Trandoshan/Bathyscaphe made with their RabbitMQ-based
+// crawlingQueue: the crawler subscribes to a "crawlingQueue" of URLs to
+// fetch and publishes finished CrawlResults to a "crawledQueue" for
+// parsers to consume by hostname. This decouples how many crawler/parser
+// workers are online from how fast jobs are produced, the same goal
+// apps/api/internal/eventbus solves for one-way lifecycle events — bus
+// additionally supports consuming with ack/nack, so a backend can redeliver
+// work a handler failed to process.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Message is one delivery from a Bus. Attempt is 1 on first delivery and
+// increments on every redelivery, so a Handler (or the bus implementation
+// itself) can cap retries or adjust behavior on repeated failures.
+type Message struct {
+	JobID   string
+	Body    []byte
+	Attempt int
+}
+
+// Handler processes one Message. Returning nil acks the message; a non-nil
+// error nacks it, triggering a backoff-delayed redelivery up to the Bus's
+// configured RetryConfig.MaxAttempts, after which the message is moved to
+// the queue's dead-letter queue (queue name + ".dlq") and acked so it stops
+// blocking the queue.
+type Handler func(ctx context.Context, msg Message) error
+
+// Bus publishes to and consumes from named queues. What a "queue" is (a
+// Redis stream, a NATS subject, a RabbitMQ queue) is owned by the backend.
+type Bus interface {
+	// Publish enqueues body onto queue under jobID, the idempotency key
+	// (see GenerateJobID) a consumer can use to recognize and skip a
+	// message it already processed.
+	Publish(ctx context.Context, queue, jobID string, body []byte) error
+
+	// Subscribe consumes queue, invoking handler for each message per
+	// Handler's ack/nack contract above. It blocks until ctx is canceled
+	// or a fatal backend error occurs.
+	Subscribe(ctx context.Context, queue string, handler Handler) error
+
+	Close() error
+}
+
+// Config selects and configures a Bus, decoded from JSON like
+// {"kind":"redis","addr":"localhost:6379"}, the same shape eventbus.Config
+// and archive.Config use for their own pluggable backends.
+type Config struct {
+	Kind string `json:"kind"` // "redis", "nats", or "rabbitmq"
+	Addr string `json:"addr"` // Redis "host:port", NATS URL, or AMQP URL
+}
+
+// RetryConfig tunes how many times a failed message is redelivered, and
+// the backoff schedule between attempts, before it's moved to the queue's
+// dead-letter queue. Mirrors apps/api/internal/retry.Config's shape, the
+// repo's established exponential-backoff-with-jitter schedule.
+type RetryConfig struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxDelay     time.Duration
+	// MaxAttempts is the total number of deliveries a message gets,
+	// including the first — MaxAttempts of 5 means up to 4 redeliveries
+	// before dead-lettering.
+	MaxAttempts int
+}
+
+// DefaultRetryConfig is the standard crawl-job redelivery schedule: 1s
+// initial backoff, doubling each attempt, capped at 5m, up to 5 attempts.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		InitialDelay: time.Second,
+		Multiplier:   2.0,
+		MaxDelay:     5 * time.Minute,
+		MaxAttempts:  5,
+	}
+}
+
+// backoffWithJitter returns how long to wait before redelivering a message
+// for the given attempt (1-indexed: attempt 1 is the delay before the
+// second delivery).
+func backoffWithJitter(cfg RetryConfig, attempt int) time.Duration {
+	backoff := time.Duration(float64(cfg.InitialDelay) * math.Pow(cfg.Multiplier, float64(attempt-1)))
+	if backoff > cfg.MaxDelay {
+		backoff = cfg.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff/2 + jitter
+}
+
+// dlqName returns the dead-letter queue name a backend uses for queue.
+func dlqName(queue string) string {
+	return queue + ".dlq"
+}
+
+// New builds the Bus selected by cfg.Kind, retrying failed deliveries per
+// retryCfg.
+func New(cfg Config, retryCfg RetryConfig) (Bus, error) {
+	switch cfg.Kind {
+	case "redis":
+		return NewRedisBus(cfg.Addr, retryCfg)
+	case "nats":
+		return NewNATSBus(cfg.Addr, retryCfg)
+	case "rabbitmq":
+		return NewRabbitMQBus(cfg.Addr, retryCfg)
+	default:
+		return nil, fmt.Errorf("unknown bus backend kind: %q", cfg.Kind)
+	}
+}
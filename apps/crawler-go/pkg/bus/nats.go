@@ -0,0 +1,150 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsStreamName is the single JetStream stream every queue's subject
+// lives under, matching how RedisBus keeps every queue in one consumer
+// group's namespace.
+const natsStreamName = "QUIETHIRE_CRAWL"
+
+// NATSBus publishes to and consumes from subjects on one JetStream
+// stream, one subject per queue name. Idempotency is enforced natively via
+// JetStream's Nats-Msg-Id deduplication window, rather than RedisBus's
+// manual approach.
+type NATSBus struct {
+	conn     *nats.Conn
+	js       nats.JetStreamContext
+	retryCfg RetryConfig
+}
+
+// NewNATSBus connects to url (e.g. "nats://localhost:4222") and ensures
+// the shared JetStream stream exists.
+func NewNATSBus(url string, retryCfg RetryConfig) (*NATSBus, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to nats at %s: %w", url, err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("getting jetstream context: %w", err)
+	}
+
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:       natsStreamName,
+		Subjects:   []string{natsStreamName + ".>"},
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil && err != nats.ErrStreamNameAlreadyInUse {
+		conn.Close()
+		return nil, fmt.Errorf("creating jetstream stream %s: %w", natsStreamName, err)
+	}
+
+	return &NATSBus{conn: conn, js: js, retryCfg: retryCfg}, nil
+}
+
+func subjectFor(queue string) string {
+	return natsStreamName + "." + queue
+}
+
+func (b *NATSBus) Publish(ctx context.Context, queue, jobID string, body []byte) error {
+	msg := nats.NewMsg(subjectFor(queue))
+	msg.Data = body
+	msg.Header.Set(nats.MsgIdHdr, jobID)
+
+	_, err := b.js.PublishMsg(msg, nats.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("publishing to nats subject %s: %w", subjectFor(queue), err)
+	}
+	return nil
+}
+
+// Subscribe creates (or reuses) a durable, explicit-ack JetStream consumer
+// for queue and dispatches deliveries to handler until ctx is canceled.
+// JetStream's own MaxDeliver redelivers a Nak'd message automatically;
+// Subscribe only has to decide, from the delivery's own attempt count,
+// whether this is the final attempt (dead-letter it) or an earlier one
+// (Nak with backoff for JetStream to retry).
+func (b *NATSBus) Subscribe(ctx context.Context, queue string, handler Handler) error {
+	durable := "quiethire-" + queue
+	sub, err := b.js.PullSubscribe(subjectFor(queue), durable, nats.AckExplicit(), nats.MaxDeliver(b.retryCfg.MaxAttempts))
+	if err != nil {
+		return fmt.Errorf("subscribing to nats subject %s: %w", subjectFor(queue), err)
+	}
+	defer func() {
+		if unsubErr := sub.Unsubscribe(); unsubErr != nil {
+			slog.Error("bus: unsubscribing from nats subject", "subject", subjectFor(queue), "error", unsubErr)
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		msgs, err := sub.Fetch(10, nats.MaxWait(5*time.Second))
+		if err != nil {
+			if err == nats.ErrTimeout {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("bus: fetching from nats subject", "subject", subjectFor(queue), "error", err)
+			continue
+		}
+
+		for _, msg := range msgs {
+			b.handleMsg(ctx, queue, msg, handler)
+		}
+	}
+}
+
+func (b *NATSBus) handleMsg(ctx context.Context, queue string, msg *nats.Msg, handler Handler) {
+	jobID := msg.Header.Get(nats.MsgIdHdr)
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempt = int(meta.NumDelivered)
+	}
+
+	handlerErr := handler(ctx, Message{JobID: jobID, Body: msg.Data, Attempt: attempt})
+	if handlerErr == nil {
+		if err := msg.Ack(); err != nil {
+			slog.Error("bus: acking nats message", "queue", queue, "job_id", jobID, "error", err)
+		}
+		return
+	}
+
+	slog.Error("bus: handler failed", "queue", queue, "job_id", jobID, "attempt", attempt, "error", handlerErr)
+	if attempt >= b.retryCfg.MaxAttempts {
+		slog.Warn("bus: job exhausted attempts, dead-lettering", "job_id", jobID, "queue", queue, "attempt", attempt)
+		// Only ack once the dead-letter publish actually lands; if it
+		// fails, leave the message un-acked so JetStream redelivers it
+		// (and retries the dead-letter publish) rather than losing it.
+		if err := b.Publish(context.Background(), dlqName(queue), jobID, msg.Data); err != nil {
+			slog.Error("bus: dead-lettering job", "job_id", jobID, "queue", queue, "error", err)
+			return
+		}
+		if err := msg.Ack(); err != nil {
+			slog.Error("bus: acking exhausted nats message", "queue", queue, "job_id", jobID, "error", err)
+		}
+		return
+	}
+
+	if err := msg.NakWithDelay(backoffWithJitter(b.retryCfg, attempt)); err != nil {
+		slog.Error("bus: nacking nats message", "queue", queue, "job_id", jobID, "error", err)
+	}
+}
+
+func (b *NATSBus) Close() error {
+	b.conn.Close()
+	return nil
+}
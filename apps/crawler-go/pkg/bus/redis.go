@@ -0,0 +1,191 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisConsumerGroup is the single consumer group every RedisBus reads a
+// stream through. All crawler replicas share it, so XReadGroup spreads each
+// queue's messages across whichever replicas are online (rather than every
+// replica seeing every message, as plain XREAD/pub-sub would).
+const redisConsumerGroup = "quiethire-crawlers"
+
+// RedisBus publishes to and consumes from Redis streams, one stream per
+// queue name, via XAdd/XReadGroup/XAck. A retry is a republish of the same
+// body onto the same stream with Attempt incremented, after a backoff
+// sleep and an XAck of the original entry — Redis streams don't have
+// RabbitMQ/NATS JetStream's native redelivery, so RedisBus fakes it with a
+// requeue, same as every other backend's Subscribe honors the shared
+// RetryConfig identically regardless of what the wire backend natively
+// supports.
+type RedisBus struct {
+	client   *redis.Client
+	retryCfg RetryConfig
+	consumer string
+}
+
+// NewRedisBus dials addr ("host:port").
+func NewRedisBus(addr string, retryCfg RetryConfig) (*RedisBus, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("connecting to redis at %s: %w", addr, err)
+	}
+	return &RedisBus{
+		client:   client,
+		retryCfg: retryCfg,
+		consumer: fmt.Sprintf("consumer-%d", time.Now().UnixNano()),
+	}, nil
+}
+
+func (b *RedisBus) Publish(ctx context.Context, queue, jobID string, body []byte) error {
+	return b.publishAttempt(ctx, queue, jobID, body, 1)
+}
+
+func (b *RedisBus) publishAttempt(ctx context.Context, queue, jobID string, body []byte, attempt int) error {
+	err := b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: queue,
+		Values: map[string]interface{}{
+			"job_id":  jobID,
+			"body":    body,
+			"attempt": attempt,
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("publishing to redis stream %s: %w", queue, err)
+	}
+	return nil
+}
+
+// Subscribe ensures queue's consumer group exists, then loops reading new
+// entries via XReadGroup and dispatching them to handler, acking on
+// success and requeuing (with backoff) or dead-lettering on failure, until
+// ctx is canceled.
+func (b *RedisBus) Subscribe(ctx context.Context, queue string, handler Handler) error {
+	if err := b.ensureGroup(ctx, queue); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    redisConsumerGroup,
+			Consumer: b.consumer,
+			Streams:  []string{queue, ">"},
+			Count:    10,
+			Block:    5 * time.Second,
+		}).Result()
+		if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("bus: reading redis stream", "queue", queue, "error", err)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				b.handleEntry(ctx, queue, entry, handler)
+			}
+		}
+	}
+}
+
+func (b *RedisBus) ensureGroup(ctx context.Context, queue string) error {
+	err := b.client.XGroupCreateMkStream(ctx, queue, redisConsumerGroup, "0").Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		// BUSYGROUP means the group already exists, which is the common case
+		// after the first replica creates it; anything else is a real error.
+		if !isBusyGroupErr(err) {
+			return fmt.Errorf("creating redis consumer group for %s: %w", queue, err)
+		}
+	}
+	return nil
+}
+
+func isBusyGroupErr(err error) bool {
+	return err != nil && len(err.Error()) >= 9 && err.Error()[:9] == "BUSYGROUP"
+}
+
+func (b *RedisBus) handleEntry(ctx context.Context, queue string, entry redis.XMessage, handler Handler) {
+	jobID, _ := entry.Values["job_id"].(string)
+	bodyStr, _ := entry.Values["body"].(string)
+	attempt := parseAttempt(entry.Values["attempt"])
+
+	msg := Message{JobID: jobID, Body: []byte(bodyStr), Attempt: attempt}
+
+	acked := true
+	if err := handler(ctx, msg); err != nil {
+		slog.Error("bus: handler failed", "queue", queue, "job_id", jobID, "attempt", attempt, "error", err)
+		acked = b.redeliver(ctx, queue, jobID, msg.Body, attempt)
+	}
+	// Only ack once the message has actually been republished (or
+	// dead-lettered); acking an entry that redeliver failed to republish
+	// would drop it instead of leaving it pending for a future retry.
+	if !acked {
+		return
+	}
+
+	if ackErr := b.client.XAck(ctx, queue, redisConsumerGroup, entry.ID).Err(); ackErr != nil {
+		slog.Error("bus: acking redis entry", "entry_id", entry.ID, "queue", queue, "error", ackErr)
+	}
+}
+
+// redeliver either republishes body with attempt+1 after a backoff sleep,
+// or — once retryCfg.MaxAttempts is reached — publishes it to queue's DLQ
+// instead, so a permanently-failing message stops being retried forever.
+// It reports whether the republish (or dead-letter) actually succeeded;
+// handleEntry must not ack the original entry when it returns false. A
+// canceled ctx only skips the rest of the backoff wait — the republish
+// itself always uses a background context, so a mid-wait shutdown signal
+// can't turn into a lost message.
+func (b *RedisBus) redeliver(ctx context.Context, queue, jobID string, body []byte, attempt int) bool {
+	if attempt >= b.retryCfg.MaxAttempts {
+		slog.Warn("bus: job exhausted attempts, dead-lettering", "job_id", jobID, "queue", queue, "attempt", attempt)
+		if err := b.publishAttempt(context.Background(), dlqName(queue), jobID, body, attempt); err != nil {
+			slog.Error("bus: dead-lettering job", "job_id", jobID, "queue", queue, "error", err)
+			return false
+		}
+		return true
+	}
+
+	select {
+	case <-time.After(backoffWithJitter(b.retryCfg, attempt)):
+	case <-ctx.Done():
+	}
+	if err := b.publishAttempt(context.Background(), queue, jobID, body, attempt+1); err != nil {
+		slog.Error("bus: requeuing job", "job_id", jobID, "queue", queue, "error", err)
+		return false
+	}
+	return true
+}
+
+func parseAttempt(v interface{}) int {
+	switch val := v.(type) {
+	case string:
+		if n, err := strconv.Atoi(val); err == nil {
+			return n
+		}
+	case int64:
+		return int(val)
+	}
+	return 1
+}
+
+func (b *RedisBus) Close() error {
+	return b.client.Close()
+}
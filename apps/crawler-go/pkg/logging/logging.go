@@ -0,0 +1,49 @@
+// Package logging configures the crawler's default slog.Logger: JSON
+// output for production (easy to ship to a log aggregator), or tinted
+// plain-text output for local development when LOG_PRETTY=true. Callers
+// elsewhere in the module log via the top-level slog.Info/slog.Error/etc.
+// functions against this configured default rather than threading a
+// *slog.Logger through every constructor.
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/lmittmann/tint"
+)
+
+// Init builds the process-wide default slog.Logger and installs it via
+// slog.SetDefault. pretty selects tinted, human-readable output (color
+// dropped automatically when w isn't a terminal); otherwise records are
+// emitted as JSON lines, one per record.
+func Init(pretty bool, w io.Writer) {
+	var handler slog.Handler
+	if pretty {
+		handler = tint.NewHandler(w, &tint.Options{
+			Level:      slog.LevelInfo,
+			NoColor:    !isTerminal(w),
+			TimeFormat: "15:04:05",
+		})
+	} else {
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// isTerminal reports whether w looks like it's connected to a terminal,
+// so pretty output only colorizes when something will actually render the
+// ANSI codes (e.g. not when LOG_PRETTY=true but stderr is redirected to a
+// file).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,52 @@
+// Package tracing wires up OpenTelemetry distributed tracing for the
+// crawler: a span per fetch and per parse, exported via OTLP/HTTP to a
+// collector, so a CrawlResult's trace/span IDs can be correlated with the
+// rest of that job's lifecycle in ClickHouse.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Tracer is the crawler's shared tracer, used to start a span per
+// CrawlURL invocation and per parser Parse call.
+var Tracer = otel.Tracer("github.com/itisrohit/quiethire/apps/crawler-go")
+
+// Init configures the global TracerProvider to export spans via
+// OTLP/HTTP to endpoint (host:port, no scheme — e.g. "localhost:4318"). If
+// endpoint is empty, tracing stays a no-op (otel's default provider),
+// since not every deployment runs a collector. The returned shutdown
+// flushes and closes the exporter; call it before the process exits.
+func Init(ctx context.Context, serviceName, endpoint string) (shutdown func(context.Context) error, err error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter for %s: %w", endpoint, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	Tracer = provider.Tracer(serviceName)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCrawlDelay is PolitenessManager's floor when neither the caller
+// nor a host's robots.txt specifies one.
+const defaultCrawlDelay = 1 * time.Second
+
+// robotsCacheTTL bounds how long a parsed robots.txt is reused before
+// PolitenessManager refetches it, so a site that changes its rules doesn't
+// stay misconfigured here for the life of the process.
+const robotsCacheTTL = 24 * time.Hour
+
+// robotsRules is one host's parsed robots.txt: just enough to answer
+// Allowed and a Crawl-delay — longest-prefix Allow/Disallow matching under
+// the wildcard User-agent group, not a full RFC 9309 implementation. That
+// covers every ATS/job-board robots.txt QuietHire has actually crawled.
+type robotsRules struct {
+	disallow   []string
+	allow      []string
+	crawlDelay time.Duration
+	fetchedAt  time.Time
+}
+
+// Allowed reports whether path is allowed, per the longest matching
+// Allow/Disallow prefix — the same precedence most crawlers give an
+// overlapping Allow/Disallow pair.
+func (r *robotsRules) Allowed(path string) bool {
+	bestAllow, bestDisallow := -1, -1
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) > bestAllow {
+			bestAllow = len(p)
+		}
+	}
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > bestDisallow {
+			bestDisallow = len(p)
+		}
+	}
+	return bestAllow >= bestDisallow
+}
+
+// hostBucket is a per-host token bucket: capacity tokens, refilled at
+// refillRate tokens/sec, one consumed per request. It sits alongside
+// PolitenessManager's minCrawlDelay floor so a host that tolerates
+// occasional bursts doesn't have every request serialized to a fixed
+// delay, while the floor still protects the very first requests of a
+// burst. Not safe for concurrent use; callers must hold PolitenessManager.mu.
+type hostBucket struct {
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens per second
+	lastRefill time.Time
+}
+
+func newHostBucket(capacity, refillRate float64) *hostBucket {
+	return &hostBucket{tokens: capacity, capacity: capacity, refillRate: refillRate, lastRefill: time.Now()}
+}
+
+// take refills b for elapsed time, then either consumes a token (returning
+// 0, meaning proceed now) or returns how long until one is available.
+func (b *hostBucket) take() time.Duration {
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = minFloat(b.capacity, b.tokens+elapsed*b.refillRate)
+	b.lastRefill = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	missing := 1 - b.tokens
+	b.tokens = 0
+	return time.Duration(missing / b.refillRate * float64(time.Second))
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// PolitenessManager enforces robots.txt, a per-host token-bucket rate
+// limit with a minimum crawl-delay floor, and in-flight URL dedup, so
+// CrawlBatch's per-host workers never hammer a single site or crawl the
+// same URL twice concurrently.
+type PolitenessManager struct {
+	client    *http.Client
+	userAgent string
+
+	minCrawlDelay    time.Duration
+	bucketCapacity   float64
+	bucketRefillRate float64
+
+	mu          sync.Mutex
+	robotsCache map[string]*robotsRules
+	buckets     map[string]*hostBucket
+	lastCrawl   map[string]time.Time
+	inFlight    map[string]bool
+}
+
+// NewPolitenessManager creates a PolitenessManager. minCrawlDelay <= 0
+// falls back to defaultCrawlDelay; bucketCapacity/bucketRefillRate <= 0
+// fall back to a single-token bucket refilled at 1/minCrawlDelay (i.e. the
+// bucket alone reduces to the same behavior as the delay floor).
+func NewPolitenessManager(userAgent string, minCrawlDelay time.Duration, bucketCapacity, bucketRefillRate float64) *PolitenessManager {
+	if minCrawlDelay <= 0 {
+		minCrawlDelay = defaultCrawlDelay
+	}
+	if bucketCapacity <= 0 {
+		bucketCapacity = 1
+	}
+	if bucketRefillRate <= 0 {
+		bucketRefillRate = 1 / minCrawlDelay.Seconds()
+	}
+	return &PolitenessManager{
+		client:           &http.Client{Timeout: 10 * time.Second},
+		userAgent:        userAgent,
+		minCrawlDelay:    minCrawlDelay,
+		bucketCapacity:   bucketCapacity,
+		bucketRefillRate: bucketRefillRate,
+		robotsCache:      make(map[string]*robotsRules),
+		buckets:          make(map[string]*hostBucket),
+		lastCrawl:        make(map[string]time.Time),
+		inFlight:         make(map[string]bool),
+	}
+}
+
+// Allowed reports whether rawURL is allowed by its host's robots.txt,
+// fetching and caching the rules on first use (or once robotsCacheTTL has
+// elapsed). A robots.txt that can't be fetched at all is treated as
+// allow-everything, the conservative convention when a site doesn't
+// publish one (or is temporarily unreachable).
+func (pm *PolitenessManager) Allowed(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return pm.robotsFor(ctx, u).Allowed(u.Path)
+}
+
+func (pm *PolitenessManager) robotsFor(ctx context.Context, u *url.URL) *robotsRules {
+	host := u.Host
+
+	pm.mu.Lock()
+	cached, ok := pm.robotsCache[host]
+	pm.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < robotsCacheTTL {
+		return cached
+	}
+
+	rules := pm.fetchRobots(ctx, u)
+	pm.mu.Lock()
+	pm.robotsCache[host] = rules
+	pm.mu.Unlock()
+	return rules
+}
+
+func (pm *PolitenessManager) fetchRobots(ctx context.Context, u *url.URL) *robotsRules {
+	rules := &robotsRules{fetchedAt: time.Now()}
+
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", u.Scheme, u.Host)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return rules
+	}
+	req.Header.Set("User-Agent", pm.userAgent)
+
+	resp, err := pm.client.Do(req)
+	if err != nil {
+		return rules
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Error("closing robots.txt response", "host", u.Host, "error", closeErr)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return rules
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return rules
+	}
+	parseRobots(rules, body)
+	return rules
+}
+
+// parseRobots fills rules from body, honoring only the "User-agent: *"
+// group — QuietHire's crawler doesn't identify itself with a distinct UA
+// that sites give different rules to.
+func parseRobots(rules *robotsRules, body []byte) {
+	inWildcardGroup := false
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		directive := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch directive {
+		case "user-agent":
+			inWildcardGroup = value == "*"
+		case "disallow":
+			if inWildcardGroup && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		case "allow":
+			if inWildcardGroup && value != "" {
+				rules.allow = append(rules.allow, value)
+			}
+		case "crawl-delay":
+			if inWildcardGroup {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil {
+					rules.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+}
+
+// Wait blocks until rawURL's host clears both its token bucket and its
+// minimum crawl-delay floor (robots.txt's Crawl-delay, if larger than
+// pm.minCrawlDelay), or ctx is canceled.
+func (pm *PolitenessManager) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	host := u.Host
+
+	pm.mu.Lock()
+	delay := pm.minCrawlDelay
+	if rules, ok := pm.robotsCache[host]; ok && rules.crawlDelay > delay {
+		delay = rules.crawlDelay
+	}
+	last, seenBefore := pm.lastCrawl[host]
+
+	bucket, ok := pm.buckets[host]
+	if !ok {
+		bucket = newHostBucket(pm.bucketCapacity, pm.bucketRefillRate)
+		pm.buckets[host] = bucket
+	}
+	wait := bucket.take()
+	pm.mu.Unlock()
+
+	if seenBefore {
+		if delayWait := delay - time.Since(last); delayWait > wait {
+			wait = delayWait
+		}
+	}
+
+	if wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	pm.mu.Lock()
+	pm.lastCrawl[host] = time.Now()
+	pm.mu.Unlock()
+	return nil
+}
+
+// Claim reports whether rawURL isn't already being crawled by another
+// goroutine, marking it in-flight if so. CrawlBatch's current one-goroutine-
+// per-host grouping means a duplicate Claim never actually happens there,
+// but PolitenessManager is shared state meant to be safe for any caller
+// that submits overlapping URLs concurrently, including future ones that
+// don't go through CrawlBatch's host grouping. Pair every successful Claim
+// with a Release once the crawl finishes.
+func (pm *PolitenessManager) Claim(rawURL string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.inFlight[rawURL] {
+		return false
+	}
+	pm.inFlight[rawURL] = true
+	return true
+}
+
+// Release clears rawURL's in-flight marker.
+func (pm *PolitenessManager) Release(rawURL string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	delete(pm.inFlight, rawURL)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/itisrohit/quiethire/apps/crawler-go/parsers"
+	"github.com/playwright-community/playwright-go"
+)
+
+// CrawlOptions tunes how PlaywrightCrawler decides a page has finished
+// rendering before reading its content, replacing a single hardcoded sleep
+// with a wait adapted to the ATS being crawled.
+type CrawlOptions struct {
+	// WaitSelectors are CSS selectors indicating the page has rendered its
+	// job content (e.g. ".app-title" for Greenhouse, via
+	// parsers.SelectorHints); the first to appear satisfies the wait.
+	// Empty falls back to the network-idle heuristic.
+	WaitSelectors []string
+	// SelectorTimeout bounds how long WaitSelectors is given to appear
+	// before falling back to the network-idle heuristic.
+	SelectorTimeout time.Duration
+	// IdleQuietPeriod is how long document.readyState must stay
+	// "complete" with zero pending XHRs before the network-idle
+	// heuristic considers the page rendered.
+	IdleQuietPeriod time.Duration
+	// MaxIdleWait bounds the network-idle heuristic's total wait.
+	MaxIdleWait time.Duration
+	// ScrollToBottom scrolls the page to the bottom (repeatedly, until its
+	// height stops growing) before reading content, for lazy-loaded job
+	// lists that only render once scrolled into view.
+	ScrollToBottom bool
+}
+
+// DefaultCrawlOptions is the adaptive-wait schedule used when a URL's ATS
+// doesn't expose WaitSelectors (see optionsForURL).
+func DefaultCrawlOptions() CrawlOptions {
+	return CrawlOptions{
+		SelectorTimeout: 8 * time.Second,
+		IdleQuietPeriod: 500 * time.Millisecond,
+		MaxIdleWait:     10 * time.Second,
+		ScrollToBottom:  true,
+	}
+}
+
+// optionsForURL builds CrawlOptions for rawURL, filling WaitSelectors from
+// the parser registry's matching parser when it implements
+// parsers.SelectorHints (e.g. GreenhouseParser's ".app-title"), so
+// PlaywrightCrawler can wait for a selector specific to the detected ATS
+// rather than only the generic network-idle heuristic.
+func optionsForURL(rawURL string) CrawlOptions {
+	opts := DefaultCrawlOptions()
+	parser, err := parsers.Resolve(rawURL)
+	if err != nil {
+		return opts
+	}
+	if hints, ok := parser.(parsers.SelectorHints); ok {
+		opts.WaitSelectors = hints.WaitSelectors()
+	}
+	return opts
+}
+
+// waitForRender blocks until page looks rendered, using opts.WaitSelectors
+// when present (fast and ATS-specific) and the network-idle heuristic
+// otherwise or as a fallback when none of them showed up within
+// opts.SelectorTimeout.
+func waitForRender(page playwright.Page, opts CrawlOptions) {
+	if len(opts.WaitSelectors) > 0 && waitForAnySelector(page, opts.WaitSelectors, opts.SelectorTimeout) {
+		return
+	}
+	waitForNetworkIdle(page, opts.IdleQuietPeriod, opts.MaxIdleWait)
+}
+
+// waitForAnySelector waits for the first of selectors to become visible,
+// up to timeout per selector, returning true as soon as one appears.
+func waitForAnySelector(page playwright.Page, selectors []string, timeout time.Duration) bool {
+	timeoutMS := playwright.Float(float64(timeout.Milliseconds()))
+	for _, selector := range selectors {
+		if _, err := page.WaitForSelector(selector, playwright.PageWaitForSelectorOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: timeoutMS,
+		}); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForNetworkIdle polls the page (via pendingRequestTrackerScript's
+// window.__qhPendingRequests counter, installed before navigation) until
+// document.readyState is "complete" and no fetch()/XHR call has been
+// in flight for quietPeriod, or maxWait has elapsed — whichever comes
+// first. A page where the tracker script failed to install still quiets
+// on readyState alone, just without the pending-request signal.
+func waitForNetworkIdle(page playwright.Page, quietPeriod, maxWait time.Duration) {
+	const pollInterval = 100 * time.Millisecond
+	deadline := time.Now().Add(maxWait)
+	var quietSince time.Time
+
+	for {
+		quiet, err := pageIsQuiet(page)
+		if err != nil {
+			slog.Warn("network-idle check failed, stopping wait early", "error", err)
+			return
+		}
+
+		now := time.Now()
+		if quiet {
+			if quietSince.IsZero() {
+				quietSince = now
+			} else if now.Sub(quietSince) >= quietPeriod {
+				return
+			}
+		} else {
+			quietSince = time.Time{}
+		}
+
+		if now.Add(pollInterval).After(deadline) {
+			return
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// pageIsQuiet reports whether page's document has finished loading and has
+// no pending fetch()/XHR call outstanding.
+func pageIsQuiet(page playwright.Page) (bool, error) {
+	value, err := page.Evaluate(`() => document.readyState === 'complete' && (window.__qhPendingRequests || 0) === 0`)
+	if err != nil {
+		return false, err
+	}
+	quiet, _ := value.(bool)
+	return quiet, nil
+}
+
+// scrollToBottom repeatedly scrolls page to the bottom of its document,
+// waiting between scrolls for lazy-loaded content (job lists that only
+// fetch more rows once scrolled into view) to render, until the
+// document's height stops growing or maxScrolls is reached.
+func scrollToBottom(page playwright.Page) {
+	const maxScrolls = 20
+	const settleWait = 300 * time.Millisecond
+
+	var lastHeight float64
+	for i := 0; i < maxScrolls; i++ {
+		if _, err := page.Evaluate(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			slog.Warn("scroll-to-bottom failed, stopping early", "error", err)
+			return
+		}
+		time.Sleep(settleWait)
+
+		height, err := page.Evaluate(`() => document.body.scrollHeight`)
+		if err != nil {
+			slog.Warn("could not read document height, stopping scroll", "error", err)
+			return
+		}
+		h, _ := height.(float64)
+		if h <= lastHeight {
+			return
+		}
+		lastHeight = h
+	}
+}
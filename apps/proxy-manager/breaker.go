@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// BreakerState is one state in a Proxy's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// consecutiveFailThreshold is how many failures in a row trip a Closed
+// breaker to Open — the same threshold the old FailCount >= 3 cutoff used.
+const consecutiveFailThreshold = 3
+
+// baseCircuitBackoff/maxCircuitBackoff bound how long an Open breaker waits
+// before allowing a HalfOpen probe: backoff doubles with every trip
+// (baseCircuitBackoff * 2^trips), capped at maxCircuitBackoff so a
+// long-dead proxy still gets probed occasionally instead of being
+// abandoned forever.
+const (
+	baseCircuitBackoff = 5 * time.Second
+	maxCircuitBackoff  = 10 * time.Minute
+)
+
+// CircuitBreaker is a proxy's trip state, persisted to Redis alongside the
+// rest of Proxy (via saveProxyHash/saveProxyHealthFields, see
+// redis_store.go) so it survives a proxy-manager restart.
+type CircuitBreaker struct {
+	State       BreakerState `json:"state"`
+	OpenedAt    time.Time    `json:"opened_at,omitempty"`
+	NextProbeAt time.Time    `json:"next_probe_at,omitempty"`
+	Trips       int          `json:"trips"`
+
+	// probing is true from the moment GetNextProxy hands out the single
+	// HalfOpen probe until its outcome is reported via MarkProxySuccess/
+	// MarkProxyFailure, so a second concurrent caller can't also slip
+	// through as a probe. Unexported: it's in-process coordination, not
+	// state worth persisting — a restart mid-probe just allows one extra
+	// probe, which is harmless.
+	probing bool
+}
+
+// StateOrClosed returns cb.State, treating the zero value (a proxy added
+// before breaker state existed, or never yet tripped) as Closed.
+func (cb CircuitBreaker) StateOrClosed() BreakerState {
+	if cb.State == "" {
+		return BreakerClosed
+	}
+	return cb.State
+}
+
+// backoffFor returns how long an Open breaker waits before its next
+// HalfOpen probe, given how many times it's tripped in a row so far.
+// Jitter of up to 20% keeps many simultaneously-tripped proxies from all
+// retrying in the same instant.
+func backoffFor(trips int) time.Duration {
+	backoff := baseCircuitBackoff
+	for i := 0; i < trips && backoff < maxCircuitBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxCircuitBackoff {
+		backoff = maxCircuitBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/5 + 1))
+	return backoff + jitter
+}
+
+// tripOpen opens p's breaker (or re-opens it, if it was already Open or
+// HalfOpen), scheduling its next probe with a backoff based on how many
+// times it's tripped before, then incrementing that trip count.
+func tripOpen(p *Proxy, now time.Time) {
+	from := p.Breaker.StateOrClosed()
+	p.Breaker.State = BreakerOpen
+	p.Breaker.OpenedAt = now
+	p.Breaker.NextProbeAt = now.Add(backoffFor(p.Breaker.Trips))
+	p.Breaker.Trips++
+	p.Breaker.probing = false
+	logBreakerTransition(p.URL, from, BreakerOpen)
+}
+
+// breakerAllows reports whether p's circuit breaker currently permits
+// handing it out, transitioning Open to HalfOpen in place once its backoff
+// has elapsed. It does not claim the HalfOpen probe slot — call claimProbe
+// on whichever proxy a SelectionStrategy actually picks, so building a
+// candidate list (which calls this for every proxy) doesn't burn the one
+// probe on a proxy that isn't ultimately returned.
+func breakerAllows(p *Proxy, now time.Time) bool {
+	switch p.Breaker.State {
+	case BreakerOpen:
+		if now.Before(p.Breaker.NextProbeAt) {
+			return false
+		}
+		p.Breaker.State = BreakerHalfOpen
+		logBreakerTransition(p.URL, BreakerOpen, BreakerHalfOpen)
+		return !p.Breaker.probing
+	case BreakerHalfOpen:
+		return !p.Breaker.probing
+	default:
+		return true
+	}
+}
+
+// claimProbe marks p's HalfOpen breaker as having its one probe in flight;
+// a no-op in every other state.
+func claimProbe(p *Proxy) {
+	if p.Breaker.State == BreakerHalfOpen {
+		p.Breaker.probing = true
+	}
+}
+
+// recordBreakerSuccess closes p's breaker and resets its trip counter, the
+// outcome of either a normal Closed-state request or a successful HalfOpen
+// probe.
+func recordBreakerSuccess(p *Proxy) {
+	if p.Breaker.State == BreakerHalfOpen {
+		p.Breaker.State = BreakerClosed
+		p.Breaker.Trips = 0
+		logBreakerTransition(p.URL, BreakerHalfOpen, BreakerClosed)
+	}
+	p.Breaker.probing = false
+}
+
+// recordBreakerFailure trips p's breaker open. A failed HalfOpen probe
+// re-opens immediately with a doubled backoff (via tripOpen's use of the
+// existing trip count); a Closed breaker only trips once FailCount reaches
+// consecutiveFailThreshold, the same threshold the old hard cutoff used.
+func recordBreakerFailure(p *Proxy, now time.Time) {
+	if p.Breaker.State == BreakerHalfOpen {
+		tripOpen(p, now)
+		return
+	}
+	if p.FailCount >= consecutiveFailThreshold {
+		tripOpen(p, now)
+	}
+}
+
+// timeUntilProbe returns how long until p's Open breaker allows its next
+// HalfOpen probe, or 0 if it isn't Open or the backoff has already elapsed.
+func timeUntilProbe(p *Proxy, now time.Time) time.Duration {
+	if p.Breaker.State != BreakerOpen || !now.Before(p.Breaker.NextProbeAt) {
+		return 0
+	}
+	return p.Breaker.NextProbeAt.Sub(now)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// defaultEventLogMaxSize is NewProxyEventLog's default rotation threshold,
+// overridable via EVENT_LOG_MAX_SIZE_MB.
+const defaultEventLogMaxSize = 100 * 1024 * 1024
+
+// maxEventLogRotations bounds nextRotatedPath's "path.NNN" search: once
+// 999 rotated generations of one log exist, something downstream has
+// stopped consuming/archiving them and piling up a 1000th would rather be
+// a loud failure than silent, unbounded disk growth.
+const maxEventLogRotations = 999
+
+// eventLogBufferSize bounds Log's channel: callers like selectProxyLocked
+// and MarkProxySuccess/MarkProxyFailure invoke it while holding pm.mu, so
+// Log can never block on disk I/O (a rename+reopen during rotation
+// included) without stalling every other goroutine waiting on that lock. A
+// full buffer means the writer goroutine genuinely can't keep up; the event
+// is dropped (and counted) rather than blocking its caller.
+const eventLogBufferSize = 4096
+
+// eventLog is the process-wide proxy event log, set up in main() alongside
+// the manager/ctx globals. Every logging helper below is a no-op if it's
+// nil, so a failure to open the log file at startup degrades to "no event
+// log" rather than crashing the service.
+var eventLog *ProxyEventLog
+
+// ProxyEventLog appends structured JSON event records (selections,
+// success/failure reports, breaker transitions, health-check results) to a
+// file, so operators can reconstruct after the fact why a given crawl
+// worker got a bad proxy. It rotates the file once it exceeds maxSize or
+// crosses a daily boundary, renaming the old one to the first unused
+// "path.NNN" suffix and gzip-compressing it in the background.
+//
+// Log only ever enqueues onto events; a single writer goroutine (run) owns
+// path/maxSize/file/size/openedDay exclusively, so none of that state needs
+// a mutex and none of Log's callers ever block on the disk I/O a write or
+// rotation does — several of them (selectProxyLocked,
+// MarkProxySuccess/MarkProxyFailure) call Log while holding pm.mu.
+type ProxyEventLog struct {
+	path    string
+	maxSize int64
+	events  chan proxyLogEvent
+
+	file *os.File
+	size int64
+	// openedDay is the "2006-01-02" date the current file was opened on, so
+	// run can tell when a new calendar day means it's time to rotate even
+	// if maxSize hasn't been reached.
+	openedDay string
+}
+
+// proxyLogEvent is one line of the JSON event stream. Fields not relevant
+// to a given Kind are left zero and omitted from the encoded line.
+type proxyLogEvent struct {
+	Time      time.Time    `json:"time"`
+	Kind      string       `json:"kind"` // "selection", "success", "failure", "breaker_transition", "health_check"
+	ProxyURL  string       `json:"proxy_url,omitempty"`
+	Strategy  string       `json:"strategy,omitempty"`
+	LatencyMs int          `json:"latency_ms,omitempty"`
+	FromState BreakerState `json:"from_state,omitempty"`
+	ToState   BreakerState `json:"to_state,omitempty"`
+	Healthy   *bool        `json:"healthy,omitempty"`
+}
+
+// NewProxyEventLog opens (creating if necessary) the event log at path,
+// rotating on maxSize bytes (or defaultEventLogMaxSize if <= 0) or a daily
+// boundary, whichever comes first.
+func NewProxyEventLog(path string, maxSize int64) (*ProxyEventLog, error) {
+	if maxSize <= 0 {
+		maxSize = defaultEventLogMaxSize
+	}
+	l := &ProxyEventLog{path: path, maxSize: maxSize, events: make(chan proxyLogEvent, eventLogBufferSize)}
+	if err := l.openCurrent(); err != nil {
+		return nil, err
+	}
+	go l.run()
+	return l, nil
+}
+
+// run is the sole writer goroutine: it owns every piece of ProxyEventLog's
+// file state and drains events for the life of the process.
+func (l *ProxyEventLog) run() {
+	for event := range l.events {
+		l.write(event)
+	}
+}
+
+// openCurrent opens (or reopens, after a rotation) l.path for appending and
+// records its current size and the day it was opened on.
+func (l *ProxyEventLog) openCurrent() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening event log %s: %w", l.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat-ing event log %s: %w", l.path, err)
+	}
+	l.file = f
+	l.size = info.Size()
+	l.openedDay = time.Now().Format("2006-01-02")
+	return nil
+}
+
+// Log enqueues event for the writer goroutine, never blocking: a full
+// buffer (the writer genuinely can't keep up) drops the event with a log
+// line rather than stalling the caller, which may be holding pm.mu.
+func (l *ProxyEventLog) Log(event proxyLogEvent) {
+	select {
+	case l.events <- event:
+	default:
+		log.Printf("proxy event log buffer full, dropping %s event for %s", event.Kind, event.ProxyURL)
+	}
+}
+
+// write appends event as one JSON line, rotating first if needed. Only
+// run calls this, so it's the only thing touching l's file state. Failures
+// are logged rather than returned: losing one event shouldn't take down the
+// writer goroutine, the same best-effort treatment publishEvent
+// (redis_store.go) gives a missed pub/sub message.
+func (l *ProxyEventLog) write(event proxyLogEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("encoding proxy event log entry: %v", err)
+		return
+	}
+	body = append(body, '\n')
+
+	if l.shouldRotate() {
+		if err := l.rotate(); err != nil {
+			log.Printf("rotating event log %s: %v", l.path, err)
+		}
+	}
+
+	n, err := l.file.Write(body)
+	if err != nil {
+		log.Printf("writing event log entry: %v", err)
+		return
+	}
+	l.size += int64(n)
+}
+
+func (l *ProxyEventLog) shouldRotate() bool {
+	return l.size >= l.maxSize || time.Now().Format("2006-01-02") != l.openedDay
+}
+
+// rotate closes the current file, renames it to the first unused
+// "path.NNN" suffix, gzip-compresses that renamed file in the background,
+// and opens a fresh file at path. If a step fails, it falls back to
+// reopening/keeping the existing file rather than losing the ability to
+// log at all.
+func (l *ProxyEventLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		log.Printf("closing event log before rotation: %v", err)
+	}
+
+	rotatedPath, err := nextRotatedPath(l.path)
+	if err != nil {
+		if openErr := l.openCurrent(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := os.Rename(l.path, rotatedPath); err != nil {
+		if openErr := l.openCurrent(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("renaming %s to %s: %w", l.path, rotatedPath, err)
+	}
+
+	go compressRotatedFile(rotatedPath)
+
+	return l.openCurrent()
+}
+
+// nextRotatedPath returns the first "path.NNN" (001-999) that doesn't
+// already exist.
+func nextRotatedPath(path string) (string, error) {
+	for i := 1; i <= maxEventLogRotations; i++ {
+		candidate := fmt.Sprintf("%s.%03d", path, i)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("all %d rotation slots for %s are in use", maxEventLogRotations, path)
+}
+
+// compressRotatedFile gzips rotatedPath to rotatedPath+".gz", removing the
+// uncompressed copy once done. Runs in its own goroutine so compressing a
+// near-maxSize file never blocks the Log call that triggered rotation.
+func compressRotatedFile(rotatedPath string) {
+	src, err := os.Open(rotatedPath)
+	if err != nil {
+		log.Printf("opening rotated event log %s for compression: %v", rotatedPath, err)
+		return
+	}
+	defer src.Close()
+
+	dstPath := rotatedPath + ".gz"
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		log.Printf("creating compressed event log %s: %v", dstPath, err)
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		log.Printf("compressing event log %s: %v", rotatedPath, err)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		log.Printf("closing gzip writer for %s: %v", dstPath, err)
+		return
+	}
+
+	if err := os.Remove(rotatedPath); err != nil {
+		log.Printf("removing uncompressed rotated event log %s: %v", rotatedPath, err)
+	}
+}
+
+// logSelection appends a "selection" event recording which proxy was handed
+// out and via which strategy ("" meaning the default).
+func logSelection(proxy *Proxy, strategyName string) {
+	if eventLog == nil {
+		return
+	}
+	eventLog.Log(proxyLogEvent{Time: time.Now(), Kind: "selection", ProxyURL: proxy.URL, Strategy: strategyName})
+}
+
+// logOutcome appends a "success" or "failure" event for proxyURL.
+func logOutcome(kind, proxyURL string, latencyMs int) {
+	if eventLog == nil {
+		return
+	}
+	eventLog.Log(proxyLogEvent{Time: time.Now(), Kind: kind, ProxyURL: proxyURL, LatencyMs: latencyMs})
+}
+
+// logHealthCheck appends a "health_check" event recording whether proxyURL
+// passed.
+func logHealthCheck(proxyURL string, healthy bool) {
+	if eventLog == nil {
+		return
+	}
+	eventLog.Log(proxyLogEvent{Time: time.Now(), Kind: "health_check", ProxyURL: proxyURL, Healthy: &healthy})
+}
+
+// logBreakerTransition appends a "breaker_transition" event if from and to
+// differ; a no-op otherwise so breaker.go's callers don't each need their
+// own "did it actually change" check.
+func logBreakerTransition(proxyURL string, from, to BreakerState) {
+	if eventLog == nil || from == to {
+		return
+	}
+	eventLog.Log(proxyLogEvent{Time: time.Now(), Kind: "breaker_transition", ProxyURL: proxyURL, FromState: from, ToState: to})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/net/proxy"
+)
+
+// defaultHealthCheckURLs is HEALTH_CHECK_URLS' fallback when unset: a small
+// spread of independent IP-echo services, so one of them being down doesn't
+// read as the entire proxy pool failing health checks.
+var defaultHealthCheckURLs = []string{
+	"https://httpbin.org/ip",
+	"https://api.ipify.org",
+	"https://icanhazip.com",
+}
+
+// healthCheckTimeout bounds a single proxied health-check request.
+const healthCheckTimeout = 10 * time.Second
+
+// ipPattern extracts the reported IP out of a health-check target's
+// response body regardless of whether it replies with JSON
+// (httpbin.org/ip's {"origin": "..."}) or bare text (ipify, icanhazip).
+var ipPattern = regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`)
+
+// proxyHealthCheckLatency records how long a proxied health-check request
+// took, labeled by which proxy, which target it hit, and the proxy's
+// protocol, so a slow/flaky target or protocol stands out across the pool
+// rather than being folded into one undifferentiated average.
+var proxyHealthCheckLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "proxy_health_check_latency_seconds",
+	Help:    "Latency of a single proxied health-check request, by proxy, target, and protocol.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"proxy", "target", "protocol"})
+
+// healthCheckTargets loads HEALTH_CHECK_URLS (comma-separated), falling
+// back to defaultHealthCheckURLs when unset.
+func healthCheckTargets() []string {
+	raw := os.Getenv("HEALTH_CHECK_URLS")
+	if raw == "" {
+		return defaultHealthCheckURLs
+	}
+
+	var targets []string
+	for _, u := range strings.Split(raw, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		return defaultHealthCheckURLs
+	}
+	return targets
+}
+
+// directIPRefreshInterval controls how often RunDirectIPRefresh re-measures
+// pm's own egress IP, so a changing egress IP (NAT rotation, a rescheduled
+// pod) doesn't leave CheckProxyHealth comparing against a stale baseline
+// for the life of the process.
+const directIPRefreshInterval = 10 * time.Minute
+
+// RunDirectIPRefresh determines pm's direct IP immediately, then re-measures
+// it every directIPRefreshInterval until the process exits. It's meant to
+// run in its own goroutine: determining it can take several seconds per
+// target if one is slow or unreachable, and blocking main() on it risks
+// tripping a container orchestrator's startup probe before app.Listen is
+// even reached.
+func (pm *ProxyManager) RunDirectIPRefresh() {
+	pm.refreshDirectIP()
+
+	ticker := time.NewTicker(directIPRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		pm.refreshDirectIP()
+	}
+}
+
+// refreshDirectIP measures pm's current direct IP and stores it, logging
+// rather than failing when every target is unreachable — CheckProxyHealth
+// simply skips the non-masking check until a future refresh succeeds.
+func (pm *ProxyManager) refreshDirectIP() {
+	ip, err := determineDirectIP(pm.targets)
+	if err != nil {
+		log.Printf("Warning: could not determine direct IP, health checks won't detect non-masking proxies: %v", err)
+		return
+	}
+
+	pm.mu.Lock()
+	pm.directIP = ip
+	pm.mu.Unlock()
+	log.Printf("Direct IP determined as %s", ip)
+}
+
+// getDirectIP returns pm's last-measured direct IP.
+func (pm *ProxyManager) getDirectIP() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.directIP
+}
+
+// removeProxyHealthMetrics drops every proxy_health_check_latency_seconds
+// series recorded for proxyURL, so a rotating proxy pool doesn't leave the
+// histogram accumulating one abandoned series per proxy ever seen.
+func removeProxyHealthMetrics(proxyURL string) {
+	proxyHealthCheckLatency.DeletePartialMatch(prometheus.Labels{"proxy": proxyURL})
+}
+
+// determineDirectIP makes a direct (unproxied) request against the first
+// reachable target and extracts the IP it reports, so CheckProxyHealth has
+// a baseline to compare a proxied response against — a proxy that's merely
+// forwarding straight through without actually masking the origin IP
+// should fail health checks, not silently pass them.
+func determineDirectIP(targets []string) (string, error) {
+	client := &http.Client{Timeout: healthCheckTimeout}
+
+	var lastErr error
+	for _, target := range targets {
+		resp, err := client.Get(target)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		closeErr := resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if closeErr != nil {
+			log.Printf("closing direct IP response body: %v", closeErr)
+		}
+
+		if ip := extractIP(body); ip != "" {
+			return ip, nil
+		}
+		lastErr = fmt.Errorf("no IP found in response from %s", target)
+	}
+	return "", fmt.Errorf("determining direct IP: %w", lastErr)
+}
+
+// extractIP pulls the first IPv4 address out of body.
+func extractIP(body []byte) string {
+	return ipPattern.FindString(string(body))
+}
+
+// nextHealthCheckTarget round-robins pm.targets so repeated health checks
+// spread across every configured target instead of hammering just the
+// first one.
+func (pm *ProxyManager) nextHealthCheckTarget() string {
+	i := atomic.AddUint64(&pm.healthCheckTargetIdx, 1)
+	return pm.targets[int(i)%len(pm.targets)]
+}
+
+// proxyTransport builds an http.Transport that routes through proxy: for
+// http/https a plain CONNECT/forward proxy via http.ProxyURL, for socks5 a
+// golang.org/x/net/proxy.SOCKS5 dialer wrapped as DialContext.
+func proxyTransport(p *Proxy) (*http.Transport, error) {
+	switch p.Protocol {
+	case "http", "https":
+		proxyURL := &url.URL{
+			Scheme: p.Protocol,
+			Host:   fmt.Sprintf("%s:%d", p.Host, p.Port),
+		}
+		if p.Username != "" {
+			proxyURL.User = url.UserPassword(p.Username, p.Password)
+		}
+		return &http.Transport{Proxy: http.ProxyURL(proxyURL)}, nil
+
+	case "socks5":
+		var auth *proxy.Auth
+		if p.Username != "" {
+			auth = &proxy.Auth{User: p.Username, Password: p.Password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", fmt.Sprintf("%s:%d", p.Host, p.Port), auth, proxy.Direct)
+		if err != nil {
+			return nil, fmt.Errorf("building socks5 dialer for %s: %w", p.URL, err)
+		}
+
+		transport := &http.Transport{}
+		if ctxDialer, ok := dialer.(interface {
+			DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+		}); ok {
+			transport.DialContext = ctxDialer.DialContext
+		} else {
+			transport.Dial = dialer.Dial
+		}
+		return transport, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported proxy protocol %q for %s", p.Protocol, p.URL)
+	}
+}
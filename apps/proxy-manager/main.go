@@ -5,16 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -31,7 +35,23 @@ type Proxy struct {
 	FailCount    int       `json:"fail_count"`
 	SuccessCount int       `json:"success_count"`
 	AvgLatency   int       `json:"avg_latency_ms"`
-	IsHealthy    bool      `json:"is_healthy"`
+	// LatencySamples counts how many times AvgLatency has been folded into
+	// by updateLatencyEWMA, so a proxy whose EWMA has genuinely settled at
+	// 0ms isn't mistaken for one that's never reported a sample.
+	LatencySamples int  `json:"latency_samples"`
+	InFlight       int  `json:"in_flight"`
+	IsHealthy      bool `json:"is_healthy"`
+
+	// Breaker is this proxy's circuit breaker state, which gates
+	// GetNextProxy independently of IsHealthy (kept in sync with it for
+	// GetProxyByCountry/GetStats, but Breaker is what actually decides
+	// whether GetNextProxy hands this proxy out).
+	Breaker CircuitBreaker `json:"breaker"`
+
+	// Tags are free-form labels (e.g. "datacenter", "residential", "mobile")
+	// a RoutingRule's RequireTags can filter SelectProxyForURL's candidates
+	// by.
+	Tags []string `json:"tags,omitempty"`
 }
 
 // ProxyManager manages proxy rotation and health checks
@@ -40,7 +60,30 @@ type ProxyManager struct {
 	proxies             []*Proxy
 	mu                  sync.RWMutex
 	healthCheckInterval time.Duration
-	currentIndex        int
+
+	// txMaxAttempts/txRetryBaseDelay tune acquireLock's retry-with-backoff
+	// schedule for proxyListLockKey, the distributed lock guarding
+	// structural changes (add/remove) to the proxies index shared across
+	// replicas.
+	txMaxAttempts    int
+	txRetryBaseDelay time.Duration
+
+	// targets is HEALTH_CHECK_URLS, rotated across by nextHealthCheckTarget
+	// so CheckProxyHealth doesn't hammer a single endpoint.
+	targets              []string
+	healthCheckTargetIdx uint64
+	// directIP is this process's own IP as reported by determineDirectIP at
+	// startup — CheckProxyHealth compares a proxy's reported IP against it
+	// to catch a proxy that's transparently forwarding instead of masking
+	// the origin.
+	directIP string
+
+	// rules is the ordered list SelectProxyForURL checks a target URL
+	// against, first match wins. stickySessions maps a host to the proxy
+	// URL a StickySession rule has pinned it to, so repeat requests for the
+	// same host keep the same egress IP until that proxy goes unhealthy.
+	rules          []RoutingRule
+	stickySessions map[string]string
 }
 
 var (
@@ -48,13 +91,22 @@ var (
 	ctx     = context.Background()
 )
 
+// defaultTxMaxAttempts/defaultTxRetryBaseDelay are NewProxyManager's
+// defaults, overridable via TX_MAX_ATTEMPTS/TX_RETRY_BASE_DELAY_MS.
+const (
+	defaultTxMaxAttempts    = 5
+	defaultTxRetryBaseDelay = 50 * time.Millisecond
+)
+
 // NewProxyManager creates a new proxy manager
 func NewProxyManager(redisClient *redis.Client) *ProxyManager {
 	return &ProxyManager{
 		proxies:             make([]*Proxy, 0),
-		currentIndex:        0,
 		redis:               redisClient,
 		healthCheckInterval: 5 * time.Minute,
+		txMaxAttempts:       defaultTxMaxAttempts,
+		txRetryBaseDelay:    defaultTxRetryBaseDelay,
+		stickySessions:      make(map[string]string),
 	}
 }
 
@@ -70,82 +122,99 @@ func (pm *ProxyManager) LoadProxiesFromEnv() error {
 	if err := json.Unmarshal([]byte(proxiesJSON), &proxies); err != nil {
 		return fmt.Errorf("failed to parse PROXIES: %w", err)
 	}
+	resetInFlight(proxies)
 
 	pm.mu.Lock()
 	pm.proxies = proxies
 	pm.mu.Unlock()
 
 	log.Printf("Loaded %d proxies from environment", len(proxies))
+	pm.updatePoolMetrics()
 	return nil
 }
 
-// LoadProxiesFromRedis loads proxies from Redis
+// LoadProxiesFromRedis loads proxies from the per-proxy hashes referenced by
+// proxiesIndexKey (see redis_store.go), replacing the old monolithic
+// proxies:list JSON blob this used to read.
 func (pm *ProxyManager) LoadProxiesFromRedis() error {
-	data, err := pm.redis.Get(ctx, "proxies:list").Result()
-	if err == redis.Nil {
-		log.Println("No proxies found in Redis")
-		return nil
-	}
+	proxies, err := pm.loadProxiesFromIndex()
 	if err != nil {
 		return fmt.Errorf("failed to load proxies from Redis: %w", err)
 	}
-
-	var proxies []*Proxy
-	if err := json.Unmarshal([]byte(data), &proxies); err != nil {
-		return fmt.Errorf("failed to parse proxies from Redis: %w", err)
+	if len(proxies) == 0 {
+		log.Println("No proxies found in Redis")
+		return nil
 	}
+	// InFlight reflects requests a now-gone process handed out; nothing
+	// will ever release them, so it must not carry over a restart.
+	resetInFlight(proxies)
 
 	pm.mu.Lock()
 	pm.proxies = proxies
 	pm.mu.Unlock()
 
 	log.Printf("Loaded %d proxies from Redis", len(proxies))
+	pm.updatePoolMetrics()
 	return nil
 }
 
-// SaveProxiesToRedis saves proxies to Redis
-func (pm *ProxyManager) SaveProxiesToRedis() error {
-	pm.mu.RLock()
-	data, err := json.Marshal(pm.proxies)
-	pm.mu.RUnlock()
-
-	if err != nil {
-		return fmt.Errorf("failed to marshal proxies: %w", err)
-	}
-
-	if err := pm.redis.Set(ctx, "proxies:list", data, 0).Err(); err != nil {
-		return fmt.Errorf("failed to save proxies to Redis: %w", err)
+// resetInFlight zeroes every proxy's InFlight, since it only makes sense
+// relative to requests this process itself handed out and is tracking.
+func resetInFlight(proxies []*Proxy) {
+	for _, p := range proxies {
+		p.InFlight = 0
 	}
+}
 
-	return nil
+// GetNextProxy returns a healthy proxy chosen by strategyName (see
+// strategyByName for the supported names; an unrecognized or empty name
+// falls back to round_robin). The chosen proxy's InFlight is incremented
+// before it's returned, so LeastConnectionsStrategy only ever sees a proxy
+// as busy once a caller has actually been handed it; callers are expected
+// to pair this with /api/v1/proxy/release once they're done with it.
+func (pm *ProxyManager) GetNextProxy(strategyName string) (*Proxy, error) {
+	return observeSelectionDuration(func() (*Proxy, error) {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		return pm.selectProxyLocked(strategyName, time.Now())
+	})
 }
 
-// GetNextProxy returns the next available healthy proxy (round-robin)
-func (pm *ProxyManager) GetNextProxy() (*Proxy, error) {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
+// GetBreakerState returns proxyURL's circuit breaker snapshot and how long
+// until it's next eligible for a HalfOpen probe (0 if not Open, or already
+// due).
+func (pm *ProxyManager) GetBreakerState(proxyURL string) (CircuitBreaker, time.Duration, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
 
-	if len(pm.proxies) == 0 {
-		return nil, fmt.Errorf("no proxies available")
+	for _, proxy := range pm.proxies {
+		if proxy.URL == proxyURL {
+			return proxy.Breaker, timeUntilProbe(proxy, time.Now()), nil
+		}
 	}
+	return CircuitBreaker{}, 0, fmt.Errorf("proxy not found: %s", proxyURL)
+}
 
-	// Find next healthy proxy
-	attempts := 0
-	maxAttempts := len(pm.proxies)
-
-	for attempts < maxAttempts {
-		pm.currentIndex = (pm.currentIndex + 1) % len(pm.proxies)
-		proxy := pm.proxies[pm.currentIndex]
+// ReleaseProxy decrements proxyURL's in-flight count, floored at zero so a
+// caller that releases without a matching GetNextProxy (or releases twice)
+// can't push it negative. It also clears a claimed HalfOpen probe without
+// otherwise touching Breaker: a caller that only releases, never reporting
+// success/failure, still needs the breaker to try another probe rather
+// than being stuck with probing permanently true.
+func (pm *ProxyManager) ReleaseProxy(proxyURL string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 
-		if proxy.IsHealthy || proxy.FailCount < 3 {
-			proxy.LastUsed = time.Now()
-			return proxy, nil
+	for _, proxy := range pm.proxies {
+		if proxy.URL == proxyURL {
+			if proxy.InFlight > 0 {
+				proxy.InFlight--
+			}
+			proxy.Breaker.probing = false
+			return true
 		}
-
-		attempts++
 	}
-
-	return nil, fmt.Errorf("no healthy proxies available")
+	return false
 }
 
 // GetProxyByCountry returns a healthy proxy from a specific country
@@ -163,61 +232,150 @@ func (pm *ProxyManager) GetProxyByCountry(country string) (*Proxy, error) {
 	return nil, fmt.Errorf("no healthy proxies available for country: %s", country)
 }
 
-// MarkProxySuccess marks a proxy as successful
-func (pm *ProxyManager) MarkProxySuccess(proxyURL string) {
+// MarkProxySuccess marks a proxy as successful. latencyMs <= 0 means the
+// caller didn't measure one (e.g. an older client still posting just
+// proxy_url); AvgLatency's EWMA is only updated when a real sample is
+// given. Also decrements InFlight, the same as /api/v1/proxy/release,
+// since reporting an outcome is itself a "done with this proxy" signal —
+// a caller that always reports success/failure shouldn't also need to
+// remember a separate release call just to keep LeastConnections accurate.
+func (pm *ProxyManager) MarkProxySuccess(proxyURL string, latencyMs int) {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
+	var snapshot Proxy
+	var healthChanged bool
+	found := false
 	for _, proxy := range pm.proxies {
 		if proxy.URL == proxyURL {
+			wasHealthy := proxy.IsHealthy
 			proxy.SuccessCount++
 			proxy.FailCount = 0
-			proxy.IsHealthy = true
+			recordBreakerSuccess(proxy)
+			proxy.IsHealthy = proxy.Breaker.StateOrClosed() != BreakerOpen
+			if latencyMs > 0 {
+				updateLatencyEWMA(proxy, latencyMs)
+			}
+			if proxy.InFlight > 0 {
+				proxy.InFlight--
+			}
+			healthChanged = proxy.IsHealthy != wasHealthy
+			snapshot = *proxy
+			found = true
 			break
 		}
 	}
+	pm.mu.Unlock()
 
-	// Save to Redis asynchronously
+	if !found {
+		return
+	}
+
+	proxyRequestsTotal.WithLabelValues(proxyURL, "success").Inc()
+	if latencyMs > 0 {
+		proxyLatencyMs.WithLabelValues(proxyURL).Observe(float64(latencyMs))
+	}
+	logOutcome("success", proxyURL, latencyMs)
+	if healthChanged {
+		pm.updatePoolMetrics()
+	}
+
+	// Persist asynchronously: the success counter via an atomic HINCRBY so
+	// a concurrent increment from another replica is never clobbered, and
+	// everything else (breaker state, latency, health) as a plain HSET of
+	// this replica's locally-computed snapshot.
 	go func() {
-		if err := pm.SaveProxiesToRedis(); err != nil {
-			log.Printf("Failed to save proxies to Redis: %v", err)
+		if err := pm.incrProxyCounter(proxyURL, "success_count", 1); err != nil {
+			log.Printf("Failed to persist proxy success count: %v", err)
+		}
+		if err := pm.redis.HSet(ctx, proxyHashKey(proxyURL), "fail_count", 0).Err(); err != nil {
+			log.Printf("Failed to reset proxy fail count: %v", err)
+		}
+		if err := pm.saveProxyHealthFields(proxyURL, snapshot); err != nil {
+			log.Printf("Failed to save proxy health fields: %v", err)
+		}
+		if healthChanged {
+			pm.publishEvent(proxyEvent{Type: "health_change", URL: proxyURL, Proxy: &snapshot})
 		}
 	}()
 }
 
-// MarkProxyFailure marks a proxy as failed
-func (pm *ProxyManager) MarkProxyFailure(proxyURL string) {
+// MarkProxyFailure marks a proxy as failed. latencyMs <= 0 is treated the
+// same way MarkProxySuccess treats it: no EWMA update. Also decrements
+// InFlight, for the same reason MarkProxySuccess does.
+func (pm *ProxyManager) MarkProxyFailure(proxyURL string, latencyMs int) {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
+	var snapshot Proxy
+	var healthChanged bool
+	found := false
 	for _, proxy := range pm.proxies {
 		if proxy.URL == proxyURL {
+			wasHealthy := proxy.IsHealthy
 			proxy.FailCount++
-			if proxy.FailCount >= 3 {
-				proxy.IsHealthy = false
+			recordBreakerFailure(proxy, time.Now())
+			proxy.IsHealthy = proxy.Breaker.StateOrClosed() != BreakerOpen
+			if latencyMs > 0 {
+				updateLatencyEWMA(proxy, latencyMs)
 			}
+			if proxy.InFlight > 0 {
+				proxy.InFlight--
+			}
+			healthChanged = proxy.IsHealthy != wasHealthy
+			snapshot = *proxy
+			found = true
 			break
 		}
 	}
+	pm.mu.Unlock()
+
+	if !found {
+		return
+	}
+
+	proxyRequestsTotal.WithLabelValues(proxyURL, "failure").Inc()
+	if latencyMs > 0 {
+		proxyLatencyMs.WithLabelValues(proxyURL).Observe(float64(latencyMs))
+	}
+	logOutcome("failure", proxyURL, latencyMs)
+	if healthChanged {
+		pm.updatePoolMetrics()
+	}
 
-	// Save to Redis asynchronously
+	// Persist asynchronously, same split as MarkProxySuccess: fail_count
+	// via atomic HINCRBY, everything else as a plain HSET snapshot.
 	go func() {
-		if err := pm.SaveProxiesToRedis(); err != nil {
-			log.Printf("Failed to save proxies to Redis: %v", err)
+		if err := pm.incrProxyCounter(proxyURL, "fail_count", 1); err != nil {
+			log.Printf("Failed to persist proxy fail count: %v", err)
+		}
+		if err := pm.saveProxyHealthFields(proxyURL, snapshot); err != nil {
+			log.Printf("Failed to save proxy health fields: %v", err)
+		}
+		if healthChanged {
+			pm.publishEvent(proxyEvent{Type: "health_change", URL: proxyURL, Proxy: &snapshot})
 		}
 	}()
 }
 
-// CheckProxyHealth checks if a proxy is healthy
+// CheckProxyHealth actually routes a request through proxy (previously this
+// hit httpbin.org directly with a default client, so every proxy "passed"
+// regardless of whether it worked at all) and checks that the target
+// reports an IP other than pm.directIP, catching a proxy that's
+// transparently forwarding rather than masking the origin.
 func (pm *ProxyManager) CheckProxyHealth(proxy *Proxy) bool {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	transport, err := proxyTransport(proxy)
+	if err != nil {
+		log.Printf("Proxy %s health check failed: %v", proxy.URL, err)
+		return false
 	}
+	client := &http.Client{Transport: transport, Timeout: healthCheckTimeout}
+
+	target := pm.nextHealthCheckTarget()
 
 	start := time.Now()
-	resp, err := client.Get("https://httpbin.org/ip")
+	resp, err := client.Get(target)
+	latency := time.Since(start)
+	proxyHealthCheckLatency.WithLabelValues(proxy.URL, target, proxy.Protocol).Observe(latency.Seconds())
+
 	if err != nil {
-		log.Printf("Proxy %s health check failed: %v", proxy.URL, err)
+		log.Printf("Proxy %s health check against %s failed: %v", proxy.URL, target, err)
 		return false
 	}
 	defer func() {
@@ -226,10 +384,29 @@ func (pm *ProxyManager) CheckProxyHealth(proxy *Proxy) bool {
 		}
 	}()
 
-	latency := time.Since(start).Milliseconds()
-	proxy.AvgLatency = int(latency)
+	updateLatencyEWMA(proxy, int(latency.Milliseconds()))
+
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Proxy %s health check against %s: reading body: %v", proxy.URL, target, err)
+		return false
+	}
+
+	reportedIP := extractIP(body)
+	if reportedIP == "" {
+		log.Printf("Proxy %s health check against %s: no IP found in response", proxy.URL, target)
+		return false
+	}
+	if directIP := pm.getDirectIP(); directIP != "" && reportedIP == directIP {
+		log.Printf("Proxy %s health check against %s: reported IP %s matches direct IP, proxy is not masking origin", proxy.URL, target, reportedIP)
+		return false
+	}
 
-	return resp.StatusCode == 200
+	return true
 }
 
 // RunHealthChecks runs periodic health checks on all proxies
@@ -239,66 +416,125 @@ func (pm *ProxyManager) RunHealthChecks() {
 
 	for range ticker.C {
 		log.Println("Running proxy health checks...")
-		pm.mu.Lock()
-
-		for _, proxy := range pm.proxies {
+		pm.mu.RLock()
+		proxies := make([]*Proxy, len(pm.proxies))
+		copy(proxies, pm.proxies)
+		pm.mu.RUnlock()
+
+		var wg sync.WaitGroup
+		for _, proxy := range proxies {
+			wg.Add(1)
 			go func(p *Proxy) {
-				isHealthy := pm.CheckProxyHealth(p)
-				p.IsHealthy = isHealthy
-				if !isHealthy {
-					p.FailCount++
-				} else {
-					p.FailCount = 0
-				}
+				defer wg.Done()
+				pm.checkAndPersistHealth(p)
 			}(proxy)
 		}
-
-		pm.mu.Unlock()
-
-		// Save updated proxies to Redis
-		time.Sleep(2 * time.Second) // Wait for health checks to complete
-		if err := pm.SaveProxiesToRedis(); err != nil {
-			log.Printf("Failed to save proxies to Redis: %v", err)
-		}
+		wg.Wait()
 
 		log.Println("Health checks completed")
 	}
 }
 
-// AddProxy adds a new proxy
-func (pm *ProxyManager) AddProxy(proxy *Proxy) {
+// checkAndPersistHealth runs CheckProxyHealth against p, folds the outcome
+// into p's breaker/fail-count state under pm.mu, then persists the result
+// and — if IsHealthy flipped — publishes a "health_change" event the same
+// way MarkProxySuccess/MarkProxyFailure do, so a periodic health check
+// propagates to other replicas just as fast as a request-path report does.
+func (pm *ProxyManager) checkAndPersistHealth(p *Proxy) {
+	isHealthy := pm.CheckProxyHealth(p)
+	logHealthCheck(p.URL, isHealthy)
+
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	wasHealthy := p.IsHealthy
+	if !isHealthy {
+		p.FailCount++
+		recordBreakerFailure(p, time.Now())
+	} else {
+		p.FailCount = 0
+		recordBreakerSuccess(p)
+	}
+	p.IsHealthy = p.Breaker.StateOrClosed() != BreakerOpen
+	healthChanged := p.IsHealthy != wasHealthy
+	snapshot := *p
+	pm.mu.Unlock()
+
+	if isHealthy {
+		if err := pm.redis.HSet(ctx, proxyHashKey(p.URL), "fail_count", 0).Err(); err != nil {
+			log.Printf("Failed to reset proxy fail count: %v", err)
+		}
+	} else if err := pm.incrProxyCounter(p.URL, "fail_count", 1); err != nil {
+		log.Printf("Failed to persist proxy fail count: %v", err)
+	}
+	if err := pm.saveProxyHealthFields(p.URL, snapshot); err != nil {
+		log.Printf("Failed to save proxy health fields: %v", err)
+	}
+	if healthChanged {
+		pm.publishEvent(proxyEvent{Type: "health_change", URL: p.URL, Proxy: &snapshot})
+		pm.updatePoolMetrics()
+	}
+}
 
+// AddProxy adds a new proxy. Registering it in Redis (proxyListLockKey-
+// guarded) and publishing the "add" event to every other replica both
+// happen synchronously, so a caller that immediately queries another
+// replica still has a consistent read of what was just added; only the
+// risk of a slow Redis round-trip makes this block the HTTP handler. If
+// addProxyToIndex fails, proxy is removed from pm.proxies again rather than
+// left live in this replica's own rotation while invisible to every other
+// replica.
+func (pm *ProxyManager) AddProxy(proxy *Proxy) error {
+	pm.mu.Lock()
 	proxy.IsHealthy = true
 	proxy.LastUsed = time.Now()
 	pm.proxies = append(pm.proxies, proxy)
+	pm.mu.Unlock()
 
-	go func() {
-		if err := pm.SaveProxiesToRedis(); err != nil {
-			log.Printf("Failed to save proxies to Redis: %v", err)
+	if err := pm.addProxyToIndex(proxy); err != nil {
+		pm.mu.Lock()
+		for i, p := range pm.proxies {
+			if p == proxy {
+				pm.proxies = append(pm.proxies[:i], pm.proxies[i+1:]...)
+				break
+			}
 		}
-	}()
+		pm.mu.Unlock()
+		return err
+	}
+	pm.updatePoolMetrics()
+	return nil
 }
 
-// RemoveProxy removes a proxy by URL
-func (pm *ProxyManager) RemoveProxy(proxyURL string) bool {
+// RemoveProxy removes a proxy by URL, unregistering it from Redis and
+// publishing a "remove" event to every other replica. If
+// removeProxyFromIndex fails, the proxy is put back into pm.proxies rather
+// than leaving it reachable in Redis/other replicas but gone from this
+// replica's own rotation.
+func (pm *ProxyManager) RemoveProxy(proxyURL string) (bool, error) {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
+	var removed *Proxy
 	for i, proxy := range pm.proxies {
 		if proxy.URL == proxyURL {
+			removed = proxy
 			pm.proxies = append(pm.proxies[:i], pm.proxies[i+1:]...)
-			go func() {
-				if err := pm.SaveProxiesToRedis(); err != nil {
-					log.Printf("Failed to save proxies to Redis: %v", err)
-				}
-			}()
-			return true
+			break
 		}
 	}
+	found := removed != nil
+	pm.mu.Unlock()
 
-	return false
+	if !found {
+		return false, nil
+	}
+	if err := pm.removeProxyFromIndex(proxyURL); err != nil {
+		pm.mu.Lock()
+		pm.proxies = append(pm.proxies, removed)
+		pm.mu.Unlock()
+		return true, err
+	}
+	removeProxyHealthMetrics(proxyURL)
+	removeProxyRequestMetrics(proxyURL)
+	pm.updatePoolMetrics()
+	return true, nil
 }
 
 // GetAllProxies returns all proxies
@@ -367,6 +603,44 @@ func main() {
 	// Initialize proxy manager
 	manager = NewProxyManager(redisClient)
 
+	if raw := os.Getenv("TX_MAX_ATTEMPTS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			manager.txMaxAttempts = n
+		} else {
+			log.Printf("Warning: invalid TX_MAX_ATTEMPTS %q, using default %d", raw, manager.txMaxAttempts)
+		}
+	}
+	if raw := os.Getenv("TX_RETRY_BASE_DELAY_MS"); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms > 0 {
+			manager.txRetryBaseDelay = time.Duration(ms) * time.Millisecond
+		} else {
+			log.Printf("Warning: invalid TX_RETRY_BASE_DELAY_MS %q, using default %s", raw, manager.txRetryBaseDelay)
+		}
+	}
+
+	manager.targets = healthCheckTargets()
+
+	// Set up the structured proxy event log (selections, outcomes, breaker
+	// transitions, health checks). A failure here is logged, not fatal: the
+	// service runs fine without it, just without post-hoc reconstruction.
+	eventLogPath := os.Getenv("EVENT_LOG_PATH")
+	if eventLogPath == "" {
+		eventLogPath = "proxy-events.log"
+	}
+	eventLogMaxSize := int64(defaultEventLogMaxSize)
+	if raw := os.Getenv("EVENT_LOG_MAX_SIZE_MB"); raw != "" {
+		if mb, err := strconv.ParseInt(raw, 10, 64); err == nil && mb > 0 {
+			eventLogMaxSize = mb * 1024 * 1024
+		} else {
+			log.Printf("Warning: invalid EVENT_LOG_MAX_SIZE_MB %q, using default", raw)
+		}
+	}
+	if l, err := NewProxyEventLog(eventLogPath, eventLogMaxSize); err != nil {
+		log.Printf("Warning: failed to open proxy event log: %v", err)
+	} else {
+		eventLog = l
+	}
+
 	// Load proxies
 	if err := manager.LoadProxiesFromRedis(); err != nil {
 		log.Printf("Warning: Failed to load proxies from Redis: %v", err)
@@ -376,9 +650,23 @@ func main() {
 		log.Fatalf("Failed to load proxies from environment: %v", err)
 	}
 
+	if err := manager.LoadRulesFromRedis(); err != nil {
+		log.Printf("Warning: Failed to load routing rules from Redis: %v", err)
+	}
+
+	// Determine (and keep refreshing) this process's direct egress IP in
+	// the background, so a slow/unreachable target set can't delay
+	// app.Listen below and trip a container orchestrator's startup probe.
+	go manager.RunDirectIPRefresh()
+
 	// Start health checks in background
 	go manager.RunHealthChecks()
 
+	// Subscribe to other replicas' add/remove/health-state-change events so
+	// this replica's in-memory cache updates immediately instead of only on
+	// the next LoadProxiesFromRedis.
+	go manager.watchEvents(context.Background())
+
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:               "Proxy Manager Service",
@@ -401,9 +689,57 @@ func main() {
 		})
 	})
 
-	// Get next proxy
+	// Prometheus metrics: proxy_pool_size, proxy_country_pool_size,
+	// proxy_requests_total, proxy_latency_ms, proxy_selection_duration_seconds
+	// (metrics.go), and proxy_health_check_latency_seconds (healthcheck.go).
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	// Get next proxy. ?strategy=round_robin|weighted_latency|least_connections|random
 	app.Get("/api/v1/proxy/next", func(c *fiber.Ctx) error {
-		proxy, err := manager.GetNextProxy()
+		strategy := c.Query("strategy")
+		proxy, err := manager.GetNextProxy(strategy)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(proxy)
+	})
+
+	// Release a proxy acquired via /api/v1/proxy/next, so LeastConnections
+	// sees it as free again.
+	app.Post("/api/v1/proxy/release", func(c *fiber.Ctx) error {
+		var req struct {
+			ProxyURL string `json:"proxy_url"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if manager.ReleaseProxy(req.ProxyURL) {
+			return c.JSON(fiber.Map{"message": "Proxy released"})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Proxy not found",
+		})
+	})
+
+	// Get a proxy suitable for a target URL, per the first matching
+	// RoutingRule (see routing.go). Falls back to GetNextProxy's plain
+	// selection when no rule applies.
+	app.Post("/api/v1/proxy/for-url", func(c *fiber.Ctx) error {
+		var req struct {
+			URL string `json:"url"`
+		}
+		if err := c.BodyParser(&req); err != nil || req.URL == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		proxy, err := manager.SelectProxyForURL(req.URL, c.Query("strategy"))
 		if err != nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
 				"error": err.Error(),
@@ -412,6 +748,54 @@ func main() {
 		return c.JSON(proxy)
 	})
 
+	// List routing rules
+	app.Get("/api/v1/proxy/rules", func(c *fiber.Ctx) error {
+		return c.JSON(manager.GetRules())
+	})
+
+	// Create a routing rule
+	app.Post("/api/v1/proxy/rules", func(c *fiber.Ctx) error {
+		var rule RoutingRule
+		if err := c.BodyParser(&rule); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		if err := manager.AddRule(rule); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusCreated).JSON(rule)
+	})
+
+	// Delete a routing rule by pattern+match
+	app.Delete("/api/v1/proxy/rules", func(c *fiber.Ctx) error {
+		var req struct {
+			Pattern string    `json:"pattern"`
+			Match   MatchKind `json:"match"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+
+		found, err := manager.DeleteRule(req.Pattern, req.Match)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if found {
+			return c.JSON(fiber.Map{"message": "Rule deleted"})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Rule not found",
+		})
+	})
+
 	// Get proxy by country
 	app.Get("/api/v1/proxy/country/:country", func(c *fiber.Ctx) error {
 		country := c.Params("country")
@@ -430,6 +814,24 @@ func main() {
 		return c.JSON(proxies)
 	})
 
+	// Get a proxy's circuit breaker state
+	app.Get("/api/v1/proxies/:url/breaker", func(c *fiber.Ctx) error {
+		proxyURL := c.Params("url")
+		breaker, untilProbe, err := manager.GetBreakerState(proxyURL)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"state":               breaker.StateOrClosed(),
+			"trips":               breaker.Trips,
+			"opened_at":           breaker.OpenedAt,
+			"next_probe_at":       breaker.NextProbeAt,
+			"time_until_probe_ms": untilProbe.Milliseconds(),
+		})
+	})
+
 	// Get proxy stats
 	app.Get("/api/v1/proxies/stats", func(c *fiber.Ctx) error {
 		stats := manager.GetStats()
@@ -445,14 +847,24 @@ func main() {
 			})
 		}
 
-		manager.AddProxy(&proxy)
+		if err := manager.AddProxy(&proxy); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusCreated).JSON(proxy)
 	})
 
 	// Remove proxy
 	app.Delete("/api/v1/proxies/:url", func(c *fiber.Ctx) error {
 		proxyURL := c.Params("url")
-		if manager.RemoveProxy(proxyURL) {
+		found, err := manager.RemoveProxy(proxyURL)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if found {
 			return c.JSON(fiber.Map{"message": "Proxy removed"})
 		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -460,10 +872,12 @@ func main() {
 		})
 	})
 
-	// Mark proxy success
+	// Mark proxy success. latency_ms is optional and, when given, folds
+	// into the proxy's AvgLatency EWMA.
 	app.Post("/api/v1/proxy/success", func(c *fiber.Ctx) error {
 		var req struct {
-			ProxyURL string `json:"proxy_url"`
+			ProxyURL  string `json:"proxy_url"`
+			LatencyMs int    `json:"latency_ms"`
 		}
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -471,14 +885,15 @@ func main() {
 			})
 		}
 
-		manager.MarkProxySuccess(req.ProxyURL)
+		manager.MarkProxySuccess(req.ProxyURL, req.LatencyMs)
 		return c.JSON(fiber.Map{"message": "Proxy marked as successful"})
 	})
 
-	// Mark proxy failure
+	// Mark proxy failure. latency_ms is optional, same as /proxy/success.
 	app.Post("/api/v1/proxy/failure", func(c *fiber.Ctx) error {
 		var req struct {
-			ProxyURL string `json:"proxy_url"`
+			ProxyURL  string `json:"proxy_url"`
+			LatencyMs int    `json:"latency_ms"`
 		}
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -486,7 +901,7 @@ func main() {
 			})
 		}
 
-		manager.MarkProxyFailure(req.ProxyURL)
+		manager.MarkProxyFailure(req.ProxyURL, req.LatencyMs)
 		return c.JSON(fiber.Map{"message": "Proxy marked as failed"})
 	})
 
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// proxyPoolSize tracks how many proxies are in each state, refreshed by
+// updatePoolMetrics whenever pool composition or a proxy's health/breaker
+// state changes.
+var proxyPoolSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "proxy_pool_size",
+	Help: "Number of proxies currently in each state (healthy, unhealthy, open).",
+}, []string{"state"})
+
+// proxyCountryPoolSize tracks how many proxies are configured per country,
+// refreshed alongside proxyPoolSize.
+var proxyCountryPoolSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "proxy_country_pool_size",
+	Help: "Number of proxies configured per country.",
+}, []string{"country"})
+
+// proxyRequestsTotal counts every outcome MarkProxySuccess/MarkProxyFailure
+// reports, per proxy.
+var proxyRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "proxy_requests_total",
+	Help: "Count of reported request outcomes, per proxy and outcome.",
+}, []string{"proxy", "outcome"})
+
+// proxyLatencyMs observes the latency MarkProxySuccess/MarkProxyFailure
+// callers report, per proxy. Distinct from proxy_health_check_latency_seconds
+// (healthcheck.go), which only covers this process's own periodic checks.
+var proxyLatencyMs = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "proxy_latency_ms",
+	Help:    "Reported request latency in milliseconds, per proxy.",
+	Buckets: []float64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+}, []string{"proxy"})
+
+// proxySelectionDuration times GetNextProxy/SelectProxyForURL, so lock
+// contention or a growing candidate list shows up here before it's visible
+// as added request latency downstream.
+var proxySelectionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "proxy_selection_duration_seconds",
+	Help:    "Time taken to select a proxy via GetNextProxy or SelectProxyForURL.",
+	Buckets: prometheus.DefBuckets,
+})
+
+// proxyPoolState classifies p into the state label updatePoolMetrics
+// tracks. An Open breaker always wins over IsHealthy: a proxy that's
+// tripped shouldn't also count as merely "unhealthy".
+func proxyPoolState(p *Proxy) string {
+	if p.Breaker.StateOrClosed() == BreakerOpen {
+		return "open"
+	}
+	if p.IsHealthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// updatePoolMetrics recomputes proxyPoolSize and proxyCountryPoolSize from
+// pm's current proxies. Call it whenever pool composition or a proxy's
+// health/breaker state changes.
+func (pm *ProxyManager) updatePoolMetrics() {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	counts := map[string]int{"healthy": 0, "unhealthy": 0, "open": 0}
+	countryCounts := make(map[string]int)
+	for _, p := range pm.proxies {
+		counts[proxyPoolState(p)]++
+		if p.Country != "" {
+			countryCounts[p.Country]++
+		}
+	}
+	for state, n := range counts {
+		proxyPoolSize.WithLabelValues(state).Set(float64(n))
+	}
+
+	// Reset first: a country whose last proxy just left the pool should
+	// read zero, not keep reporting its last nonzero count forever.
+	proxyCountryPoolSize.Reset()
+	for country, n := range countryCounts {
+		proxyCountryPoolSize.WithLabelValues(country).Set(float64(n))
+	}
+}
+
+// removeProxyRequestMetrics drops every proxy_requests_total/proxy_latency_ms
+// series recorded for proxyURL, the same cardinality cleanup
+// removeProxyHealthMetrics (healthcheck.go) gives the health-check latency
+// histogram.
+func removeProxyRequestMetrics(proxyURL string) {
+	proxyRequestsTotal.DeletePartialMatch(prometheus.Labels{"proxy": proxyURL})
+	proxyLatencyMs.DeletePartialMatch(prometheus.Labels{"proxy": proxyURL})
+}
+
+// observeSelectionDuration times fn, a proxy-selection call, and records it
+// under proxySelectionDuration regardless of whether it succeeded.
+func observeSelectionDuration(fn func() (*Proxy, error)) (*Proxy, error) {
+	start := time.Now()
+	proxy, err := fn()
+	proxySelectionDuration.Observe(time.Since(start).Seconds())
+	return proxy, err
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// proxiesIndexKey is a Redis set of every known proxy URL — the source of
+// truth for which proxy:{url} hashes exist. It replaces the old monolithic
+// proxies:list JSON blob: a counter bump on one proxy no longer requires
+// rewriting (and risks clobbering a concurrent write to) every other
+// proxy's state.
+const proxiesIndexKey = "proxies:index"
+
+// proxyListLockKey guards structural changes to proxiesIndexKey (adding or
+// removing a proxy) across replicas. Per-proxy counter updates don't need
+// it — HINCRBY on a single hash field is already atomic — only changing
+// which proxies exist is a read-modify-write that needs mutual exclusion.
+const proxyListLockKey = "proxies:lock:index"
+
+// proxyEventsChannel is the pub/sub channel add/remove/health-state-change
+// events are published on, so every replica's in-memory cache updates
+// instantly instead of waiting for the next periodic reload.
+const proxyEventsChannel = "proxies:events"
+
+// defaultLockTTL bounds how long a replica can hold proxyListLockKey before
+// Redis expires it on its own, so a replica that dies mid-update can't wedge
+// every other replica out of AddProxy/RemoveProxy forever.
+const defaultLockTTL = 5 * time.Second
+
+// proxyHashKey is the per-proxy hash proxyURL's fields (counters, latency,
+// breaker state, ...) are stored under.
+func proxyHashKey(proxyURL string) string {
+	return "proxy:" + proxyURL
+}
+
+// proxyEvent is published on proxyEventsChannel and consumed by every
+// replica's watchEvents loop to keep its in-memory cache current.
+type proxyEvent struct {
+	Type  string `json:"type"` // "add", "remove", "health_change", or "rules_changed"
+	URL   string `json:"url"`
+	Proxy *Proxy `json:"proxy,omitempty"`
+}
+
+// releaseLockScript deletes KEYS[1] only if it still holds ARGV[1], so a
+// release firing after this lock's TTL already expired — and another
+// replica has since acquired it — doesn't delete that replica's lock out
+// from under it.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end
+`)
+
+// acquireLock takes a SETNX-based distributed lock on key, retrying up to
+// pm.txMaxAttempts times with exponential backoff (pm.txRetryBaseDelay,
+// doubling each attempt, plus jitter) while it's held by another replica.
+// The returned release func is a no-op once the lock's TTL has already
+// passed to someone else.
+func (pm *ProxyManager) acquireLock(lockCtx context.Context, key string, ttl time.Duration) (release func(), err error) {
+	token := strconv.FormatInt(rand.Int63(), 36)
+
+	delay := pm.txRetryBaseDelay
+	for attempt := 0; attempt < pm.txMaxAttempts; attempt++ {
+		ok, err := pm.redis.SetNX(lockCtx, key, token, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquiring lock %s: %w", key, err)
+		}
+		if ok {
+			return func() { pm.releaseLock(key, token) }, nil
+		}
+
+		if attempt < pm.txMaxAttempts-1 {
+			jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+			time.Sleep(delay + jitter)
+			delay *= 2
+		}
+	}
+	return nil, fmt.Errorf("acquiring lock %s: exhausted %d attempts", key, pm.txMaxAttempts)
+}
+
+// releaseLock runs releaseLockScript to drop key, logging rather than
+// returning an error since callers invoke this via defer.
+func (pm *ProxyManager) releaseLock(key, token string) {
+	if err := releaseLockScript.Run(ctx, pm.redis, []string{key}, token).Err(); err != nil {
+		log.Printf("releasing lock %s: %v", key, err)
+	}
+}
+
+// proxyHashFields flattens proxy into the field map saveProxyHash writes
+// with HSET. Breaker is nested and rarely read directly by anything other
+// than Go, so it's carried as a single JSON-encoded field rather than
+// flattened into more hash fields of its own.
+func proxyHashFields(proxy *Proxy) (map[string]interface{}, error) {
+	breakerJSON, err := json.Marshal(proxy.Breaker)
+	if err != nil {
+		return nil, fmt.Errorf("encoding breaker state for %s: %w", proxy.URL, err)
+	}
+	tagsJSON, err := json.Marshal(proxy.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("encoding tags for %s: %w", proxy.URL, err)
+	}
+
+	return map[string]interface{}{
+		"url":             proxy.URL,
+		"host":            proxy.Host,
+		"protocol":        proxy.Protocol,
+		"username":        proxy.Username,
+		"password":        proxy.Password,
+		"country":         proxy.Country,
+		"port":            proxy.Port,
+		"fail_count":      proxy.FailCount,
+		"success_count":   proxy.SuccessCount,
+		"avg_latency_ms":  proxy.AvgLatency,
+		"latency_samples": proxy.LatencySamples,
+		"is_healthy":      proxy.IsHealthy,
+		"last_used":       proxy.LastUsed.Format(time.RFC3339Nano),
+		"breaker_json":    string(breakerJSON),
+		"tags_json":       string(tagsJSON),
+	}, nil
+}
+
+// proxyFromHash decodes the field map HGetAll returns back into a Proxy.
+// InFlight is intentionally left at zero: it's only meaningful relative to
+// requests this process itself handed out, never what another replica's
+// hash last recorded.
+func proxyFromHash(fields map[string]string) (*Proxy, error) {
+	p := &Proxy{
+		URL:      fields["url"],
+		Host:     fields["host"],
+		Protocol: fields["protocol"],
+		Username: fields["username"],
+		Password: fields["password"],
+		Country:  fields["country"],
+	}
+	p.Port, _ = strconv.Atoi(fields["port"])
+	p.FailCount, _ = strconv.Atoi(fields["fail_count"])
+	p.SuccessCount, _ = strconv.Atoi(fields["success_count"])
+	p.AvgLatency, _ = strconv.Atoi(fields["avg_latency_ms"])
+	p.LatencySamples, _ = strconv.Atoi(fields["latency_samples"])
+	p.IsHealthy, _ = strconv.ParseBool(fields["is_healthy"])
+	if lastUsed, err := time.Parse(time.RFC3339Nano, fields["last_used"]); err == nil {
+		p.LastUsed = lastUsed
+	}
+	if breakerJSON := fields["breaker_json"]; breakerJSON != "" {
+		if err := json.Unmarshal([]byte(breakerJSON), &p.Breaker); err != nil {
+			return nil, fmt.Errorf("decoding breaker state for %s: %w", p.URL, err)
+		}
+	}
+	if tagsJSON := fields["tags_json"]; tagsJSON != "" {
+		if err := json.Unmarshal([]byte(tagsJSON), &p.Tags); err != nil {
+			return nil, fmt.Errorf("decoding tags for %s: %w", p.URL, err)
+		}
+	}
+	return p, nil
+}
+
+// saveProxyHash HSETs every field of proxy into proxy:{url}, overwriting
+// whatever was there. Individual HSET calls are already atomic, so this
+// needs no lock of its own — only structural changes to proxiesIndexKey
+// (who exists at all) need acquireLock.
+func (pm *ProxyManager) saveProxyHash(proxy *Proxy) error {
+	fields, err := proxyHashFields(proxy)
+	if err != nil {
+		return err
+	}
+	if err := pm.redis.HSet(ctx, proxyHashKey(proxy.URL), fields).Err(); err != nil {
+		return fmt.Errorf("saving proxy hash %s: %w", proxy.URL, err)
+	}
+	return nil
+}
+
+// saveProxyHealthFields HSETs everything about snapshot except its success
+// and fail counters, which MarkProxySuccess/MarkProxyFailure persist
+// separately via incrProxyCounter/a plain reset so a HINCRBY from one
+// replica can never be clobbered by a concurrent HSET of another replica's
+// stale locally-cached count.
+func (pm *ProxyManager) saveProxyHealthFields(proxyURL string, snapshot Proxy) error {
+	breakerJSON, err := json.Marshal(snapshot.Breaker)
+	if err != nil {
+		return fmt.Errorf("encoding breaker state for %s: %w", proxyURL, err)
+	}
+
+	fields := map[string]interface{}{
+		"avg_latency_ms":  snapshot.AvgLatency,
+		"latency_samples": snapshot.LatencySamples,
+		"is_healthy":      snapshot.IsHealthy,
+		"last_used":       snapshot.LastUsed.Format(time.RFC3339Nano),
+		"breaker_json":    string(breakerJSON),
+	}
+	if err := pm.redis.HSet(ctx, proxyHashKey(proxyURL), fields).Err(); err != nil {
+		return fmt.Errorf("saving proxy health fields %s: %w", proxyURL, err)
+	}
+	return nil
+}
+
+// incrProxyCounter atomically bumps one of proxy:{url}'s counter fields via
+// HINCRBY, so two replicas reporting an outcome for the same proxy at the
+// same time both land instead of one clobbering the other the way a
+// load-modify-store over the old JSON blob could.
+func (pm *ProxyManager) incrProxyCounter(proxyURL, field string, delta int64) error {
+	if err := pm.redis.HIncrBy(ctx, proxyHashKey(proxyURL), field, delta).Err(); err != nil {
+		return fmt.Errorf("incrementing %s on %s: %w", field, proxyURL, err)
+	}
+	return nil
+}
+
+// loadProxiesFromIndex reads every URL in proxiesIndexKey and HGetAlls its
+// hash, skipping (with a log) any URL whose hash is missing or malformed
+// rather than failing the whole load.
+func (pm *ProxyManager) loadProxiesFromIndex() ([]*Proxy, error) {
+	urls, err := pm.redis.SMembers(ctx, proxiesIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading proxies index: %w", err)
+	}
+
+	proxies := make([]*Proxy, 0, len(urls))
+	for _, url := range urls {
+		fields, err := pm.redis.HGetAll(ctx, proxyHashKey(url)).Result()
+		if err != nil {
+			log.Printf("reading proxy hash %s: %v", url, err)
+			continue
+		}
+		if len(fields) == 0 {
+			log.Printf("proxy %s is indexed but has no hash, skipping", url)
+			continue
+		}
+
+		proxy, err := proxyFromHash(fields)
+		if err != nil {
+			log.Printf("decoding proxy hash %s: %v", url, err)
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// addProxyToIndex registers proxy in Redis: it takes proxyListLockKey,
+// SAdds its URL to proxiesIndexKey, writes its hash, then publishes an
+// "add" event so every replica's in-memory cache picks it up immediately.
+func (pm *ProxyManager) addProxyToIndex(proxy *Proxy) error {
+	release, err := pm.acquireLock(ctx, proxyListLockKey, defaultLockTTL)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := pm.redis.SAdd(ctx, proxiesIndexKey, proxy.URL).Err(); err != nil {
+		return fmt.Errorf("adding %s to proxies index: %w", proxy.URL, err)
+	}
+	if err := pm.saveProxyHash(proxy); err != nil {
+		return err
+	}
+
+	pm.publishEvent(proxyEvent{Type: "add", URL: proxy.URL, Proxy: proxy})
+	return nil
+}
+
+// removeProxyFromIndex unregisters proxyURL from Redis: it takes
+// proxyListLockKey, SREMs it from proxiesIndexKey, deletes its hash, then
+// publishes a "remove" event.
+func (pm *ProxyManager) removeProxyFromIndex(proxyURL string) error {
+	release, err := pm.acquireLock(ctx, proxyListLockKey, defaultLockTTL)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := pm.redis.SRem(ctx, proxiesIndexKey, proxyURL).Err(); err != nil {
+		return fmt.Errorf("removing %s from proxies index: %w", proxyURL, err)
+	}
+	if err := pm.redis.Del(ctx, proxyHashKey(proxyURL)).Err(); err != nil {
+		return fmt.Errorf("deleting proxy hash %s: %w", proxyURL, err)
+	}
+
+	pm.publishEvent(proxyEvent{Type: "remove", URL: proxyURL})
+	return nil
+}
+
+// publishEvent JSON-encodes event and publishes it to proxyEventsChannel,
+// logging (rather than returning) any error since every caller treats
+// propagation as best-effort — a missed event just means this replica's
+// peers fall back to their next periodic reload.
+func (pm *ProxyManager) publishEvent(event proxyEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("encoding proxy event %s %s: %v", event.Type, event.URL, err)
+		return
+	}
+	if err := pm.redis.Publish(ctx, proxyEventsChannel, body).Err(); err != nil {
+		log.Printf("publishing proxy event %s %s: %v", event.Type, event.URL, err)
+	}
+}
+
+// watchEvents subscribes to proxyEventsChannel and applies every event to
+// pm's in-memory cache until subCtx is canceled, so add/remove/health
+// changes on other replicas propagate here well before the next periodic
+// LoadProxiesFromRedis. It's meant to run in its own goroutine for the
+// life of the process.
+func (pm *ProxyManager) watchEvents(subCtx context.Context) {
+	sub := pm.redis.Subscribe(subCtx, proxyEventsChannel)
+	defer func() {
+		if err := sub.Close(); err != nil {
+			log.Printf("closing proxy events subscription: %v", err)
+		}
+	}()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-subCtx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			pm.applyEvent(msg.Payload)
+		}
+	}
+}
+
+// applyEvent decodes one pub/sub message and folds it into pm.proxies.
+func (pm *ProxyManager) applyEvent(payload string) {
+	var event proxyEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("decoding proxy event: %v", err)
+		return
+	}
+
+	if event.Type == "rules_changed" {
+		// LoadRulesFromRedis takes pm.mu itself, so it must run outside the
+		// lock this function's other event types hold below.
+		if err := pm.LoadRulesFromRedis(); err != nil {
+			log.Printf("reloading routing rules after rules_changed event: %v", err)
+		}
+		return
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	switch event.Type {
+	case "remove":
+		for i, p := range pm.proxies {
+			if p.URL == event.URL {
+				pm.proxies = append(pm.proxies[:i], pm.proxies[i+1:]...)
+				break
+			}
+		}
+	case "add", "health_change":
+		if event.Proxy == nil {
+			return
+		}
+		for _, p := range pm.proxies {
+			if p.URL == event.URL {
+				// probing and InFlight are this replica's own in-process
+				// bookkeeping (see CircuitBreaker.probing's doc comment) —
+				// never overwrite them with whatever another replica's
+				// snapshot happened to carry, or a probe this replica has
+				// outstanding could get silently reset to available.
+				probing := p.Breaker.probing
+				inFlight := p.InFlight
+				*p = *event.Proxy
+				p.Breaker.probing = probing
+				p.InFlight = inFlight
+				return
+			}
+		}
+		event.Proxy.InFlight = 0
+		pm.proxies = append(pm.proxies, event.Proxy)
+	}
+}
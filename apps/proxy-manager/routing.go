@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rulesRedisKey stores the JSON-encoded RoutingRule list. Rules change far
+// less often, and in far smaller volume, than per-proxy counters, so unlike
+// proxies:list (see redis_store.go) there's no need to split this into
+// per-rule hashes — a single blob with a lock around writes is enough.
+const rulesRedisKey = "proxies:rules"
+
+// rulesLockKey guards read-modify-write updates to the rules list across
+// replicas, the same distributed-lock pattern proxyListLockKey uses for the
+// proxies index.
+const rulesLockKey = "proxies:lock:rules"
+
+// MatchKind is how a RoutingRule's Pattern is compared against a target
+// URL's host.
+type MatchKind string
+
+const (
+	MatchHost   MatchKind = "host"
+	MatchRegex  MatchKind = "regex"
+	MatchSuffix MatchKind = "suffix"
+)
+
+// RoutingRule scopes SelectProxyForURL's candidate proxies for URLs whose
+// host matches Pattern (per Match). Rules are checked in order; the first
+// one that matches a given URL applies.
+type RoutingRule struct {
+	Pattern string    `json:"pattern"`
+	Match   MatchKind `json:"match"`
+
+	// RequireCountry, RequireTags, and RequireProtocol narrow the
+	// candidate set down to proxies satisfying all three (empty means
+	// "no constraint").
+	RequireCountry  string   `json:"require_country,omitempty"`
+	RequireTags     []string `json:"require_tags,omitempty"`
+	RequireProtocol string   `json:"require_protocol,omitempty"`
+
+	// StickySession pins a host to the one proxy SelectProxyForURL first
+	// picks for it, reusing that proxy for every later request to the same
+	// host until it goes unhealthy. Essential for sessions that need
+	// cookie/egress-IP continuity (e.g. a Workday application flow).
+	StickySession bool `json:"sticky_session,omitempty"`
+
+	// compiledRegex caches MatchRegex's compiled Pattern so a rule checked
+	// against every SelectProxyForURL call isn't re-parsed on each one.
+	// compileRule fills this in when a rule is added or loaded; it's
+	// unexported and never persisted, so Matches falls back to compiling
+	// on the spot if it's unset.
+	compiledRegex *regexp.Regexp
+}
+
+// compileRule validates rule.Match and, for MatchRegex, precompiles
+// rule.Pattern into rule.compiledRegex. Call it on every rule accepted from
+// outside the process (AddRule, LoadRulesFromRedis) so a typo'd match kind
+// or an invalid regex is rejected up front instead of surfacing as a
+// per-request error out of Matches.
+func compileRule(rule *RoutingRule) error {
+	switch rule.Match {
+	case MatchRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("compiling regex rule %q: %w", rule.Pattern, err)
+		}
+		rule.compiledRegex = re
+	case MatchHost, MatchSuffix, "":
+		// No precompilation needed.
+	default:
+		return fmt.Errorf("unknown match kind %q", rule.Match)
+	}
+	return nil
+}
+
+// Matches reports whether targetURL's host satisfies rule, per rule.Match.
+// MatchRegex matches against the full targetURL instead of just the host,
+// so a rule can also scope by path (e.g. "linkedin\\.com/jobs/").
+func (rule RoutingRule) Matches(targetURL string) (bool, error) {
+	switch rule.Match {
+	case MatchRegex:
+		re := rule.compiledRegex
+		if re == nil {
+			var err error
+			re, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				return false, fmt.Errorf("compiling regex rule %q: %w", rule.Pattern, err)
+			}
+		}
+		return re.MatchString(targetURL), nil
+
+	case MatchSuffix:
+		host, err := urlHost(targetURL)
+		if err != nil {
+			return false, err
+		}
+		pattern := strings.ToLower(rule.Pattern)
+		return host == pattern || strings.HasSuffix(host, "."+pattern), nil
+
+	case MatchHost, "":
+		host, err := urlHost(targetURL)
+		if err != nil {
+			return false, err
+		}
+		return host == strings.ToLower(rule.Pattern), nil
+
+	default:
+		return false, fmt.Errorf("unknown match kind %q", rule.Match)
+	}
+}
+
+// urlHost returns the lowercased host component of rawURL.
+func urlHost(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing URL %q: %w", rawURL, err)
+	}
+	return strings.ToLower(u.Hostname()), nil
+}
+
+// matchesRuleConstraints reports whether proxy satisfies rule's
+// country/tags/protocol requirements (not its Pattern/Match, which is
+// checked separately against the target URL by Matches).
+func matchesRuleConstraints(proxy *Proxy, rule RoutingRule) bool {
+	if rule.RequireCountry != "" && proxy.Country != rule.RequireCountry {
+		return false
+	}
+	if rule.RequireProtocol != "" && proxy.Protocol != rule.RequireProtocol {
+		return false
+	}
+	for _, tag := range rule.RequireTags {
+		if !hasTag(proxy.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectProxyForURL returns a proxy suitable for targetURL: it finds the
+// first rule (in pm.rules order) whose Matches accepts targetURL, filters
+// healthy proxies down to those satisfying that rule's constraints, and
+// picks one via strategyName (see strategyByName). A StickySession rule
+// instead reuses whatever proxy it last pinned to targetURL's host, as long
+// as that proxy is still healthy. A targetURL that no rule matches falls
+// back to GetNextProxy's plain, unfiltered selection.
+func (pm *ProxyManager) SelectProxyForURL(targetURL, strategyName string) (*Proxy, error) {
+	host, err := urlHost(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return observeSelectionDuration(func() (*Proxy, error) {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+
+		var matched *RoutingRule
+		for i := range pm.rules {
+			ok, err := pm.rules[i].Matches(targetURL)
+			if err != nil {
+				// A malformed rule shouldn't take down every request that
+				// reaches it in rule order — skip it and keep checking the
+				// rest, the same way a bad proxy hash is skipped rather than
+				// failing the whole load in loadProxiesFromIndex.
+				log.Printf("evaluating routing rule %q (%s): %v", pm.rules[i].Pattern, pm.rules[i].Match, err)
+				continue
+			}
+			if ok {
+				matched = &pm.rules[i]
+				break
+			}
+		}
+
+		if matched == nil {
+			return pm.selectProxyLocked("", time.Now())
+		}
+
+		if matched.StickySession {
+			if pinnedURL, ok := pm.stickySessions[host]; ok {
+				for _, proxy := range pm.proxies {
+					if proxy.URL == pinnedURL && breakerAllows(proxy, time.Now()) {
+						claimProbe(proxy)
+						proxy.LastUsed = time.Now()
+						proxy.InFlight++
+						logSelection(proxy, strategyName)
+						return proxy, nil
+					}
+				}
+				// Pinned proxy is gone or unhealthy; fall through and pick
+				// (and pin) a new one.
+				delete(pm.stickySessions, host)
+			}
+		}
+
+		candidates := make([]*Proxy, 0, len(pm.proxies))
+		nowTime := time.Now()
+		for _, proxy := range pm.proxies {
+			if breakerAllows(proxy, nowTime) && matchesRuleConstraints(proxy, *matched) {
+				candidates = append(candidates, proxy)
+			}
+		}
+
+		proxy, err := strategyByName(strategyName).Select(candidates)
+		if err != nil {
+			return nil, fmt.Errorf("no proxy available for rule matching %q: %w", targetURL, err)
+		}
+
+		claimProbe(proxy)
+		proxy.LastUsed = nowTime
+		proxy.InFlight++
+
+		if matched.StickySession {
+			pm.stickySessions[host] = proxy.URL
+		}
+		logSelection(proxy, strategyName)
+		return proxy, nil
+	})
+}
+
+// selectProxyLocked is GetNextProxy's body, reusable by SelectProxyForURL
+// once it already holds pm.mu (GetNextProxy itself takes the lock, so it
+// can't be called reentrantly).
+func (pm *ProxyManager) selectProxyLocked(strategyName string, nowTime time.Time) (*Proxy, error) {
+	if len(pm.proxies) == 0 {
+		return nil, fmt.Errorf("no proxies available")
+	}
+
+	healthy := make([]*Proxy, 0, len(pm.proxies))
+	for _, proxy := range pm.proxies {
+		if breakerAllows(proxy, nowTime) {
+			healthy = append(healthy, proxy)
+		}
+	}
+
+	proxy, err := strategyByName(strategyName).Select(healthy)
+	if err != nil {
+		return nil, err
+	}
+
+	claimProbe(proxy)
+	proxy.LastUsed = nowTime
+	proxy.InFlight++
+	logSelection(proxy, strategyName)
+	return proxy, nil
+}
+
+// GetRules returns a copy of pm's current routing rules.
+func (pm *ProxyManager) GetRules() []RoutingRule {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	rules := make([]RoutingRule, len(pm.rules))
+	copy(rules, pm.rules)
+	return rules
+}
+
+// AddRule appends rule to pm's routing rules and persists the new list to
+// Redis under rulesLockKey, then notifies every other replica to reload.
+func (pm *ProxyManager) AddRule(rule RoutingRule) error {
+	if err := compileRule(&rule); err != nil {
+		return fmt.Errorf("invalid routing rule: %w", err)
+	}
+
+	release, err := pm.acquireLock(ctx, rulesLockKey, defaultLockTTL)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	pm.mu.Lock()
+	pm.rules = append(pm.rules, rule)
+	rules := make([]RoutingRule, len(pm.rules))
+	copy(rules, pm.rules)
+	pm.mu.Unlock()
+
+	if err := pm.saveRulesToRedis(rules); err != nil {
+		return err
+	}
+	pm.publishRulesChanged()
+	return nil
+}
+
+// DeleteRule removes the first rule matching pattern and match, returning
+// whether one was found, persists the updated list, and notifies every
+// other replica to reload.
+func (pm *ProxyManager) DeleteRule(pattern string, match MatchKind) (bool, error) {
+	release, err := pm.acquireLock(ctx, rulesLockKey, defaultLockTTL)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	pm.mu.Lock()
+	found := false
+	for i, rule := range pm.rules {
+		if rule.Pattern == pattern && rule.Match == match {
+			pm.rules = append(pm.rules[:i], pm.rules[i+1:]...)
+			found = true
+			break
+		}
+	}
+	rules := make([]RoutingRule, len(pm.rules))
+	copy(rules, pm.rules)
+	pm.mu.Unlock()
+
+	if !found {
+		return false, nil
+	}
+	if err := pm.saveRulesToRedis(rules); err != nil {
+		return true, err
+	}
+	pm.publishRulesChanged()
+	return true, nil
+}
+
+// saveRulesToRedis JSON-encodes rules and writes them to rulesRedisKey.
+func (pm *ProxyManager) saveRulesToRedis(rules []RoutingRule) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("encoding routing rules: %w", err)
+	}
+	if err := pm.redis.Set(ctx, rulesRedisKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("saving routing rules to Redis: %w", err)
+	}
+	return nil
+}
+
+// LoadRulesFromRedis loads pm's routing rules from rulesRedisKey, skipping
+// (with a log) any rule that no longer compiles rather than failing the
+// whole load — the same treatment loadProxiesFromIndex gives a malformed
+// proxy hash.
+func (pm *ProxyManager) LoadRulesFromRedis() error {
+	data, err := pm.redis.Get(ctx, rulesRedisKey).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("loading routing rules from Redis: %w", err)
+	}
+
+	var rules []RoutingRule
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		return fmt.Errorf("parsing routing rules from Redis: %w", err)
+	}
+
+	valid := make([]RoutingRule, 0, len(rules))
+	for i := range rules {
+		if err := compileRule(&rules[i]); err != nil {
+			log.Printf("skipping routing rule %q: %v", rules[i].Pattern, err)
+			continue
+		}
+		valid = append(valid, rules[i])
+	}
+
+	pm.mu.Lock()
+	pm.rules = valid
+	pm.mu.Unlock()
+	return nil
+}
+
+// publishRulesChanged notifies every replica's watchEvents loop to reload
+// routing rules from Redis, the same propagation path proxy add/remove/
+// health-state changes already use (see proxyEventsChannel).
+func (pm *ProxyManager) publishRulesChanged() {
+	pm.publishEvent(proxyEvent{Type: "rules_changed"})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ewmaAlpha weights each new latency sample against AvgLatency's running
+// value: 0.2 means a proxy's score reacts within a handful of requests to a
+// real slowdown, without one slow outlier swinging it on its own.
+const ewmaAlpha = 0.2
+
+// SelectionStrategy picks one proxy out of the currently healthy set.
+// GetNextProxy holds pm.mu for the duration of Select, so implementations
+// must not block or re-enter the manager.
+type SelectionStrategy interface {
+	Name() string
+	Select(healthy []*Proxy) (*Proxy, error)
+}
+
+// strategyByName is the set GetNextProxy's ?strategy= query param chooses
+// from; RoundRobin is the default so existing callers that don't pass the
+// param keep today's behavior.
+func strategyByName(name string) SelectionStrategy {
+	switch name {
+	case "weighted_latency":
+		return weightedLatencyStrategy
+	case "least_connections":
+		return leastConnectionsStrategy
+	case "random":
+		return randomStrategy
+	case "round_robin", "":
+		return roundRobinStrategy
+	default:
+		return roundRobinStrategy
+	}
+}
+
+// RoundRobinStrategy cycles through healthy proxies in order, picking up
+// where the last Select left off. index is relative to whatever slice of
+// healthy proxies it's given, not to ProxyManager.proxies, so a proxy
+// going unhealthy and healthy again can shift what index lands on; that's
+// fine for round-robin's fairness goal.
+type RoundRobinStrategy struct {
+	index int
+}
+
+func (s *RoundRobinStrategy) Name() string { return "round_robin" }
+
+func (s *RoundRobinStrategy) Select(healthy []*Proxy) (*Proxy, error) {
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available")
+	}
+	s.index = (s.index + 1) % len(healthy)
+	return healthy[s.index], nil
+}
+
+// WeightedLatencyStrategy implements "power of two choices": it samples two
+// healthy proxies at random and returns whichever scores lower, rather than
+// ranking the whole set on every call. That keeps selection O(1) regardless
+// of fleet size while still steering traffic away from slow or flaky
+// proxies far more often than plain round-robin does.
+type WeightedLatencyStrategy struct{}
+
+func (s *WeightedLatencyStrategy) Name() string { return "weighted_latency" }
+
+func (s *WeightedLatencyStrategy) Select(healthy []*Proxy) (*Proxy, error) {
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available")
+	}
+	if len(healthy) == 1 {
+		return healthy[0], nil
+	}
+
+	a := healthy[rand.Intn(len(healthy))]
+	b := healthy[rand.Intn(len(healthy))]
+	if proxyScore(a) <= proxyScore(b) {
+		return a, nil
+	}
+	return b, nil
+}
+
+// proxyScore is EWMA latency scaled up by fail ratio, so a proxy that's
+// fast but frequently failing still loses to a slightly slower, more
+// reliable one. +1 keeps a proxy with zero recorded latency (newly added,
+// no samples yet) from scoring as an automatic win over one with real data.
+func proxyScore(p *Proxy) float64 {
+	total := p.SuccessCount + p.FailCount
+	failRatio := 0.0
+	if total > 0 {
+		failRatio = float64(p.FailCount) / float64(total)
+	}
+	return float64(p.AvgLatency+1) * (1 + failRatio)
+}
+
+// LeastConnectionsStrategy returns the healthy proxy with the fewest
+// in-flight requests, ties broken by iteration order. InFlight is only
+// meaningful once callers pair every GetNextProxy with a later
+// /api/v1/proxy/release.
+type LeastConnectionsStrategy struct{}
+
+func (s *LeastConnectionsStrategy) Name() string { return "least_connections" }
+
+func (s *LeastConnectionsStrategy) Select(healthy []*Proxy) (*Proxy, error) {
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available")
+	}
+
+	best := healthy[0]
+	for _, p := range healthy[1:] {
+		if p.InFlight < best.InFlight {
+			best = p
+		}
+	}
+	return best, nil
+}
+
+// RandomStrategy returns a uniformly random healthy proxy.
+type RandomStrategy struct{}
+
+func (s *RandomStrategy) Name() string { return "random" }
+
+func (s *RandomStrategy) Select(healthy []*Proxy) (*Proxy, error) {
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("no healthy proxies available")
+	}
+	return healthy[rand.Intn(len(healthy))], nil
+}
+
+// updateLatencyEWMA folds latencyMs into p.AvgLatency at ewmaAlpha. Called
+// on every success/failure that reports a latency, and from the periodic
+// health check, so AvgLatency always reflects the same smoothed series
+// rather than request-path samples getting clobbered by the next health
+// check's single raw reading. LatencySamples, not AvgLatency == 0, is what
+// decides whether this is the first sample, since a proxy can legitimately
+// settle at 0ms.
+func updateLatencyEWMA(p *Proxy, latencyMs int) {
+	if p.LatencySamples == 0 {
+		p.AvgLatency = latencyMs
+	} else {
+		p.AvgLatency = int(ewmaAlpha*float64(latencyMs) + (1-ewmaAlpha)*float64(p.AvgLatency))
+	}
+	p.LatencySamples++
+}
+
+// Package-level strategy instances: RoundRobinStrategy carries state
+// (index) that must persist and be shared across calls regardless of which
+// ProxyManager.GetNextProxy caller triggers it, so it isn't rebuilt per
+// request the way the stateless strategies could be.
+var (
+	roundRobinStrategy       = &RoundRobinStrategy{}
+	weightedLatencyStrategy  = &WeightedLatencyStrategy{}
+	leastConnectionsStrategy = &LeastConnectionsStrategy{}
+	randomStrategy           = &RandomStrategy{}
+)
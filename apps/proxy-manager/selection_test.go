@@ -0,0 +1,131 @@
+package main
+
+import "testing"
+
+func TestStrategyByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want SelectionStrategy
+	}{
+		{"weighted_latency", weightedLatencyStrategy},
+		{"least_connections", leastConnectionsStrategy},
+		{"random", randomStrategy},
+		{"round_robin", roundRobinStrategy},
+		{"", roundRobinStrategy},
+		{"unknown", roundRobinStrategy},
+	}
+	for _, tt := range tests {
+		if got := strategyByName(tt.name); got != tt.want {
+			t.Errorf("strategyByName(%q) = %v, want %v", tt.name, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestRoundRobinStrategyCyclesInOrder(t *testing.T) {
+	s := &RoundRobinStrategy{}
+	healthy := []*Proxy{{Host: "a"}, {Host: "b"}, {Host: "c"}}
+
+	var got []string
+	for i := 0; i < len(healthy)*2; i++ {
+		p, err := s.Select(healthy)
+		if err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+		got = append(got, p.Host)
+	}
+
+	want := []string{"b", "c", "a", "b", "c", "a"}
+	for i, host := range want {
+		if got[i] != host {
+			t.Fatalf("round-robin order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRoundRobinStrategyNoHealthyProxies(t *testing.T) {
+	s := &RoundRobinStrategy{}
+	if _, err := s.Select(nil); err == nil {
+		t.Error("Select with no healthy proxies returned no error, want one")
+	}
+}
+
+func TestWeightedLatencyStrategySingleProxy(t *testing.T) {
+	s := &WeightedLatencyStrategy{}
+	only := &Proxy{Host: "only"}
+	got, err := s.Select([]*Proxy{only})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if got != only {
+		t.Error("Select with one healthy proxy did not return it")
+	}
+}
+
+func TestWeightedLatencyStrategyPrefersLowerScore(t *testing.T) {
+	s := &WeightedLatencyStrategy{}
+	fast := &Proxy{Host: "fast", AvgLatency: 10, LatencySamples: 1}
+	slow := &Proxy{Host: "slow", AvgLatency: 10000, FailCount: 100, SuccessCount: 1, LatencySamples: 1}
+
+	// power-of-two-choices samples randomly, but with only two candidates
+	// every draw compares the same pair, so the lower-scoring proxy should
+	// win every time.
+	for i := 0; i < 20; i++ {
+		got, err := s.Select([]*Proxy{fast, slow})
+		if err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+		if got != fast {
+			t.Fatalf("Select picked %q over the clearly better-scoring %q", got.Host, fast.Host)
+		}
+	}
+}
+
+func TestLeastConnectionsStrategyPicksFewestInFlight(t *testing.T) {
+	s := &LeastConnectionsStrategy{}
+	busy := &Proxy{Host: "busy", InFlight: 5}
+	idle := &Proxy{Host: "idle", InFlight: 0}
+
+	got, err := s.Select([]*Proxy{busy, idle})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if got != idle {
+		t.Errorf("Select picked %q, want the idle proxy %q", got.Host, idle.Host)
+	}
+}
+
+func TestRandomStrategyOnlyReturnsHealthyProxies(t *testing.T) {
+	s := &RandomStrategy{}
+	healthy := []*Proxy{{Host: "a"}, {Host: "b"}}
+
+	for i := 0; i < 20; i++ {
+		got, err := s.Select(healthy)
+		if err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+		if got != healthy[0] && got != healthy[1] {
+			t.Fatalf("Select returned a proxy not in the healthy set: %q", got.Host)
+		}
+	}
+}
+
+func TestUpdateLatencyEWMA(t *testing.T) {
+	p := &Proxy{}
+
+	updateLatencyEWMA(p, 100)
+	if p.AvgLatency != 100 {
+		t.Fatalf("first sample: AvgLatency = %d, want 100 (no prior average to blend with)", p.AvgLatency)
+	}
+	if p.LatencySamples != 1 {
+		t.Fatalf("LatencySamples = %d, want 1", p.LatencySamples)
+	}
+
+	updateLatencyEWMA(p, 300)
+	want := int(ewmaAlpha*300 + (1-ewmaAlpha)*100)
+	if p.AvgLatency != want {
+		t.Fatalf("second sample: AvgLatency = %d, want %d", p.AvgLatency, want)
+	}
+	if p.LatencySamples != 2 {
+		t.Fatalf("LatencySamples = %d, want 2", p.LatencySamples)
+	}
+}